@@ -2,17 +2,22 @@ package main
 
 import (
 	"fmt"
+	"os"
 
+	"github.com/Kaamkiya/gg/internal/app/arena"
 	"github.com/Kaamkiya/gg/internal/app/blackjack"
 	"github.com/Kaamkiya/gg/internal/app/connect4"
 	"github.com/Kaamkiya/gg/internal/app/dodger"
 	"github.com/Kaamkiya/gg/internal/app/hangman"
 	"github.com/Kaamkiya/gg/internal/app/maze"
+	"github.com/Kaamkiya/gg/internal/app/netplay"
 	"github.com/Kaamkiya/gg/internal/app/pong"
 	"github.com/Kaamkiya/gg/internal/app/snake"
+	"github.com/Kaamkiya/gg/internal/app/solitaire"
 	"github.com/Kaamkiya/gg/internal/app/sudoku"
 	"github.com/Kaamkiya/gg/internal/app/tetris"
 	"github.com/Kaamkiya/gg/internal/app/tictactoe"
+	"github.com/Kaamkiya/gg/internal/app/tictactoe/ultimate"
 	"github.com/Kaamkiya/gg/internal/app/twenty48"
 
 	"github.com/charmbracelet/huh"
@@ -21,6 +26,23 @@ import (
 func main() {
 	var game string
 
+	if len(os.Args) > 1 {
+		commands := map[string]func([]string) error{
+			"arena":     arena.Run,
+			"blackjack": blackjack.Command,
+			"host":      netplay.Host,
+			"join":      netplay.Join,
+		}
+
+		if command, ok := commands[os.Args[1]]; ok {
+			if err := command(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	fmt.Println("gg - a tui for small offline games")
 
 	err := huh.NewSelect[string]().
@@ -34,10 +56,14 @@ func main() {
 			huh.NewOption("snake", "snake"),
 			huh.NewOption("tetris", "tetris"),
 			huh.NewOption("connect 4 (2 player)", "connect4"),
+			huh.NewOption("connect 4 (vs AI)", "connect4-ai"),
 			huh.NewOption("pong (2 player)", "pong"),
 			huh.NewOption("tictactoe (2 player)", "tictactoe"),
 			huh.NewOption("tictactoe (vs AI)", "tictactoe-ai"),
-			huh.NewOption("blackjack (2 player)", "blackjack"),
+			huh.NewOption("ultimate tictactoe (2 player)", "ultimate"),
+			huh.NewOption("ultimate tictactoe (vs AI)", "ultimate-ai"),
+			huh.NewOption("blackjack (1-7 players, bots)", "blackjack"),
+			huh.NewOption("solitaire", "solitaire"),
 		).
 		Value(&game).
 		Run()
@@ -55,6 +81,10 @@ func main() {
 		tictactoe.Run()
 	case "tictactoe-ai":
 		tictactoe.RunVsAi()
+	case "ultimate":
+		ultimate.Run()
+	case "ultimate-ai":
+		ultimate.RunVsAi()
 	case "dodger":
 		dodger.Run()
 	case "hangman":
@@ -63,6 +93,8 @@ func main() {
 		twenty48.Run()
 	case "connect4":
 		connect4.Run()
+	case "connect4-ai":
+		connect4.RunVsAi()
 	case "snake":
 		snake.Run()
 	case "sudoku":
@@ -71,6 +103,8 @@ func main() {
 		tetris.Run()
 	case "blackjack":
 		blackjack.Run()
+	case "solitaire":
+		solitaire.Run()
 	default:
 		panic("This game either doesn't exist or hasn't been implemented.")
 	}
@@ -0,0 +1,225 @@
+// Package arena pits AIs against each other on the games implementing the
+// tictactoe engine.GameEngine interface, without any user interface.
+package arena
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Kaamkiya/gg/internal/app/connect4"
+	"github.com/Kaamkiya/gg/internal/app/tictactoe/engine"
+	"github.com/Kaamkiya/gg/internal/app/tictactoe/ultimate"
+)
+
+// Game creates the engine and the empty board of a game.
+type Game struct {
+	Name     string
+	NewGame  func() engine.GameEngine
+	NewBoard func() *engine.Board
+}
+
+var Games = []Game{
+	variantGame("tictactoe", engine.TicTacToe),
+	variantGame("4x4", engine.FourByFour),
+	variantGame("gomoku", engine.Gomoku),
+	{"connect4", func() engine.GameEngine { return connect4.NewEngine() }, connect4.NewBoard},
+	{"ultimate", func() engine.GameEngine { return ultimate.NewEngine() }, ultimate.NewBoard},
+}
+
+func variantGame(name string, variant engine.Variant) Game {
+	return Game{
+		Name: name,
+		NewGame: func() engine.GameEngine {
+			return engine.NewEngineWithWinLength(0, variant.WinLength)
+		},
+		NewBoard: func() *engine.Board {
+			return engine.NewRectBoard(variant.Rows, variant.Cols)
+		},
+	}
+}
+
+func findGame(name string) (Game, error) {
+	for _, game := range Games {
+		if game.Name == name {
+			return game, nil
+		}
+	}
+
+	return Game{}, fmt.Errorf("unknown game %q, expected one of %s", name, strings.Join(gameNames(), ", "))
+}
+
+// Player is an AI configuration written as algorithm:strength, e.g. mcts:1000
+// for 1000 iterations of MCTS or negamax:9 for a search 9 plies deep. random
+// plays any legal move and takes no strength.
+type Player struct {
+	Algorithm string
+	Strength  int
+}
+
+func ParsePlayer(spec string) (Player, error) {
+	algorithm, strength, found := strings.Cut(spec, ":")
+
+	switch algorithm {
+	case "random":
+		if found {
+			return Player{}, fmt.Errorf("random takes no strength: %q", spec)
+		}
+
+		return Player{Algorithm: algorithm}, nil
+	case "mcts", "negamax":
+		n, err := strconv.Atoi(strength)
+		if err != nil || n <= 0 {
+			return Player{}, fmt.Errorf("expected a positive strength in %q", spec)
+		}
+
+		return Player{algorithm, n}, nil
+	}
+
+	return Player{}, fmt.Errorf("unknown algorithm in %q, expected mcts:N, negamax:N or random", spec)
+}
+
+func (p Player) String() string {
+	if p.Algorithm == "random" {
+		return p.Algorithm
+	}
+
+	return fmt.Sprintf("%s:%d", p.Algorithm, p.Strength)
+}
+
+// NewAI creates a new AI of the player, so that no state is shared between the
+// games.
+func (p Player) NewAI(game engine.GameEngine) engine.AI {
+	switch p.Algorithm {
+	case "mcts":
+		return engine.NewMCTS(game, p.Strength)
+	case "negamax":
+		return engine.NewNegamax(game, p.Strength)
+	}
+
+	return engine.NewNoisyAI(nil, game, 1)
+}
+
+// Play plays the games between a and b on parallel workers. a starts the even
+// games and b the odd ones. The outcomes are seen from a: 1 for a win, 0 for a
+// draw and -1 for a loss.
+func Play(game Game, a, b Player, games, workers int) []int {
+	outcomes := make([]int, games)
+	indices := make(chan int)
+
+	var wg sync.WaitGroup
+	for range max(workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				outcomes[i] = playGame(game, a, b, i%2 == 0)
+			}
+		}()
+	}
+
+	for i := range games {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	return outcomes
+}
+
+func playGame(game Game, a, b Player, aFirst bool) int {
+	rules := game.NewGame()
+	aiA, aiB := a.NewAI(rules), b.NewAI(rules)
+
+	if aFirst {
+		return engine.PlayMatch(rules, game.NewBoard(), aiA, aiB)
+	}
+
+	return -engine.PlayMatch(rules, game.NewBoard(), aiB, aiA)
+}
+
+// Run runs the arena command: gg arena <game> --a <player> --b <player>.
+func Run(args []string) error {
+	flags := flag.NewFlagSet("arena", flag.ContinueOnError)
+	a := flags.String("a", "mcts:1000", "first AI, as mcts:N, negamax:N or random")
+	b := flags.String("b", "mcts:100", "second AI, as mcts:N, negamax:N or random")
+	games := flags.Int("games", 100, "number of games, each AI starting half of them")
+	workers := flags.Int("workers", runtime.GOMAXPROCS(0), "number of games played in parallel")
+	format := flags.String("format", "text", "output format: text, json or csv")
+	output := flags.String("o", "", "file to write the results to instead of the standard output")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: gg arena <game> [flags]")
+		fmt.Fprintln(flags.Output(), "games:", strings.Join(gameNames(), ", "))
+		flags.PrintDefaults()
+	}
+
+	// The game comes first but the flag package stops at the first argument.
+	name := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if name == "" {
+		name = flags.Arg(0)
+	}
+
+	game, err := findGame(name)
+	if err != nil {
+		return err
+	}
+
+	playerA, err := ParsePlayer(*a)
+	if err != nil {
+		return err
+	}
+
+	playerB, err := ParsePlayer(*b)
+	if err != nil {
+		return err
+	}
+
+	if *games <= 0 {
+		return fmt.Errorf("expected a positive number of games, got %d", *games)
+	}
+
+	if !slices.Contains([]string{"text", "json", "csv"}, *format) {
+		return fmt.Errorf("unknown format %q, expected text, json or csv", *format)
+	}
+
+	result := NewResult(game.Name, playerA, playerB, Play(game, playerA, playerB, *games, *workers))
+
+	if *output == "" {
+		return result.Write(os.Stdout, *format)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+
+	if err := result.Write(file, *format); err != nil {
+		file.Close()
+		return err
+	}
+
+	// The data may only reach the disk on close, which must not fail silently
+	return file.Close()
+}
+
+func gameNames() []string {
+	names := []string{}
+	for _, game := range Games {
+		names = append(names, game.Name)
+	}
+
+	return names
+}
@@ -0,0 +1,114 @@
+package arena
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParsePlayer(t *testing.T) {
+	valid := map[string]Player{
+		"mcts:1000": {"mcts", 1000},
+		"negamax:9": {"negamax", 9},
+		"random":    {"random", 0},
+	}
+
+	for spec, expected := range valid {
+		player, err := ParsePlayer(spec)
+		if err != nil || player != expected {
+			t.Errorf("%s: expected %v, got %v (%v)", spec, expected, player, err)
+		}
+
+		if player.String() != spec {
+			t.Errorf("%s: printed as %s", spec, player)
+		}
+	}
+
+	for _, spec := range []string{"mcts", "mcts:0", "negamax:x", "random:3", "minimax:3"} {
+		if _, err := ParsePlayer(spec); err == nil {
+			t.Errorf("%s: expected an error", spec)
+		}
+	}
+}
+
+func TestNewResult(t *testing.T) {
+	result := NewResult("tictactoe", Player{"random", 0}, Player{"random", 0}, []int{1, 1, 1, 0, 0, -1})
+
+	if result.Wins != 3 || result.Draws != 2 || result.Losses != 1 {
+		t.Errorf("unexpected outcomes %+v", result)
+	}
+
+	if math.Abs(result.Score-2.0/3) > 1e-9 {
+		t.Errorf("expected a score of 2/3, got %f", result.Score)
+	}
+
+	// A score of 2/3 is expected from a player rated 120 points higher.
+	if math.Abs(result.Elo-120.4) > 0.1 {
+		t.Errorf("expected an Elo difference of 120, got %f", result.Elo)
+	}
+
+	if result.ScoreLow >= result.Score || result.ScoreHigh <= result.Score || result.EloLow >= result.Elo || result.EloHigh <= result.Elo {
+		t.Errorf("expected the confidence intervals to contain the estimates, got %+v", result)
+	}
+}
+
+func TestPlayAlternatesTheFirstPlayer(t *testing.T) {
+	game, _ := findGame("tictactoe")
+
+	// Perfect play against itself always ends in a draw, whoever starts.
+	player := Player{"negamax", 9}
+	for i, outcome := range Play(game, player, player, 4, 2) {
+		if outcome != 0 {
+			t.Errorf("game %d: expected a draw, got %d", i, outcome)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "result.json")
+
+	err := Run(strings.Fields("tictactoe --a negamax:9 --b random --games 10 --format json -o " + output))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := Result{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Games != 10 || result.Losses != 0 || result.A != "negamax:9" {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+func TestRunRejectsInvalidArguments(t *testing.T) {
+	for _, args := range []string{"chess", "tictactoe --a minimax:3", "tictactoe --games 0", "tictactoe --format xml"} {
+		if err := Run(strings.Fields(args)); err == nil {
+			t.Errorf("%s: expected an error", args)
+		}
+	}
+}
+
+func TestResultWriteCSV(t *testing.T) {
+	result := NewResult("connect4", Player{"mcts", 10}, Player{"random", 0}, []int{1, -1})
+
+	buffer := bytes.Buffer{}
+	if err := result.Write(&buffer, "csv"); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], "connect4,mcts:10,random,2,1,0,1,0.5000") {
+		t.Errorf("unexpected csv output %q", buffer.String())
+	}
+}
@@ -0,0 +1,121 @@
+package arena
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+const (
+	// z is the quantile of the normal distribution for 95% confidence intervals.
+	z = 1.96
+	// maxScore bounds the scores the Elo difference is computed from, which
+	// would be infinite for a score of 0 or 1. It caps it at about ±1200.
+	maxScore = 0.999
+)
+
+// Result sums up the outcomes of the games from the point of view of A. Score
+// counts a win as 1 and a draw as 0.5, so a score of 0.5 means both AIs are as
+// strong. Elo is the rating difference of A over B estimated from the score.
+// Low and High bound the 95% confidence intervals.
+type Result struct {
+	Game      string  `json:"game"`
+	A         string  `json:"a"`
+	B         string  `json:"b"`
+	Games     int     `json:"games"`
+	Wins      int     `json:"wins"`
+	Draws     int     `json:"draws"`
+	Losses    int     `json:"losses"`
+	Score     float64 `json:"score"`
+	ScoreLow  float64 `json:"score_low"`
+	ScoreHigh float64 `json:"score_high"`
+	Elo       float64 `json:"elo"`
+	EloLow    float64 `json:"elo_low"`
+	EloHigh   float64 `json:"elo_high"`
+}
+
+// NewResult computes the result of the outcomes returned by Play.
+func NewResult(game string, a, b Player, outcomes []int) Result {
+	result := Result{Game: game, A: a.String(), B: b.String(), Games: len(outcomes)}
+
+	for _, outcome := range outcomes {
+		switch outcome {
+		case 1:
+			result.Wins++
+		case 0:
+			result.Draws++
+		case -1:
+			result.Losses++
+		}
+	}
+
+	n := float64(result.Games)
+	score := (float64(result.Wins) + float64(result.Draws)/2) / n
+
+	// The variance of the score of a game, from the observed outcomes
+	variance := (float64(result.Wins)*math.Pow(1-score, 2) +
+		float64(result.Draws)*math.Pow(0.5-score, 2) +
+		float64(result.Losses)*math.Pow(score, 2)) / n
+	margin := z * math.Sqrt(variance/n)
+
+	result.Score = score
+	result.ScoreLow = max(score-margin, 0)
+	result.ScoreHigh = min(score+margin, 1)
+	result.Elo = elo(result.Score)
+	result.EloLow = elo(result.ScoreLow)
+	result.EloHigh = elo(result.ScoreHigh)
+
+	return result
+}
+
+// elo returns the rating difference expected to give the score.
+func elo(score float64) float64 {
+	score = min(max(score, 1-maxScore), maxScore)
+
+	return -400 * math.Log10(1/score-1)
+}
+
+// Write writes the result in the format: text, json or csv.
+func (r Result) Write(w io.Writer, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	case "csv":
+		return r.writeCSV(w)
+	}
+
+	_, err := fmt.Fprintf(w, `%s: %s vs %s, %d games
+wins: %d, draws: %d, losses: %d
+score: %.3f (95%% CI %.3f - %.3f)
+elo: %+.0f (95%% CI %+.0f - %+.0f)
+`,
+		r.Game, r.A, r.B, r.Games,
+		r.Wins, r.Draws, r.Losses,
+		r.Score, r.ScoreLow, r.ScoreHigh,
+		r.Elo, r.EloLow, r.EloHigh)
+
+	return err
+}
+
+func (r Result) writeCSV(w io.Writer) error {
+	f := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', 4, 64)
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"game", "a", "b", "games", "wins", "draws", "losses", "score", "score_low", "score_high", "elo", "elo_low", "elo_high"})
+	writer.Write([]string{
+		r.Game, r.A, r.B,
+		strconv.Itoa(r.Games), strconv.Itoa(r.Wins), strconv.Itoa(r.Draws), strconv.Itoa(r.Losses),
+		f(r.Score), f(r.ScoreLow), f(r.ScoreHigh),
+		f(r.Elo), f(r.EloLow), f(r.EloHigh),
+	})
+	writer.Flush()
+
+	return writer.Error()
+}
@@ -2,308 +2,240 @@ package blackjack
 
 import (
 	"fmt"
-	"math/rand"
-	"strconv"
+	"slices"
 	"strings"
 
+	"github.com/Kaamkiya/gg/internal/app/blackjack/engine"
+	"github.com/Kaamkiya/gg/internal/app/blackjack/sim"
+	"github.com/Kaamkiya/gg/internal/app/blackjack/strategy"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
 )
 
-// -------------------- ENUM: Suit --------------------
-
-// Enumeration of card suits.
-type Suit int // Suit represents a card suit in a standard deck.
-
-const (
-	// Heart is the suit of hearts (♥).
-	Heart Suit = iota
-	// Diamond is the suit of diamonds (♦).
-	Diamond
-	// Club is the suit of clubs (♣).
-	Club
-	// Spade is the suit of spades (♠).
-	Spade
-)
-
-// String returns the string representation of a Suit.
-func (suit Suit) String() string {
-	return [...]string{"♥", "♦", "♣", "♠"}[suit]
-}
-
-// -------------------- STRUCT: Card --------------------
-
-// Representation of a playing card.
-type Card struct {
-	suit Suit // Suit of the card (Heart, Diamond, Club, Spade).
-	rank int  // 1–13 where 1 is Ace, 11 is Jack, 12 is Queen, 13 is King
+// -------------------- Actions --------------------
+
+// betStep is the number of chips added or removed from a bet at once.
+const betStep = 10
+
+// actionKeys are the keys of the actions in the order of the prompt, with what the prompt says they do.
+var actionKeys = []struct {
+	key    string
+	action engine.Action
+	text   string
+}{
+	{"h", engine.ActionHit, "hit"},
+	{"s", engine.ActionStand, "stand"},
+	{"d", engine.ActionDouble, "double down"},
+	{"p", engine.ActionSplit, "split"},
+	{"r", engine.ActionSurrender, "surrender"},
+	{"i", engine.ActionInsurance, "take insurance"},
+	{"n", engine.ActionDecline, "decline insurance"},
 }
 
-// String returns the string representation of a Card, e.g., "A♥" or "K♠".
-func (card Card) String() string {
-	rankStr := strconv.Itoa(card.rank)
-	switch card.rank {
-	case 1:
-		rankStr = "A" // Ace
-	case 11:
-		rankStr = "J" // Jack
-	case 12:
-		rankStr = "Q" // Queen
-	case 13:
-		rankStr = "K" // King
-	}
-
-	return fmt.Sprintf("%s%s", rankStr, card.suit)
-}
-
-// -------------------- STRUCT: Deck --------------------
-
-// Holds a collection of Cards.
-type Deck struct {
-	cards []Card // Slice of cards in the deck.
-}
-
-// Creates a new 52-card deck and randomizes the order of the cards (Shuffle).
-func (deck *Deck) Shuffle() {
-	deck.cards = make([]Card, 0, 52)
-	for suit := Heart; suit <= Spade; suit++ {
-		for rank := 1; rank <= 13; rank++ {
-			deck.cards = append(deck.cards, Card{suit: suit, rank: rank})
+// keyAction returns the action of the key.
+func keyAction(key string) (engine.Action, bool) {
+	for _, actionKey := range actionKeys {
+		if actionKey.key == key {
+			return actionKey.action, true
 		}
 	}
-	rand.Shuffle(len(deck.cards), func(i, j int) {
-		deck.cards[i], deck.cards[j] = deck.cards[j], deck.cards[i]
-	})
+	return "", false
 }
 
-// Pops a card off the top of the deck.
-// If the deck is empty, it returns a negative‐value Card.
-func (deck *Deck) Deal() Card {
-	if len(deck.cards) == 0 {
-		return Card{-1, -1}
-	}
-	// take from end
-	card := deck.cards[len(deck.cards)-1]
-	deck.cards = deck.cards[:len(deck.cards)-1]
-	return card
-}
+// selectRules asks for the table to play at.
+func selectRules() engine.Rules {
+	choice := 0
 
-// -------------------- STRUCT: Player --------------------
-
-// Representation of a blackjack participant (dealer or player).
-type Player struct {
-	hand  []Card
-	score int // Current score based on hand.
-	name  string
-}
-
-// Draws one card from the deck into the player's hand.
-func (player *Player) Hit(deck *Deck) {
-	card := deck.Deal()
-	player.hand = append(player.hand, card)
-	player.UpdateScore()
-}
-
-// Returns the player's current score.
-func (player *Player) GetScore() int {
-	return player.score
-}
-
-// Returns a copy of the player's hand.
-func (player *Player) GetHand() []Card {
-	return player.hand
-}
-
-// SetHand sets the Player's hand to the provided slice of cards.
-func (player *Player) SetHand(hand []Card) {
-	player.hand = hand
-}
-
-// Calculates and updates a player's score
-// Aces are counted as 11 unless it causes a bust, then as 1; face cards (J, Q, K) are 10.
-func (player *Player) UpdateScore() {
-	sum := 0
-	aces := 0
-	for _, c := range player.GetHand() {
-		switch c.rank {
-		case 11, 12, 13: // Jack, Queen, King
-			sum += 10
-		case 1:
-			sum += 11
-			aces++
-		default:
-			sum += c.rank
-		}
-	}
-	// adjust for aces if bust
-	for sum > 21 && aces > 0 {
-		sum -= 10
-		aces--
+	options := make([]huh.Option[int], 0, len(engine.Presets))
+	for i, rules := range engine.Presets {
+		options = append(options, huh.NewOption(fmt.Sprintf("%s - %s", rules.Name, rules.Description()), i))
 	}
-	player.score = sum
-}
-
-// -------------------- STRUCT: BlackJackGame --------------------
-
-// Representation of a blackjack game.
-type BlackJackGame struct {
-	deck           *Deck
-	dealer         *Player
-	players        []*Player
-	numberOfRounds int    // Total number of rounds to play.
-	currentRound   int    // Current round number.
-	currentPlayer  int    // Index of the current player in players slice.
-	phase          string // Current game phase ("deal", "player_turn", "dealer_turn", "round_end", "game_over").
-}
 
-// NewBlackJackGame creates a new Blackjack game with the specified number of players and rounds.
-// The first player is treated as a regular player, and a separate dealer is created.
-func NewBlackJackGame(numPlayers, rounds int) *BlackJackGame {
-	players := make([]*Player, numPlayers)
-	for i := 0; i < numPlayers; i++ {
-		//players[i] = &Player{name: strconv.Itoa(i + 1)}
-		players[i] = &Player{name: fmt.Sprintf("Player %d", i+1)}
-	}
-	return &BlackJackGame{
-		deck:           &Deck{},
-		dealer:         &Player{name: "Dealer"},
-		players:        players,
-		numberOfRounds: rounds,
-		currentRound:   1,
-		currentPlayer:  0,
-		phase:          "deal",
+	err := huh.NewSelect[int]().
+		Title("choose a table:").
+		Options(options...).
+		Value(&choice).
+		Run()
+	if err != nil {
+		panic(err)
 	}
-}
 
-// dealInitialCards deals two cards to each player and the dealer, resetting their hands and scores.
-func (game *BlackJackGame) dealInitialCards() {
-	for _, player := range game.players {
-		player.SetHand(nil)
-		player.score = 0
-		for i := 0; i < 2; i++ {
-			player.Hit(game.deck)
-		}
-	}
-	game.dealer.SetHand(nil)
-	game.dealer.score = 0
-	for i := 0; i < 2; i++ {
-		game.dealer.Hit(game.deck)
-	}
+	return engine.Presets[choice]
 }
 
+// -------------------- UI --------------------
+
 // model represents the Bubbletea UI model for the Blackjack game.
 type model struct {
-	game          *BlackJackGame // Game state and logic.
-	cardStyle     lipgloss.Style // Style for rendering cards.
-	headerStyle   lipgloss.Style // Style for headers and prompts.
-	tableStyle    lipgloss.Style // Style for the game table.
-	nameStyle     lipgloss.Style // Style for player/dealer names.
-	cardAreaStyle lipgloss.Style // Style for card display area.
-	scoreStyle    lipgloss.Style // Style for score display.
+	game          *engine.Game      // Game state and logic.
+	shuffled      bool              // The shoe was shuffled before the round, the cut card having been reached.
+	strategy      *strategy.Table   // Basic strategy of the table for the hints, nil to play without hints.
+	hint          bool              // The basic strategy action of the current decision is shown.
+	showTable     bool              // The basic strategy table is shown under the game.
+	trainer       *trainer          // Checks the decisions against the basic strategy, nil when not training.
+	count         *strategy.Count   // Hi-Lo count of the shoe, nil to play without counting.
+	showCount     bool              // The count is shown under the game.
+	drill         *drill            // Quizzes the player on the count, nil when not drilling.
+	bots          []strategy.Policy // Policy of the bot of each seat, nil for the seats played from the keyboard.
+	cardStyle     lipgloss.Style    // Style for rendering cards.
+	headerStyle   lipgloss.Style    // Style for headers and prompts.
+	tableStyle    lipgloss.Style    // Style for the game table.
+	nameStyle     lipgloss.Style    // Style for player/dealer names.
+	cardAreaStyle lipgloss.Style    // Style for card display area.
+	scoreStyle    lipgloss.Style    // Style for score display.
+	chipsStyle    lipgloss.Style    // Style for bankroll and bet display.
 }
 
 // initialModel creates a new Bubbletea model with a Blackjack game initialized for 2 players and 3 rounds.
 func initialModel() tea.Model {
-	game := NewBlackJackGame(2, 3)
+	return newModel(engine.New(2, 3, engine.DefaultRules))
+}
+
+// newModel creates a new Bubbletea model playing the game.
+func newModel(game *engine.Game) tea.Model {
 	return model{
 		game:          game,
+		strategy:      strategy.Generate(game.Rules()),
+		count:         strategy.NewCount(game.Shoe()),
 		cardStyle:     lipgloss.NewStyle().Foreground(lipgloss.Color("12")).Bold(true), // Bright blue, bold text for cards
 		headerStyle:   lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("10")), // Bold green text for headers
-		tableStyle:    lipgloss.NewStyle().Border(lipgloss.NormalBorder()).BorderTop(true).BorderBottom(true).Width(55),
+		tableStyle:    lipgloss.NewStyle().Border(lipgloss.NormalBorder()).BorderTop(true).BorderBottom(true).Width(73),
 		nameStyle:     lipgloss.NewStyle().Width(10).Align(lipgloss.Left),
 		cardAreaStyle: lipgloss.NewStyle().Width(29).Align(lipgloss.Left),
 		scoreStyle:    lipgloss.NewStyle().Width(12).Align(lipgloss.Right),
+		chipsStyle:    lipgloss.NewStyle().Width(18).Align(lipgloss.Right),
 	}
 }
 
-// Init initializes the model by shuffling the deck and returns a clear screen command.
+// Init initializes the model by shuffling the deck and returns a clear screen command, and the first move of a bot
+// betting first.
 func (m model) Init() tea.Cmd {
-	m.game.deck.Shuffle()
-	return tea.ClearScreen
+	m.observe(m.game.Shuffle())
+	return tea.Batch(tea.ClearScreen, m.botTurn())
 }
 
-// Update handles user input and updates the game state based on the current phase.
-// It processes key presses to hit, stand, advance rounds, or quit, and manages phase transitions.
+// Update handles the message, then schedules the move of a bot once the game starts waiting for it.
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch m.game.phase {
-	case "deal":
-		// Deal initial cards and move to player turns
-		m.game.dealInitialCards()
-		m.game.phase = "player_turn"
-	case "player_turn":
+	waiting := m.bot() != nil
+	updated, cmd := m.update(msg)
+	if m := updated.(model); !waiting && m.bot() != nil {
+		return m, tea.Batch(cmd, m.botTurn())
+	}
+	return updated, cmd
+}
+
+// update handles user input and plays it on the game engine based on the current phase.
+// It processes key presses to bet, play the actions of the hands, advance rounds, or quit. The engine moves from phase
+// to phase, and the moves it doesn't allow are ignored: the prompts only offer the allowed ones.
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "ctrl+c" {
+		return m, tea.Quit
+	}
+
+	// The bot plays its move, and the next bot follows after the delay
+	if _, ok := msg.(botMsg); ok {
+		m.playBot()
+		return m, m.botTurn()
+	}
+
+	// The answers of the counting quiz are typed at the end of the round
+	if msg, ok := msg.(tea.KeyMsg); ok && m.drill != nil && m.drill.asking != nil {
+		m.drill.key(msg.String())
+		return m, nil
+	}
+
+	// The hint, the basic strategy table and the count are shown in every phase
+	if msg, ok := msg.(tea.KeyMsg); ok && m.strategy != nil {
+		switch msg.String() {
+		case "?":
+			m.hint = !m.hint
+			return m, nil
+		case "c":
+			m.showTable = !m.showTable
+			return m, nil
+		case "o":
+			m.showCount = !m.showCount
+			return m, nil
+		}
+	}
+
+	// The keys don't play for the bots
+	if msg, ok := msg.(tea.KeyMsg); ok && m.bot() != nil {
+		if msg.String() == "q" {
+			return m, tea.Quit
+		}
+		return m, nil
+	}
+
+	switch m.game.Phase() {
+	case engine.PhaseBet:
 		if msg, ok := msg.(tea.KeyMsg); ok {
 			switch msg.String() {
-			case "ctrl+c", "q":
+			case "q":
 				return m, tea.Quit
-			case "h":
-				// Current player draws a card
-				player := m.game.players[m.game.currentPlayer]
-				player.Hit(m.game.deck)
-				if player.GetScore() > 21 {
-					// Player busts, move to next player or dealer
-					m.game.currentPlayer++
-					if m.game.currentPlayer >= len(m.game.players) {
-						m.game.phase = "dealer_turn"
-					}
-				}
-			case "s":
-				// Player stands, move to next player or dealer
-				m.game.currentPlayer++
-				if m.game.currentPlayer >= len(m.game.players) {
-					m.game.phase = "dealer_turn"
-				}
+			case "up", "k", "+":
+				m.game.ChangeBet(betStep)
+			case "down", "j", "-":
+				m.game.ChangeBet(-betStep)
+			case "enter", " ":
+				// Bet placed, the next player bets or the cards are dealt
+				events, _ := m.game.PlaceBet()
+				m.observe(events)
+				m.shuffled = false
 			}
 		}
-	case "dealer_turn":
-		// Check if all players have busted
-		allPlayersBusted := true
-		for _, player := range m.game.players {
-			if player.GetScore() <= 21 {
-				allPlayersBusted = false
-				break
+	case engine.PhaseInsurance, engine.PhasePlayerTurn:
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			switch msg.String() {
+			case "q":
+				return m, tea.Quit
 			}
-		}
-
-		if allPlayersBusted {
-			// Skip dealer's turn if all players have busted
-			m.game.phase = "round_end"
-		} else {
-			// Dealer must hit until reaching 17 or higher
-			if msg, ok := msg.(tea.KeyMsg); ok {
-				switch msg.String() {
-				case "ctrl+c", "q":
-					return m, tea.Quit
-				case "enter", " ", "h":
-					// Dealer hits until score is at least 17
-					for m.game.dealer.GetScore() < 17 {
-						m.game.dealer.Hit(m.game.deck)
-					}
-					// Once dealer reaches 17+, move to round end
-					m.game.phase = "round_end"
+			// The player takes the insurance, or the current hand plays the action, and the game moves on to the
+			// next hand, player or the dealer once the hand is done.
+			if action, ok := keyAction(msg.String()); ok && m.game.CanTake(action) {
+				if m.trainer != nil {
+					m.trainer.record(situation(m.game), action, m.strategy.Action(m.game))
 				}
+				events, _ := m.game.Take(action)
+				m.observe(events)
+				m.hint = false
 			}
 		}
-	case "round_end":
+	case engine.PhaseDealerTurn:
+		if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "q" {
+			return m, tea.Quit
+		}
+		// The hole card is shown, the dealer hits until reaching 17 unless all players have busted, then the bets
+		// are settled. Without a hole card, the dealer draws the second card first.
+		events, _ := m.game.PlayDealer()
+		m.observe(events)
+		// The counting drill quizzes the player every few rounds
+		if m.drill != nil && m.game.Round()%quizEvery == 0 {
+			m.drill.ask(m.count)
+		}
+	case engine.PhaseRoundEnd:
 		if msg, ok := msg.(tea.KeyMsg); ok {
 			switch msg.String() {
+			case "q":
+				return m, tea.Quit
 			case "enter", " ":
-				// Advance to next round or end game
-				if m.game.currentRound >= m.game.numberOfRounds {
-					m.game.phase = "game_over"
-				} else {
-					m.game.currentRound++
-					m.game.currentPlayer = 0
-					m.game.deck.Shuffle()
-					m.game.phase = "deal"
+				// Open the bets of the next round or end game
+				events, _ := m.game.NextRound()
+				m.observe(events)
+				m.shuffled = slices.ContainsFunc(events, func(event engine.Event) bool {
+					return event.Kind == engine.Shuffled
+				})
+				if m.game.Phase() != engine.PhaseGameOver {
 					// Return a command to clear screen and refresh
 					return m, tea.ClearScreen
 				}
 			}
 		}
-	case "game_over":
+	case engine.PhaseGameOver:
 		if msg, ok := msg.(tea.KeyMsg); ok {
 			switch msg.String() {
-			case "q", "ctrl+c":
+			case "q":
 				return m, tea.Quit
 			}
 		}
@@ -312,19 +244,22 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 // View renders the current game state as a string for display in the terminal.
-// It shows the dealer's hand, players' hands and scores, and prompts based on the game phase.
+// It shows the dealer's hand, players' hands, scores and chips, and prompts based on the game phase.
 func (m model) View() string {
 	var rows []string
 
 	// Render header with round information
-	rows = append(rows, m.headerStyle.Render(fmt.Sprintf("Blackjack - Round %d/%d", m.game.currentRound, m.game.numberOfRounds)))
+	rows = append(rows, m.headerStyle.Render(fmt.Sprintf("Blackjack (%s) - Round %d/%d", m.game.Rules().Name, m.game.Round(), m.game.Rounds())))
 
-	// Render dealer's hand
-	dealerHand := m.game.dealer.GetHand()
+	// Render dealer's hand, the hole card face down until the dealer's turn
+	dealerHand := m.game.Dealer().GetHand()
 	var dealerCards string
-	if m.game.phase == "deal" || m.game.phase == "player_turn" {
+	if m.game.HoleCardHidden() {
 		if len(dealerHand) > 0 {
-			dealerCards = m.cardStyle.Render(dealerHand[0].String()) + " [Hidden]"
+			dealerCards = m.cardStyle.Render(dealerHand[0].String())
+			if !m.game.Rules().NoHoleCard {
+				dealerCards += " [Hidden]"
+			}
 		} else {
 			dealerCards = ""
 		}
@@ -336,78 +271,297 @@ func (m model) View() string {
 		dealerCards = strings.Join(cards, " ")
 	}
 	dealerScore := ""
-	if m.game.phase != "deal" && m.game.phase != "player_turn" {
-		dealerScore = fmt.Sprintf("(Score: %d)", m.game.dealer.GetScore())
+	if !m.game.HoleCardHidden() {
+		dealerScore = fmt.Sprintf("(Score: %d)", m.game.Dealer().GetScore())
 	}
 	dealerRow := lipgloss.JoinHorizontal(
 		lipgloss.Top,
 		m.nameStyle.Render("Dealer:"),
 		m.cardAreaStyle.Render(dealerCards),
 		m.scoreStyle.Render(dealerScore),
+		m.chipsStyle.Render(""),
 	)
 	rows = append(rows, dealerRow)
 
-	// Render players' hands
-	for _, player := range m.game.players {
-		var cards []string
-		for _, card := range player.GetHand() {
-			cards = append(cards, m.cardStyle.Render(card.String()))
-		}
-		playerRow := lipgloss.JoinHorizontal(
-			lipgloss.Top,
-			m.nameStyle.Render(player.name+":"),
-			m.cardAreaStyle.Render(strings.Join(cards, " ")),
-			m.scoreStyle.Render(fmt.Sprintf("(Score: %d)", player.GetScore())),
-		)
-		rows = append(rows, playerRow)
+	// Render players' hands and chips, one row for each hand of a player who split
+	for i, player := range m.game.Players() {
+		rows = append(rows, m.viewPlayer(player, m.game.Phase() == engine.PhasePlayerTurn && i == m.game.CurrentPlayer())...)
 	}
 
 	// Combine rows with table style
 	s := m.tableStyle.Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
 
 	// Render phase-specific prompts
-	switch m.game.phase {
-	case "player_turn":
-		s += fmt.Sprintf("\n%s's turn: Press 'h' to hit, 's' to stand", m.game.players[m.game.currentPlayer].name)
-	case "dealer_turn":
-		dealerScore := m.game.dealer.GetScore()
-		if dealerScore < 17 {
+	switch phase := m.game.Phase(); {
+	case m.bot() != nil:
+		player := m.game.Players()[m.game.CurrentPlayer()]
+		if phase == engine.PhaseBet && m.shuffled {
+			s += "\nThe cut card came out, the shoe is shuffled"
+		}
+		s += fmt.Sprintf("\n%s is playing...", player.Name)
+	case phase == engine.PhaseBet:
+		player := m.game.Players()[m.game.CurrentPlayer()]
+		if m.shuffled {
+			s += "\nThe cut card came out, the shoe is shuffled"
+		}
+		s += fmt.Sprintf("\n%s bets $%d (bankroll $%d): Press up/down to change the bet, Enter to place it", player.Name, player.Bet, player.Bankroll)
+	case phase == engine.PhaseInsurance:
+		player := m.game.Players()[m.game.CurrentPlayer()]
+		s += fmt.Sprintf("\nThe dealer shows an ace, %s bet $%d: Press %s", player.Name, player.Bet, m.viewActions())
+		s += m.viewHint()
+	case phase == engine.PhasePlayerTurn:
+		player := m.game.Players()[m.game.CurrentPlayer()]
+		s += fmt.Sprintf("\n%s's turn", player.Name)
+		if len(player.Hands) > 1 {
+			s += fmt.Sprintf(" (hand %d/%d)", player.Current+1, len(player.Hands))
+		}
+		s += ": Press " + m.viewActions()
+		s += m.viewHint()
+	case phase == engine.PhaseDealerTurn:
+		dealerScore := m.game.Dealer().GetScore()
+		if m.game.Dealer().HasBlackjack() {
+			s += "\nDealer has blackjack! Press Enter, Space, or 'h' to see results"
+		} else if dealerScore < 17 {
 			s += fmt.Sprintf("\nDealer has %d (must hit until 17). Press Enter, Space, or 'h'", dealerScore)
+		} else if m.game.DealerHits() {
+			s += "\nDealer has a soft 17 (must hit). Press Enter, Space, or 'h'"
 		} else {
 			s += fmt.Sprintf("\nDealer has %d (stands). Press Enter, Space, or 'h' to see results", dealerScore)
 		}
-	case "round_end":
+	case phase == engine.PhaseRoundEnd:
 		s += m.headerStyle.Render("\nRound Results:")
-		dealerScore := m.game.dealer.GetScore()
-		for i, player := range m.game.players {
-			playerScore := player.GetScore()
-			var result string
-			if playerScore > 21 {
-				result = "loses (bust)"
-			} else if dealerScore > 21 {
-				result = "wins (dealer bust)"
-			} else if playerScore == dealerScore {
-				result = "ties (push)"
-			} else if playerScore > dealerScore {
-				result = "wins"
-			} else {
-				result = "loses"
+		dealerScore := m.game.Dealer().GetScore()
+		for _, player := range m.game.Players() {
+			if player.Bet == 0 {
+				s += fmt.Sprintf("\n%s sits out", player.Name)
+				continue
 			}
-			s += fmt.Sprintf("\nPlayer %d %s (Player: %d vs Dealer: %d)", i+1, result, playerScore, dealerScore)
+			for i, hand := range player.Hands {
+				name := player.Name
+				if len(player.Hands) > 1 {
+					name = fmt.Sprintf("%s (hand %d)", player.Name, i+1)
+				}
+				s += fmt.Sprintf("\n%s %s (%s: %d vs Dealer: %d)", name, resultText(hand, dealerScore), player.Name, hand.Score, dealerScore)
+			}
+			if player.InsuranceNet > 0 {
+				s += fmt.Sprintf("\n%s's insurance pays $%d", player.Name, player.InsuranceNet)
+			} else if player.InsuranceNet < 0 {
+				s += fmt.Sprintf("\n%s loses the $%d insurance", player.Name, -player.InsuranceNet)
+			}
+		}
+		if m.drill != nil && m.drill.asking != nil {
+			s += fmt.Sprintf("\nCount check, %s %s_ (Enter to answer)", m.drill.question(), m.drill.input)
+		} else {
+			if m.drill != nil && m.drill.feedback != "" {
+				s += "\n" + m.drill.feedback
+			}
+			s += "\nPress Enter or Space to continue"
 		}
-		s += "\nPress Enter or Space to continue"
-	case "game_over":
+	case phase == engine.PhaseGameOver:
 		s += m.headerStyle.Render("\nGame Over!")
+		s += m.headerStyle.Render("\nFinal Standings:")
+		s += m.viewStandings()
+		if m.trainer != nil {
+			s += m.headerStyle.Render("\nTrainer Report:")
+			s += m.trainer.report()
+		}
+		if m.drill != nil {
+			s += m.headerStyle.Render("\nCounting Report:")
+			s += m.drill.report()
+		}
 		s += "\nPress 'q' to quit"
 	}
 
+	if m.trainer != nil && m.trainer.feedback != "" && m.game.Phase() != engine.PhaseGameOver {
+		s += "\n" + m.trainer.feedback
+	}
+
+	if m.showCount && m.count != nil && (m.drill == nil || m.drill.asking == nil) {
+		s += fmt.Sprintf("\nHi-Lo running count %+d, true count %+d (%.1f decks left)", m.count.Running(), m.count.True(), m.count.DecksLeft())
+	}
+
+	if m.showTable {
+		s += m.headerStyle.Render(fmt.Sprintf("\nBasic strategy (%s):", m.game.Rules().Name))
+		s += "\n" + m.strategy.String()
+	}
+
+	return s
+}
+
+// viewActions lists the keys of the actions the current player can take.
+func (m model) viewActions() string {
+	var keys []string
+	for _, actionKey := range actionKeys {
+		if m.game.CanTake(actionKey.action) {
+			keys = append(keys, fmt.Sprintf("'%s' to %s", actionKey.key, actionKey.text))
+		}
+	}
+	return strings.Join(keys, ", ")
+}
+
+// observe counts the cards of the events of an action.
+func (m model) observe(events []engine.Event) {
+	if m.count != nil {
+		m.count.Observe(events)
+	}
+}
+
+// viewHint shows the action of the basic strategy for the current decision, or tells the keys of the hint and the
+// basic strategy table.
+func (m model) viewHint() string {
+	if m.strategy == nil {
+		return ""
+	}
+
+	if m.hint {
+		action := m.strategy.Action(m.game)
+		for _, actionKey := range actionKeys {
+			if actionKey.action == action {
+				return fmt.Sprintf("\nBasic strategy: %s ('%s') with %s", actionKey.text, actionKey.key, situation(m.game))
+			}
+		}
+	}
+	return "\nPress '?' for a hint, 'c' for the basic strategy table, 'o' for the count"
+}
+
+// viewPlayer renders a row for each hand of the player, with the player's name and bankroll on the first row and the
+// bet of each hand. The hand being played is marked on the player's turn.
+func (m model) viewPlayer(player *engine.Player, playing bool) []string {
+	hands := player.Hands
+	if len(hands) == 0 {
+		hands = []*engine.Hand{{Bet: player.Bet}}
+	}
+
+	var rows []string
+	for i, hand := range hands {
+		var cards []string
+		for _, card := range hand.Cards {
+			cards = append(cards, m.cardStyle.Render(card.String()))
+		}
+		if playing && len(hands) > 1 && i == player.Current {
+			cards = append([]string{">"}, cards...)
+		}
+
+		score := ""
+		if len(hand.Cards) > 0 {
+			score = fmt.Sprintf("(Score: %d)", hand.Score)
+		}
+
+		name, chips := "", fmt.Sprintf("bet $%d", hand.Bet)
+		if i == 0 {
+			name = player.Name + ":"
+			chips = fmt.Sprintf("$%d", player.Bankroll)
+			if hand.Bet > 0 {
+				chips = fmt.Sprintf("$%d bet $%d", player.Bankroll, hand.Bet)
+			}
+		}
+
+		rows = append(rows, lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			m.nameStyle.Render(name),
+			m.cardAreaStyle.Render(strings.Join(cards, " ")),
+			m.scoreStyle.Render(score),
+			m.chipsStyle.Render(chips),
+		))
+	}
+	return rows
+}
+
+// resultText describes the outcome of the hand and the chips won or lost.
+func resultText(hand *engine.Hand, dealerScore int) string {
+	detail := ""
+	switch {
+	case hand.Outcome == engine.BlackjackWin:
+		detail = "blackjack"
+	case hand.Outcome == engine.Win && dealerScore > 21:
+		detail = "dealer bust"
+	case hand.Outcome == engine.Loss && hand.Surrendered:
+		detail = "surrendered to a blackjack"
+	case hand.Outcome == engine.Loss && hand.Score > 21:
+		detail = "bust"
+	}
+	if hand.Doubled {
+		detail = strings.TrimPrefix(detail+", doubled", ", ")
+	}
+	if detail != "" {
+		detail = " (" + detail + ")"
+	}
+
+	switch hand.Outcome {
+	case engine.BlackjackWin, engine.Win:
+		return fmt.Sprintf("wins $%d%s", hand.Net, detail)
+	case engine.Push:
+		if hand.Doubled {
+			return "ties (push, doubled)"
+		}
+		return "ties (push)"
+	case engine.Surrender:
+		return fmt.Sprintf("surrenders, loses $%d", -hand.Net)
+	}
+	return fmt.Sprintf("loses $%d%s", -hand.Net, detail)
+}
+
+// viewStandings renders the players from the richest to the poorest, with their winnings and their tally of hands.
+func (m model) viewStandings() string {
+	standings := slices.Clone(m.game.Players())
+	slices.SortStableFunc(standings, func(a, b *engine.Player) int {
+		return b.Bankroll - a.Bankroll
+	})
+
+	s := ""
+	for i, player := range standings {
+		s += fmt.Sprintf("\n%d. %-10s $%-6d (%+d)  W%d L%d P%d", i+1, player.Name, player.Bankroll, player.Bankroll-engine.StartingBankroll, player.Wins, player.Losses, player.Pushes)
+	}
 	return s
 }
 
-// Run starts the Bubbletea program to run the Blackjack game with its UI.
+// Run asks for the mode, the table to play at and who plays its seats, then starts the Bubbletea program to run the Blackjack game with its
+// UI.
 func Run() {
-	program := tea.NewProgram(initialModel())
+	mode := selectMode()
+	rules := selectRules()
+	seats := selectSeats()
+
+	// Counting needs a shoe which isn't shuffled every round
+	if mode == modeCounting && rules.Penetration == 0 {
+		rules.Penetration = drillCutCard
+	}
+
+	rounds := 3
+	if mode == modeCounting {
+		rounds = countingRounds
+	}
+
+	m := newModel(engine.New(len(seats), rounds, rules)).(model)
+	for i, seat := range seats {
+		m.bots = append(m.bots, seat.policy(rules))
+		if seat != seatHuman {
+			m.game.Players()[i].Name = fmt.Sprintf("Bot %d", i+1)
+		}
+	}
+	switch mode {
+	case modeTrainer:
+		m.trainer = &trainer{}
+	case modeCounting:
+		m.drill = &drill{}
+	}
+
+	program := tea.NewProgram(m)
 	if _, err := program.Run(); err != nil {
 		panic(err) // Panic on program run error
 	}
 }
+
+// Command runs the blackjack command: gg blackjack sim [flags] simulates rounds without the UI, and gg blackjack plays
+// the game.
+func Command(args []string) error {
+	if len(args) == 0 {
+		Run()
+		return nil
+	}
+
+	if args[0] != "sim" {
+		return fmt.Errorf("unknown blackjack command %q, expected sim", args[0])
+	}
+	return sim.Run(args[1:])
+}
@@ -4,582 +4,359 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/Kaamkiya/gg/internal/app/blackjack/engine"
+	"github.com/Kaamkiya/gg/internal/app/blackjack/strategy"
+	"github.com/Kaamkiya/gg/internal/app/cards"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-// Test if there's a game instance properly created
-func TestGameInstanceCreation(t *testing.T) {
-	// Test creating a game with different configurations
-	tests := []struct {
-		name       string
-		numPlayers int
-		numRounds  int
-	}{
-		{"Single player, single round", 1, 1},
-		{"Two players, three rounds", 2, 3},
-		{"Four players, five rounds", 4, 5},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			game := NewBlackJackGame(tt.numPlayers, tt.numRounds)
-
-			// Check that game instance is created
-			if game == nil {
-				t.Fatal("Game instance should not be nil")
-			}
-
-			// Check that all components are initialized
-			if game.deck == nil {
-				t.Error("Deck should be initialized")
-			}
-
-			if game.dealer == nil {
-				t.Error("Dealer should be initialized")
-			}
-
-			if game.players == nil {
-				t.Error("Players slice should be initialized")
-			}
-
-			if len(game.players) != tt.numPlayers {
-				t.Errorf("Expected %d players, got %d", tt.numPlayers, len(game.players))
-			}
-
-			if game.numberOfRounds != tt.numRounds {
-				t.Errorf("Expected %d rounds, got %d", tt.numRounds, game.numberOfRounds)
-			}
-
-			// Check initial state
-			if game.currentRound != 1 {
-				t.Errorf("Expected initial round to be 1, got %d", game.currentRound)
-			}
-
-			if game.currentPlayer != 0 {
-				t.Errorf("Expected initial player to be 0, got %d", game.currentPlayer)
-			}
-
-			if game.phase != "deal" {
-				t.Errorf("Expected initial phase to be 'deal', got '%s'", game.phase)
-			}
-
-			// Check that players are properly initialized
-			for i, player := range game.players {
-				if player == nil {
-					t.Errorf("Player %d should not be nil", i)
-					continue
-				}
-				if !strings.Contains(player.name, "Player") {
-					t.Errorf("Player %d should have name containing 'Player', got '%s'", i, player.name)
-				}
-			}
-
-			// Check that dealer is properly initialized
-			if game.dealer.name != "Dealer" {
-				t.Errorf("Expected dealer name to be 'Dealer', got '%s'", game.dealer.name)
-			}
-		})
-	}
-}
-
-// Test if there are exactly 52 cards in a shuffled deck
-func TestDeckHas52Cards(t *testing.T) {
-	deck := &Deck{}
-	deck.Shuffle()
-
-	// Should have exactly 52 cards
-	if len(deck.cards) != 52 {
-		t.Errorf("Expected deck to have 52 cards, got %d", len(deck.cards))
-	}
-
-	// Verify all suits are present (13 cards each)
-	suitCount := make(map[Suit]int)
-	for _, card := range deck.cards {
-		suitCount[card.suit]++
-	}
-
-	expectedSuits := []Suit{Heart, Diamond, Club, Spade}
-	for _, suit := range expectedSuits {
-		if suitCount[suit] != 13 {
-			t.Errorf("Expected 13 cards of suit %v, got %d", suit, suitCount[suit])
-		}
-	}
-
-	// Verify all ranks are present (4 cards each)
-	rankCount := make(map[int]int)
-	for _, card := range deck.cards {
-		rankCount[card.rank]++
-	}
-
-	for rank := 1; rank <= 13; rank++ {
-		if rankCount[rank] != 4 {
-			t.Errorf("Expected 4 cards of rank %d, got %d", rank, rankCount[rank])
-		}
-	}
-}
+// loadModel creates a model playing the table of the state with the rules.
+func loadModel(t *testing.T, state string, rules engine.Rules) model {
+	t.Helper()
 
-// Test that deck maintains 52 cards after multiple shuffles
-func TestDeckConsistencyAfterShuffle(t *testing.T) {
-	deck := &Deck{}
-
-	// Shuffle multiple times
-	for i := 0; i < 10; i++ {
-		deck.Shuffle()
-		if len(deck.cards) != 52 {
-			t.Errorf("Shuffle %d: Expected 52 cards, got %d", i+1, len(deck.cards))
-		}
-	}
-}
-
-// Test that total value of cards is respected when hit
-func TestCardValueRespectedOnHit(t *testing.T) {
-	player := &Player{name: "Test Player"}
-	deck := &Deck{}
-
-	// Create a deck with known cards (in reverse order since Deal takes from end)
-	deck.cards = []Card{
-		{Heart, 5},    // 5 points (will be dealt last)
-		{Spade, 7},    // 7 points
-		{Club, 10},    // 10 points
-		{Diamond, 13}, // 10 points (King, will be dealt first)
-	}
-
-	// Test hitting with face card
-	initialScore := player.GetScore() // Should be 0
-	player.Hit(deck)                  // Should get King (10 points)
-	expectedScore := initialScore + 10
-	if player.GetScore() != expectedScore {
-		t.Errorf("Expected score %d after hitting King, got %d", expectedScore, player.GetScore())
+	game, err := engine.Load(state, rules)
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	// Test hitting with regular number
-	player.Hit(deck) // Should get 10
-	expectedScore += 10
-	if player.GetScore() != expectedScore {
-		t.Errorf("Expected score %d after hitting 10, got %d", expectedScore, player.GetScore())
-	}
-
-	// Test hitting with another number
-	player.Hit(deck) // Should get 7
-	expectedScore += 7
-	if player.GetScore() != expectedScore {
-		t.Errorf("Expected score %d after hitting 7, got %d", expectedScore, player.GetScore())
-	}
+	return newModel(game).(model)
 }
 
-// Test Ace value adjustment separately
-func TestAceValueInHit(t *testing.T) {
-	player := &Player{name: "Test Player"}
-	deck := &Deck{}
-
-	// Test Ace as 11 when safe
-	deck.cards = []Card{{Heart, 1}} // Ace
-	player.Hit(deck)                // Should get Ace (11 points)
-	if player.GetScore() != 11 {
-		t.Errorf("Expected score 11 after hitting Ace, got %d", player.GetScore())
-	}
-
-	// Test Ace adjustment when it would bust
-	player.SetHand([]Card{{Spade, 10}}) // Start with 10
-	player.UpdateScore()
-	deck.cards = []Card{{Heart, 1}} // Ace
-	player.Hit(deck)                // Should get Ace (11 points, but will adjust to 1 if > 21)
-	if player.GetScore() != 21 {
-		t.Errorf("Expected score 21 (10 + 11) after hitting Ace, got %d", player.GetScore())
-	}
-
-	// Test Ace adjustment when adding to existing high score
-	player.SetHand([]Card{{Spade, 10}, {Club, 5}}) // Start with 15
-	player.UpdateScore()
-	deck.cards = []Card{{Heart, 1}} // Ace
-	player.Hit(deck)                // Should get Ace (1 point to avoid bust)
-	if player.GetScore() != 16 {
-		t.Errorf("Expected score 16 (15 + 1) after hitting Ace to avoid bust, got %d", player.GetScore())
+// press sends the keys to the model.
+func press(m model, keys ...tea.KeyMsg) model {
+	for _, key := range keys {
+		updatedModel, _ := m.Update(key)
+		m = updatedModel.(model)
 	}
+	return m
 }
 
-// Test that Ace value adjusts correctly to prevent unnecessary busts
-func TestAceValueAdjustment(t *testing.T) {
-	player := &Player{name: "Test Player"}
-
-	// Test Ace counting as 11 when safe
-	player.SetHand([]Card{
-		{Heart, 1}, // Ace
-		{Spade, 5}, // 5
-	})
-	player.UpdateScore()
-
-	if player.GetScore() != 16 { // 11 + 5
-		t.Errorf("Expected score 16 (Ace as 11), got %d", player.GetScore())
-	}
-
-	// Test Ace counting as 1 when 11 would bust
-	player.SetHand([]Card{
-		{Heart, 1},  // Ace
-		{Spade, 10}, // 10
-		{Club, 8},   // 8
-	})
-	player.UpdateScore()
-
-	if player.GetScore() != 19 { // 1 + 10 + 8
-		t.Errorf("Expected score 19 (Ace as 1), got %d", player.GetScore())
-	}
-
-	// Test multiple Aces
-	player.SetHand([]Card{
-		{Heart, 1},   // Ace
-		{Diamond, 1}, // Ace
-		{Spade, 9},   // 9
-	})
-	player.UpdateScore()
-
-	if player.GetScore() != 21 { // 11 + 1 + 9
-		t.Errorf("Expected score 21 (one Ace as 11, one as 1), got %d", player.GetScore())
-	}
+// runes is the key message of a letter key.
+func runes(key string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
 }
 
-// Test that face cards are valued correctly
-func TestFaceCardValues(t *testing.T) {
-	player := &Player{name: "Test Player"}
+// Test complete game initialization
+func TestCompleteGameInitialization(t *testing.T) {
+	// Test model creation
+	modelInterface := initialModel()
+	m := modelInterface.(model)
 
-	// Test Jack (11) = 10 points
-	player.SetHand([]Card{{Heart, 11}})
-	player.UpdateScore()
-	if player.GetScore() != 10 {
-		t.Errorf("Expected Jack to be worth 10 points, got %d", player.GetScore())
+	// Verify game instance exists
+	if m.game == nil {
+		t.Fatal("Game instance should not be nil")
 	}
 
-	// Test Queen (12) = 10 points
-	player.SetHand([]Card{{Diamond, 12}})
-	player.UpdateScore()
-	if player.GetScore() != 10 {
-		t.Errorf("Expected Queen to be worth 10 points, got %d", player.GetScore())
+	// Verify deck is initialized
+	if m.game.Shoe() == nil {
+		t.Fatal("Deck should be initialized")
 	}
 
-	// Test King (13) = 10 points
-	player.SetHand([]Card{{Club, 13}})
-	player.UpdateScore()
-	if player.GetScore() != 10 {
-		t.Errorf("Expected King to be worth 10 points, got %d", player.GetScore())
-	}
+	// Initialize the deck
+	m.Init()
 
-	// Test all face cards together
-	player.SetHand([]Card{
-		{Heart, 11},   // Jack
-		{Diamond, 12}, // Queen
-		{Club, 13},    // King
-	})
-	player.UpdateScore()
-	if player.GetScore() != 30 {
-		t.Errorf("Expected J+Q+K to be worth 30 points, got %d", player.GetScore())
+	// Verify deck has 52 cards
+	if m.game.Shoe().Len() != 52 {
+		t.Errorf("Expected deck to have 52 cards, got %d", m.game.Shoe().Len())
 	}
-}
 
-// Test that going over 21 is considered a bust/loss
-func TestBustOver21(t *testing.T) {
-	player := &Player{name: "Test Player"}
-
-	// Create a hand that busts
-	player.SetHand([]Card{
-		{Heart, 10}, // 10
-		{Spade, 5},  // 5
-		{Club, 7},   // 7
-	})
-	player.UpdateScore()
-
-	score := player.GetScore()
-	if score <= 21 {
-		t.Errorf("Expected score to be over 21 (bust), got %d", score)
+	// Verify that the model has the essential components
+	// (Style components are initialized by initialModel function with lipgloss.NewStyle())
+	if m.game.Round() != 1 {
+		t.Errorf("Expected current round to be 1, got %d", m.game.Round())
 	}
 
-	if score != 22 {
-		t.Errorf("Expected exact score of 22, got %d", score)
+	if len(m.game.Players()) != 2 {
+		t.Errorf("Expected 2 players in initial model, got %d", len(m.game.Players()))
 	}
 }
 
 // Test bust scenario in actual game play
 func TestBustScenarioInGame(t *testing.T) {
-	m := model{
-		game: NewBlackJackGame(2, 3),
-	}
-	m.game.phase = "player_turn"
-	m.game.currentPlayer = 0
-
-	// Set up player with cards that will bust on next hit
-	m.game.players[0].SetHand([]Card{
-		{Heart, 10}, // 10
-		{Spade, 9},  // 9
-	})
-	m.game.players[0].UpdateScore()
+	m := loadModel(t, "1 3 player_turn 0 10♣ 1000:10:0:0:0:0:0:0:10♥,9♠;10;-;0;0 1000:10:0:0:0:0:0:0:10♦,7♠;10;-;0;0", engine.DefaultRules)
 
 	// Verify initial score
-	if m.game.players[0].GetScore() != 19 {
-		t.Errorf("Expected initial score 19, got %d", m.game.players[0].GetScore())
+	if m.game.Players()[0].GetScore() != 19 {
+		t.Errorf("Expected initial score 19, got %d", m.game.Players()[0].GetScore())
 	}
 
 	// Set up deck with card that will cause bust
-	m.game.deck.cards = []Card{{Club, 5}} // This will cause bust (19 + 5 = 24)
+	m.game.Shoe().Stack(cards.Card{Suit: cards.Club, Rank: 5}) // This will cause bust (19 + 5 = 24)
 
 	// Player hits
-	hitMsg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")}
-	updatedModel, _ := m.Update(hitMsg)
-	m = updatedModel.(model)
+	m = press(m, runes("h"))
 
 	// Check that player busted
-	finalScore := m.game.players[0].GetScore()
-	if finalScore <= 21 {
-		t.Errorf("Expected player to bust (score > 21), got %d", finalScore)
-	}
-
-	if finalScore != 24 {
+	if finalScore := m.game.Players()[0].GetScore(); finalScore != 24 {
 		t.Errorf("Expected exact bust score of 24, got %d", finalScore)
 	}
 
 	// Check that game advanced to next player due to bust
-	if m.game.currentPlayer != 1 {
-		t.Errorf("Expected game to advance to next player after bust, got player %d", m.game.currentPlayer)
+	if m.game.CurrentPlayer() != 1 {
+		t.Errorf("Expected game to advance to next player after bust, got player %d", m.game.CurrentPlayer())
 	}
 }
 
 // Test that dealer follows 17 rule
 func TestDealerFollows17Rule(t *testing.T) {
-	m := model{
-		game: NewBlackJackGame(2, 3),
-	}
-	m.game.phase = "dealer_turn"
-
-	// Set dealer with score under 17
-	m.game.dealer.SetHand([]Card{
-		{Heart, 6}, // 6
-		{Spade, 8}, // 8
-	})
-	m.game.dealer.UpdateScore()
+	m := loadModel(t, "1 3 dealer_turn 0 6♥,8♠ 1000:10:0:0:0:0:0:0:10♥,9♠;10;x;0;0 1000:10:0:0:0:0:0:0:10♦,7♠;10;x;0;0", engine.DefaultRules)
 
 	// Verify dealer score is under 17
-	if m.game.dealer.GetScore() != 14 {
-		t.Errorf("Expected dealer initial score 14, got %d", m.game.dealer.GetScore())
+	if m.game.Dealer().GetScore() != 14 {
+		t.Errorf("Expected dealer initial score 14, got %d", m.game.Dealer().GetScore())
 	}
 
 	// Set up deck with cards
-	m.game.deck.cards = []Card{
-		{Club, 5},    // 5
-		{Diamond, 4}, // 4
-		{Heart, 3},   // 3
-	}
+	m.game.Shoe().Stack(cards.Card{Suit: cards.Club, Rank: 5}, cards.Card{Suit: cards.Diamond, Rank: 4})
 
 	// Process dealer turn
 	updatedModel, _ := m.Update(nil)
 	m = updatedModel.(model)
 
 	// Dealer should have hit until >= 17
-	dealerScore := m.game.dealer.GetScore()
-	if dealerScore < 17 && dealerScore > 0 {
-		t.Errorf("Expected dealer to hit until score >= 17, got %d", dealerScore)
+	if dealerScore := m.game.Dealer().GetScore(); dealerScore != 19 {
+		t.Errorf("Expected dealer to hit once to 19, got %d", dealerScore)
 	}
 
 	// Should advance to round_end phase
-	if m.game.phase != "round_end" {
-		t.Errorf("Expected phase to be 'round_end', got '%s'", m.game.phase)
+	if m.game.Phase() != engine.PhaseRoundEnd {
+		t.Errorf("Expected phase to be 'round_end', got '%s'", m.game.Phase())
 	}
 }
 
-// Test complete game initialization
-func TestCompleteGameInitialization(t *testing.T) {
-	// Test model creation
-	modelInterface := initialModel()
-	m := modelInterface.(model)
+// Test that the shoe is shuffled between rounds once the cut card is reached, and that the players are told
+func TestShuffleBetweenRounds(t *testing.T) {
+	m := loadModel(t, "1 2 round_end 0 10♣,7♣ 1000:10:0:0:0:0:0:0:- 1000:10:0:0:0:0:0:0:-", engine.DefaultRules)
 
-	// Verify game instance exists
-	if m.game == nil {
-		t.Fatal("Game instance should not be nil")
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.game.Phase() != engine.PhaseBet || m.game.Shoe().Len() != 52 {
+		t.Fatalf("Expected the shoe to be shuffled for the bets, got phase %s and %d cards", m.game.Phase(), m.game.Shoe().Len())
 	}
 
-	// Verify deck is initialized
-	if m.game.deck == nil {
-		t.Fatal("Deck should be initialized")
+	if !strings.Contains(m.View(), "the shoe is shuffled") {
+		t.Errorf("Expected the shuffle to be shown, got:\n%s", m.View())
 	}
 
-	// Initialize the deck
-	m.game.deck.Shuffle()
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if strings.Contains(m.View(), "the shoe is shuffled") {
+		t.Errorf("Expected the shuffle to be shown until the first bet only, got:\n%s", m.View())
+	}
+}
 
-	// Verify deck has 52 cards
-	if len(m.game.deck.cards) != 52 {
-		t.Errorf("Expected deck to have 52 cards, got %d", len(m.game.deck.cards))
+// newDealtModel creates a model with its shoe shuffled and stacked with the cards: two for each player, then the
+// dealer's cards.
+func newDealtModel(rules engine.Rules, cards ...cards.Card) model {
+	m := newModel(engine.New(2, 3, rules)).(model)
+	m.Init()
+	m.game.Shoe().Stack(cards...)
+	return m
+}
+
+// Test the betting phase before the cards are dealt
+func TestBettingPhase(t *testing.T) {
+	m := newDealtModel(engine.DefaultRules,
+		cards.Card{Suit: cards.Heart, Rank: 10}, cards.Card{Suit: cards.Spade, Rank: 7},
+		cards.Card{Suit: cards.Club, Rank: 9}, cards.Card{Suit: cards.Spade, Rank: 8},
+		cards.Card{Suit: cards.Diamond, Rank: 10}, cards.Card{Suit: cards.Club, Rank: 6})
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyUp}, tea.KeyMsg{Type: tea.KeyUp}, tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.game.Players()[0].Bet != 20 {
+		t.Errorf("Expected the first player to bet 20, got %d", m.game.Players()[0].Bet)
 	}
 
-	// Verify that the model has the essential components
-	// (Style components are initialized by initialModel function with lipgloss.NewStyle())
-	if m.game.currentRound != 1 {
-		t.Errorf("Expected current round to be 1, got %d", m.game.currentRound)
+	if m.game.Phase() != engine.PhaseBet || m.game.CurrentPlayer() != 1 {
+		t.Fatalf("Expected the second player to bet, got phase '%s' and player %d", m.game.Phase(), m.game.CurrentPlayer())
+	}
+
+	// The bet can't go below the minimum
+	m = press(m, tea.KeyMsg{Type: tea.KeyDown})
+	if m.game.Players()[1].Bet != engine.MinBet {
+		t.Errorf("Expected the bet to stay at %d, got %d", engine.MinBet, m.game.Players()[1].Bet)
 	}
 
-	if len(m.game.players) != 2 {
-		t.Errorf("Expected 2 players in initial model, got %d", len(m.game.players))
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.game.Phase() != engine.PhasePlayerTurn || m.game.CurrentPlayer() != 0 || len(m.game.Players()[0].GetHand()) != 2 {
+		t.Errorf("Expected the cards to be dealt once every player has bet, got phase '%s'", m.game.Phase())
 	}
 }
 
-// Test that deck dealing reduces card count
-func TestDeckDealingReducesCount(t *testing.T) {
-	deck := &Deck{}
-	deck.Shuffle()
+// Test that the dealer's hole card is hidden until the dealer's turn
+func TestHoleCardHiddenUntilDealerTurn(t *testing.T) {
+	m := newDealtModel(engine.DefaultRules,
+		cards.Card{Suit: cards.Heart, Rank: 10}, cards.Card{Suit: cards.Spade, Rank: 7},
+		cards.Card{Suit: cards.Club, Rank: 9}, cards.Card{Suit: cards.Spade, Rank: 8},
+		cards.Card{Suit: cards.Diamond, Rank: 10}, cards.Card{Suit: cards.Club, Rank: 6})
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter}, tea.KeyMsg{Type: tea.KeyEnter})
 
-	initialCount := len(deck.cards)
-	if initialCount != 52 {
-		t.Errorf("Expected initial deck count of 52, got %d", initialCount)
+	if !strings.Contains(m.View(), "[Hidden]") {
+		t.Error("Expected the hole card to be hidden during the players' turns")
 	}
 
-	// Deal 10 cards
-	for i := 0; i < 10; i++ {
-		card := deck.Deal()
-		if card.suit < 0 || card.rank < 0 {
-			t.Errorf("Card %d should be valid, got invalid card", i)
-		}
+	m = press(m, runes("s"), runes("s"))
+	if m.game.Phase() != engine.PhaseDealerTurn || strings.Contains(m.View(), "[Hidden]") {
+		t.Error("Expected the hole card to be shown on the dealer's turn")
 	}
 
-	// Should have 42 cards left
-	if len(deck.cards) != 42 {
-		t.Errorf("Expected 42 cards after dealing 10, got %d", len(deck.cards))
+	// Without a hole card, the dealer only shows the upcard
+	m = newDealtModel(engine.Presets[len(engine.Presets)-1],
+		cards.Card{Suit: cards.Heart, Rank: 10}, cards.Card{Suit: cards.Spade, Rank: 7},
+		cards.Card{Suit: cards.Club, Rank: 9}, cards.Card{Suit: cards.Spade, Rank: 8},
+		cards.Card{Suit: cards.Diamond, Rank: 10})
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter}, tea.KeyMsg{Type: tea.KeyEnter})
+	if view := m.View(); strings.Contains(view, "[Hidden]") {
+		t.Errorf("Expected no hole card, got:\n%s", view)
 	}
 }
 
-// Test edge case: dealing from empty deck
-func TestDealingFromEmptyDeck(t *testing.T) {
-	deck := &Deck{}
-	// Don't shuffle - deck should be empty
+// Test the final standings of the game over screen
+func TestFinalStandings(t *testing.T) {
+	m := loadModel(t, "3 3 game_over 0 - 900:10:0:0:0:0:1:0:- 1150:10:0:0:0:1:0:0:-", engine.DefaultRules)
 
-	card := deck.Deal()
+	view := m.View()
+	first := strings.Index(view, "1. Player 2")
+	second := strings.Index(view, "2. Player 1")
+	if !strings.Contains(view, "Final Standings") || first == -1 || second < first {
+		t.Errorf("Expected the richest player first in the standings, got:\n%s", view)
+	}
 
-	// Should return invalid card
-	if card.suit != -1 || card.rank != -1 {
-		t.Errorf("Expected invalid card (-1, -1) from empty deck, got (%v, %d)", card.suit, card.rank)
+	if !strings.Contains(view, "(+150)") || !strings.Contains(view, "(-100)") {
+		t.Errorf("Expected the winnings in the standings, got:\n%s", view)
 	}
 }
 
-// Test that blackjack (21) is recognized correctly
-func TestBlackjackRecognition(t *testing.T) {
-	player := &Player{name: "Test Player"}
+// Test that the prompt offers the actions of the hand
+func TestActionPrompt(t *testing.T) {
+	m := loadModel(t, "1 1 player_turn 0 A♣ 1000:10:0:0:0:0:0:0:8♥,8♠;10;-;0;0", engine.DefaultRules)
+	m.game.Shoe().Stack(cards.Card{Suit: cards.Club, Rank: 2}, cards.Card{Suit: cards.Diamond, Rank: 3})
+
+	for _, action := range []string{"'d' to double down", "'p' to split", "'r' to surrender"} {
+		if !strings.Contains(m.View(), action) {
+			t.Errorf("Expected the prompt to offer %s, got:\n%s", action, m.View())
+		}
+	}
+
+	m = press(m, runes("p"))
+	if view := m.View(); !strings.Contains(view, "(hand 1/2)") || strings.Contains(view, "surrender") {
+		t.Errorf("Expected the first split hand to be played without surrender, got:\n%s", view)
+	}
+}
 
-	// Test natural blackjack (Ace + 10-value card)
-	player.SetHand([]Card{
-		{Heart, 1},  // Ace (11)
-		{Spade, 10}, // 10
-	})
-	player.UpdateScore()
+// Test that the hint key shows the basic strategy action, and that the table of the rules can be shown
+func TestHint(t *testing.T) {
+	m := loadModel(t, "1 1 player_turn 0 10♣ 1000:10:0:0:0:0:0:0:10♥,6♠;10;-;0;0", engine.Presets[1])
 
-	if player.GetScore() != 21 {
-		t.Errorf("Expected blackjack score of 21, got %d", player.GetScore())
+	if view := m.View(); !strings.Contains(view, "Press '?' for a hint") {
+		t.Errorf("Expected the prompt to offer a hint, got:\n%s", view)
 	}
 
-	// Test blackjack with face card
-	player.SetHand([]Card{
-		{Diamond, 1}, // Ace (11)
-		{Club, 13},   // King (10)
-	})
-	player.UpdateScore()
+	m = press(m, runes("?"))
+	if view := m.View(); !strings.Contains(view, "Basic strategy: surrender ('r') with hard 16 vs 10") {
+		t.Errorf("Expected the hint to surrender 16 against a 10, got:\n%s", view)
+	}
 
-	if player.GetScore() != 21 {
-		t.Errorf("Expected blackjack score of 21 with Ace+King, got %d", player.GetScore())
+	m = press(m, runes("c"))
+	if view := m.View(); !strings.Contains(view, "Basic strategy (Vegas Strip):") || !strings.Contains(view, "16    S  S  S  S  S  H  H  Rh Rh Rh") {
+		t.Errorf("Expected the basic strategy table of the rules, got:\n%s", view)
 	}
 }
 
-// Test flaws and edge cases
-func TestGameFlaws(t *testing.T) {
-	// Test empty deck handling
-	t.Run("Empty deck returns invalid card", func(t *testing.T) {
-		deck := &Deck{} // Empty deck
-		card := deck.Deal()
+// Test that the trainer counts the decisions which deviate from the basic strategy and reports the accuracy
+func TestTrainer(t *testing.T) {
+	m := loadModel(t, "1 1 player_turn 0 6♣ 1000:10:0:0:0:0:0:0:10♥,2♠;10;-;0;0 1000:10:0:0:0:0:0:0:10♦,8♠;10;-;0;0", engine.Presets[1])
+	m.trainer = &trainer{}
+	m.game.Shoe().Stack(cards.Card{Suit: cards.Club, Rank: 10})
 
-		if card.suit != -1 || card.rank != -1 {
-			t.Errorf("Expected invalid card from empty deck, got valid card")
-		}
-	})
-
-	// Test all players bust scenario
-	t.Run("All players bust should skip dealer", func(t *testing.T) {
-		m := model{game: NewBlackJackGame(2, 1)}
-		m.game.phase = "player_turn"
-
-		// Make both players bust
-		m.game.players[0].SetHand([]Card{{Heart, 10}, {Spade, 6}, {Club, 6}}) // 22
-		m.game.players[0].UpdateScore()
-		m.game.players[1].SetHand([]Card{{Diamond, 10}, {Heart, 5}, {Spade, 7}}) // 22
-		m.game.players[1].UpdateScore()
-
-		// Both players have busted, but dealer will still play
-		// This is a flaw - dealer shouldn't need to play if all players busted
-		if m.game.players[0].GetScore() <= 21 || m.game.players[1].GetScore() <= 21 {
-			t.Error("Test setup failed - players should be busted")
-		}
-	})
+	// 12 against a 6 stands, 18 too
+	m = press(m, runes("h"), runes("s"))
+	if m.trainer.decisions != 2 || len(m.trainer.deviations) != 1 {
+		t.Fatalf("Expected 1 deviation in 2 decisions, got %d in %d", len(m.trainer.deviations), m.trainer.decisions)
+	}
 
-	// Test deck exhaustion during game
-	t.Run("Deck exhaustion handling", func(t *testing.T) {
-		player := &Player{name: "Test"}
-		deck := &Deck{}
-		deck.cards = []Card{{Heart, 5}} // Only one card
+	if feedback := m.trainer.feedback; feedback != "" {
+		t.Errorf("Expected no correction after the right decision, got %q", feedback)
+	}
 
-		player.Hit(deck) // Takes the only card
+	m.game, _ = engine.Load("1 1 game_over 0 - 990:10:0:0:0:0:1:0:-", engine.Presets[1])
+	view := m.View()
+	if !strings.Contains(view, "Basic strategy accuracy: 50% (1 of 2 decisions)") || !strings.Contains(view, "hard 12 vs 6: hit instead of stand") {
+		t.Errorf("Expected the trainer report, got:\n%s", view)
+	}
+}
 
-		// Now deck is empty, next hit should handle gracefully
-		player.Hit(deck) // This will add invalid card {-1, -1}
+// Test that the counting drill quizzes the player on the count and reports the accuracy of the answers
+func TestCountingDrill(t *testing.T) {
+	m := loadModel(t, "2 4 dealer_turn 0 10♣,6♣ 1000:10:0:0:0:0:0:0:10♥,9♠;10;x;0;0", engine.Presets[1])
+	m.drill = &drill{}
+	m.game.Shoe().Shuffle()
+	m.game.Shoe().Stack(cards.Card{Suit: cards.Diamond, Rank: 4})
 
-		// Check if invalid card was added to hand
-		hand := player.GetHand()
-		if len(hand) != 2 {
-			t.Errorf("Expected 2 cards in hand, got %d", len(hand))
-		}
+	// The dealer draws a 4 for 20
+	m = press(m, runes("o"))
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.drill.asking == nil || !strings.Contains(m.View(), "what is the running count?") {
+		t.Fatalf("Expected a quiz at the end of the second round, got:\n%s", m.View())
+	}
 
-		// Last card should be invalid
-		lastCard := hand[len(hand)-1]
-		if lastCard.suit != -1 || lastCard.rank != -1 {
-			t.Error("Expected invalid card to be added when deck is empty")
-		}
-	})
+	if strings.Contains(m.View(), "Hi-Lo running count") {
+		t.Error("Expected the count to be hidden during the quiz")
+	}
 
-	// Test nil hand handling
-	t.Run("Nil hand initialization", func(t *testing.T) {
-		player := &Player{name: "Test"}
-		player.SetHand(nil)
+	m = press(m, runes("2"), tea.KeyMsg{Type: tea.KeyBackspace}, runes("1"), tea.KeyMsg{Type: tea.KeyEnter})
+	m = press(m, runes("-"), runes("3"), tea.KeyMsg{Type: tea.KeyEnter})
+	if m.drill.asking != nil || !strings.Contains(m.View(), "Running count +1 is right, true count is +0, not -3") {
+		t.Fatalf("Expected the answers to be checked, got:\n%s", m.View())
+	}
 
-		// This should not panic
-		score := player.GetScore()
-		if score != 0 {
-			t.Errorf("Expected score 0 for empty hand, got %d", score)
-		}
+	if !strings.Contains(m.View(), "Hi-Lo running count +1, true count +0 (6.0 decks left)") {
+		t.Errorf("Expected the count to be shown, got:\n%s", m.View())
+	}
 
-		player.UpdateScore() // Should not panic with nil hand
-	})
+	m.game, _ = engine.Load("4 4 game_over 0 - 1000:10:0:0:0:0:0:0:-", engine.Presets[1])
+	if view := m.View(); !strings.Contains(view, "Running count: 1 of 1 right (100%)") || !strings.Contains(view, "True count: 0 of 1 right (0%)") {
+		t.Errorf("Expected the counting report, got:\n%s", view)
+	}
 }
 
-// Test round management edge cases
-func TestRoundManagementFlaws(t *testing.T) {
-	// Test deck state between rounds
-	t.Run("Deck state between rounds", func(t *testing.T) {
-		m := model{game: NewBlackJackGame(2, 2)}
-		m.game.deck.Shuffle()
-
-		// Deal some cards
-		m.game.dealInitialCards()
-		initialDeckSize := len(m.game.deck.cards)
+// Test that the bots play their seats after a delay, and that the keys don't play for them
+func TestBots(t *testing.T) {
+	m := newDealtModel(engine.DefaultRules,
+		cards.Card{Suit: cards.Heart, Rank: 10}, cards.Card{Suit: cards.Spade, Rank: 7},
+		cards.Card{Suit: cards.Club, Rank: 10}, cards.Card{Suit: cards.Spade, Rank: 6},
+		cards.Card{Suit: cards.Diamond, Rank: 10}, cards.Card{Suit: cards.Club, Rank: 7},
+		cards.Card{Suit: cards.Diamond, Rank: 5})
+	m.bots = []strategy.Policy{nil, strategy.HitUnder17{}}
+	m.game.Players()[1].Name = "Bot 2"
+
+	updatedModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updatedModel.(model)
+	if cmd == nil || !strings.Contains(m.View(), "Bot 2 is playing...") {
+		t.Fatalf("Expected the bot's bet to be scheduled, got:\n%s", m.View())
+	}
 
-		// Advance to next round
-		m.game.currentRound = 1
-		m.game.phase = "round_end"
+	m = press(m, runes("+"), tea.KeyMsg{Type: tea.KeyEnter})
+	if m.game.Phase() != engine.PhaseBet || m.game.Players()[1].Bet != engine.MinBet {
+		t.Fatalf("Expected the keys not to bet for the bot, got phase '%s' and a bet of %d", m.game.Phase(), m.game.Players()[1].Bet)
+	}
 
-		enterMsg := tea.KeyMsg{Type: tea.KeyEnter}
-		updatedModel, _ := m.Update(enterMsg)
-		m = updatedModel.(model)
+	updatedModel, cmd = m.Update(botMsg{})
+	m = updatedModel.(model)
+	if m.game.Phase() != engine.PhasePlayerTurn || m.game.CurrentPlayer() != 0 || cmd != nil {
+		t.Fatalf("Expected the cards to be dealt once the bot has bet, got phase '%s'", m.game.Phase())
+	}
 
-		// Check if deck was reshuffled (should have 52 cards again)
-		newDeckSize := len(m.game.deck.cards)
-		if newDeckSize != 52 {
-			t.Errorf("Expected deck to be reshuffled to 52 cards, got %d", newDeckSize)
+	// The bot hits its 16 for 21, then the dealer plays
+	m = press(m, runes("s"))
+	for moves := 0; m.bot() != nil; moves++ {
+		if moves == 3 {
+			t.Fatal("Expected the bot to stop playing")
 		}
+		updatedModel, _ = m.Update(botMsg{})
+		m = updatedModel.(model)
+	}
 
-		// This is actually a flaw - the used cards are not returned to deck
-		// before reshuffling, they're just lost and a new deck is created
-		if newDeckSize == initialDeckSize {
-			t.Error("Deck should be different size after reshuffling, but cards are lost")
-		}
-	})
+	if m.game.Phase() != engine.PhaseDealerTurn || m.game.Players()[1].Hand().Score != 21 {
+		t.Errorf("Expected the bot to hit 16 and stand on 21, got phase '%s' and %d", m.game.Phase(), m.game.Players()[1].Hand().Score)
+	}
 }
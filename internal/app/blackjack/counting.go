@@ -0,0 +1,106 @@
+package blackjack
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Kaamkiya/gg/internal/app/blackjack/strategy"
+)
+
+// -------------------- STRUCT: drill --------------------
+
+// quiz is a question of the counting drill on the running count and the true count, with the player's answers.
+type quiz struct {
+	running, trueCount             int // Counts when the quiz was asked.
+	runningAnswer, trueCountAnswer int // Counts answered by the player.
+}
+
+// drill quizzes the player on the count every few rounds.
+type drill struct {
+	quizzes  []quiz // Quizzes answered.
+	asking   *quiz  // Quiz being asked, nil between the quizzes.
+	answered int    // Number of counts of the quiz being asked already answered: the running count then the true count.
+	input    string // Answer being typed.
+	feedback string // Corrections of the last quiz.
+}
+
+// ask starts a quiz on the count.
+func (drill *drill) ask(count *strategy.Count) {
+	drill.asking = &quiz{running: count.Running(), trueCount: count.True()}
+	drill.answered = 0
+	drill.input = ""
+	drill.feedback = ""
+}
+
+// question returns the question being asked.
+func (drill *drill) question() string {
+	if drill.answered == 0 {
+		return "what is the running count?"
+	}
+	return "what is the true count?"
+}
+
+// key types the answer: digits and a minus sign, backspace to correct it and enter to answer.
+func (drill *drill) key(key string) {
+	switch key {
+	case "backspace":
+		if drill.input != "" {
+			drill.input = drill.input[:len(drill.input)-1]
+		}
+	case "-":
+		if drill.input == "" {
+			drill.input = "-"
+		}
+	case "enter":
+		answer, err := strconv.Atoi(drill.input)
+		if err != nil {
+			return
+		}
+		drill.input = ""
+
+		if drill.answered == 0 {
+			drill.asking.runningAnswer = answer
+			drill.answered++
+			return
+		}
+		drill.asking.trueCountAnswer = answer
+		drill.quizzes = append(drill.quizzes, *drill.asking)
+		drill.feedback = drill.asking.feedback()
+		drill.asking = nil
+	default:
+		if len(key) == 1 && key[0] >= '0' && key[0] <= '9' && len(drill.input) < 4 {
+			drill.input += key
+		}
+	}
+}
+
+// feedback tells whether the answers of the quiz are right.
+func (quiz quiz) feedback() string {
+	check := func(name string, count, answer int) string {
+		if count == answer {
+			return fmt.Sprintf("%s %+d is right", name, count)
+		}
+		return fmt.Sprintf("%s is %+d, not %+d", name, count, answer)
+	}
+	return check("Running count", quiz.running, quiz.runningAnswer) + ", " + check("true count", quiz.trueCount, quiz.trueCountAnswer)
+}
+
+// report describes the accuracy of the answers of the session.
+func (drill *drill) report() string {
+	running, trueCount := 0, 0
+	for _, quiz := range drill.quizzes {
+		if quiz.running == quiz.runningAnswer {
+			running++
+		}
+		if quiz.trueCount == quiz.trueCountAnswer {
+			trueCount++
+		}
+	}
+
+	total := len(drill.quizzes)
+	if total == 0 {
+		return "\nNo quiz answered"
+	}
+	return fmt.Sprintf("\nRunning count: %d of %d right (%d%%)\nTrue count: %d of %d right (%d%%)",
+		running, total, 100*running/total, trueCount, total, 100*trueCount/total)
+}
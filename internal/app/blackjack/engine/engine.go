@@ -0,0 +1,578 @@
+// Package engine implements the rules of blackjack without any user interface. The game moves from phase to phase
+// only through the actions of the players and the dealer, which return an error when the rules don't allow them and
+// the events of what happened otherwise, like the cards dealt and the bets settled.
+package engine
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Kaamkiya/gg/internal/app/cards"
+)
+
+// -------------------- ENUM: Phase --------------------
+
+// Phase of a round of blackjack.
+type Phase int
+
+const (
+	// PhaseBet lets the players with enough chips change and place their bet, one after the other.
+	PhaseBet Phase = iota
+	// PhaseInsurance lets the players take or decline the insurance while the dealer shows an ace.
+	PhaseInsurance
+	// PhasePlayerTurn lets the players play their hands, one after the other.
+	PhasePlayerTurn
+	// PhaseDealerTurn waits for the dealer, whose hole card is turned, to play.
+	PhaseDealerTurn
+	// PhaseRoundEnd shows the settled bets until the next round.
+	PhaseRoundEnd
+	// PhaseGameOver ends the game after the last round or once nobody can bet.
+	PhaseGameOver
+)
+
+var phaseNames = [...]string{"bet", "insurance", "player_turn", "dealer_turn", "round_end", "game_over"}
+
+// String returns the name of the phase, e.g. "player_turn".
+func (phase Phase) String() string {
+	return phaseNames[phase]
+}
+
+// ParsePhase reads a phase written by Phase.String.
+func ParsePhase(text string) (Phase, error) {
+	for phase, name := range phaseNames {
+		if name == text {
+			return Phase(phase), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid phase %q", text)
+}
+
+// -------------------- ENUM: Action --------------------
+
+// Action a player takes on their current hand during their turn, or on the insurance.
+type Action string
+
+const (
+	// ActionHit draws a card.
+	ActionHit Action = "hit"
+	// ActionStand keeps the hand as it is.
+	ActionStand Action = "stand"
+	// ActionDouble doubles the bet of the first two cards and draws exactly one more card.
+	ActionDouble Action = "double"
+	// ActionSplit splits a pair into two hands, the new hand getting the same bet.
+	ActionSplit Action = "split"
+	// ActionSurrender gives up the first two cards for half the bet. It is a late surrender: the whole bet is lost if
+	// the dealer turns out to have a blackjack.
+	ActionSurrender Action = "surrender"
+	// ActionInsurance bets half the bet that the dealer, showing an ace, has a blackjack. It pays 2:1.
+	ActionInsurance Action = "insurance"
+	// ActionDecline declines the insurance.
+	ActionDecline Action = "decline"
+)
+
+// Actions are all the actions, in the order the interfaces offer them.
+var Actions = []Action{ActionHit, ActionStand, ActionDouble, ActionSplit, ActionSurrender, ActionInsurance, ActionDecline}
+
+// ErrIllegal is returned for the actions the rules don't allow.
+var ErrIllegal = errors.New("illegal action")
+
+// -------------------- STRUCT: Event --------------------
+
+// EventKind describes what happened during an action.
+type EventKind int
+
+const (
+	// Shuffled tells that the shoe was shuffled.
+	Shuffled EventKind = iota
+	// Dealt tells that Card was dealt to the hand of the player, face down for the hole card of the dealer.
+	Dealt
+	// HoleCardTurned tells that the dealer turned the hole card Card.
+	HoleCardTurned
+	// BetPlaced tells that the player placed a bet of Net chips.
+	BetPlaced
+	// ActionTaken tells that the player took Action on the hand.
+	ActionTaken
+	// HandSettled tells the Outcome of the hand and the Net chips won.
+	HandSettled
+	// InsuranceSettled tells the Net chips won on the insurance of the player.
+	InsuranceSettled
+	// RoundOver tells that the bets of the round were settled.
+	RoundOver
+	// GameOver tells that the game ended.
+	GameOver
+)
+
+// Dealer is the player of the events of the dealer.
+const Dealer = -1
+
+// Event is returned by the actions. Player is the index of the player, or Dealer, and Hand the index of their hand.
+type Event struct {
+	Kind     EventKind
+	Player   int
+	Hand     int
+	Card     cards.Card
+	FaceDown bool
+	Action   Action
+	Outcome  Outcome
+	Net      int
+}
+
+// -------------------- STRUCT: Game --------------------
+
+// Representation of a blackjack game.
+type Game struct {
+	shoe           *Shoe
+	rules          Rules
+	dealer         *Player
+	players        []*Player
+	numberOfRounds int     // Total number of rounds to play.
+	currentRound   int     // Current round number.
+	currentPlayer  int     // Index of the current player in players slice.
+	phase          Phase   // Current phase of the round.
+	events         []Event // Events of the action being played.
+}
+
+// MaxPlayers is the number of seats at a table.
+const MaxPlayers = 7
+
+// New creates a new Blackjack game with the specified number of players, from 1 to MaxPlayers, and rounds, played
+// with the rules and the shoe of the table.
+// Every player starts with the same bankroll, and the game starts with the bets of the first round.
+func New(numPlayers, rounds int, rules Rules) *Game {
+	players := make([]*Player, numPlayers)
+	for i := 0; i < numPlayers; i++ {
+		players[i] = &Player{Name: fmt.Sprintf("Player %d", i+1), Bankroll: StartingBankroll, Bet: MinBet}
+	}
+	return &Game{
+		shoe:           NewShoe(rules.Decks, rules.Penetration),
+		rules:          rules,
+		dealer:         &Player{Name: "Dealer"},
+		players:        players,
+		numberOfRounds: rounds,
+		currentRound:   1,
+		currentPlayer:  0,
+		phase:          PhaseBet,
+	}
+}
+
+// Phase returns the current phase of the round.
+func (game *Game) Phase() Phase {
+	return game.phase
+}
+
+// Rules returns the rules of the table.
+func (game *Game) Rules() Rules {
+	return game.rules
+}
+
+// Round returns the number of the current round, from 1.
+func (game *Game) Round() int {
+	return game.currentRound
+}
+
+// Rounds returns the number of rounds of the game.
+func (game *Game) Rounds() int {
+	return game.numberOfRounds
+}
+
+// CurrentPlayer returns the index of the player betting or playing.
+func (game *Game) CurrentPlayer() int {
+	return game.currentPlayer
+}
+
+// Players returns the players of the table.
+func (game *Game) Players() []*Player {
+	return game.players
+}
+
+// Dealer returns the dealer.
+func (game *Game) Dealer() *Player {
+	return game.dealer
+}
+
+// Shoe returns the shoe the cards are dealt from.
+func (game *Game) Shoe() *Shoe {
+	return game.shoe
+}
+
+// HoleCardHidden tells if the dealer's second card is still face down: it is turned on the dealer's turn.
+func (game *Game) HoleCardHidden() bool {
+	switch game.phase {
+	case PhaseDealerTurn, PhaseRoundEnd, PhaseGameOver:
+		return false
+	}
+	return true
+}
+
+// Upcard returns the dealer's face up card, or false before the deal.
+func (game *Game) Upcard() (cards.Card, bool) {
+	hand := game.dealer.GetHand()
+	if len(hand) == 0 {
+		return cards.Card{}, false
+	}
+	return hand[0], true
+}
+
+// DealerHits tells if the dealer must hit: below 17, or on a soft 17 if the rules say so.
+func (game *Game) DealerHits() bool {
+	score := game.dealer.GetScore()
+	return score < 17 || score == 17 && game.rules.HitSoft17 && game.dealer.Hand().Soft()
+}
+
+// Shuffle shuffles the shoe and returns the Shuffled event.
+func (game *Game) Shuffle() []Event {
+	game.shuffle()
+	return game.flush()
+}
+
+// ChangeBet adds delta chips to the bet of the current player, keeping it between the minimum bet and the bankroll.
+func (game *Game) ChangeBet(delta int) error {
+	if game.phase != PhaseBet {
+		return fmt.Errorf("%w: no bets during the %s phase", ErrIllegal, game.phase)
+	}
+	game.players[game.currentPlayer].changeBet(delta)
+	return nil
+}
+
+// PlaceBet confirms the bet of the current player and passes to the next player, and deals the cards once every
+// player has bet.
+func (game *Game) PlaceBet() ([]Event, error) {
+	if game.phase != PhaseBet {
+		return nil, fmt.Errorf("%w: no bets during the %s phase", ErrIllegal, game.phase)
+	}
+
+	game.emit(Event{Kind: BetPlaced, Player: game.currentPlayer, Net: game.players[game.currentPlayer].Bet})
+	game.currentPlayer = game.nextBettor(game.currentPlayer + 1)
+	if game.currentPlayer >= len(game.players) {
+		game.deal()
+	}
+	return game.flush(), nil
+}
+
+// CanTake tells if the current player can take the action: take or decline the insurance while the dealer shows an
+// ace, or play their current hand on their turn. Doubling down, splitting and surrendering need the first two cards
+// of the hand, and only the first hand of the round can be surrendered. The rules of the table can forbid doubling
+// down split hands and surrendering.
+func (game *Game) CanTake(action Action) bool {
+	if game.currentPlayer >= len(game.players) {
+		return false
+	}
+	player := game.players[game.currentPlayer]
+
+	switch game.phase {
+	case PhaseInsurance:
+		insurance := player.Bet / 2
+		return action == ActionDecline || action == ActionInsurance && insurance > 0 && player.CanAfford(insurance)
+	case PhasePlayerTurn:
+	default:
+		return false
+	}
+
+	hand := player.Hand()
+	if hand.Done {
+		return false
+	}
+	firstCards := len(hand.Cards) == 2
+
+	switch action {
+	case ActionHit, ActionStand:
+		return true
+	case ActionDouble:
+		return firstCards && (!hand.Split || game.rules.DoubleAfterSplit) && player.CanAfford(hand.Bet)
+	case ActionSplit:
+		return hand.IsPair() && len(player.Hands) < maxHands && player.CanAfford(hand.Bet)
+	case ActionSurrender:
+		return game.rules.Surrender && firstCards && len(player.Hands) == 1
+	}
+	return false
+}
+
+// Take plays the action of the current player, and passes to their next hand or to the next player once the hand is
+// done.
+func (game *Game) Take(action Action) ([]Event, error) {
+	if !game.CanTake(action) {
+		return nil, fmt.Errorf("%w: %s during the %s phase", ErrIllegal, action, game.phase)
+	}
+
+	player := game.players[game.currentPlayer]
+	game.emit(Event{Kind: ActionTaken, Player: game.currentPlayer, Hand: player.Current, Action: action})
+
+	if game.phase == PhaseInsurance {
+		if action == ActionInsurance {
+			player.Insurance = player.Bet / 2
+		}
+		game.nextInsurer()
+		return game.flush(), nil
+	}
+
+	hand := player.Hand()
+	switch action {
+	case ActionHit:
+		game.draw(game.currentPlayer, player.Current)
+		hand.Done = hand.Score >= 21
+	case ActionStand:
+		hand.Done = true
+	case ActionDouble:
+		hand.Bet *= 2
+		hand.Doubled = true
+		game.draw(game.currentPlayer, player.Current)
+		hand.Done = true
+	case ActionSplit:
+		game.split(player)
+	case ActionSurrender:
+		hand.Surrendered = true
+		hand.Done = true
+	}
+
+	if hand.Done {
+		game.nextHand()
+	}
+	return game.flush(), nil
+}
+
+// PlayDealer makes the dealer hit until reaching 17 or higher, and hit a soft 17 if the rules say so, unless every
+// hand has busted, was surrendered or is a natural blackjack. The round ends by settling the bets.
+func (game *Game) PlayDealer() ([]Event, error) {
+	if game.phase != PhaseDealerTurn {
+		return nil, fmt.Errorf("%w: the dealer doesn't play during the %s phase", ErrIllegal, game.phase)
+	}
+
+	// Without a hole card, the dealer draws the second card now
+	for len(game.dealer.GetHand()) < 2 {
+		game.draw(Dealer, 0)
+	}
+
+	live := false
+	for _, player := range game.players {
+		if player.Bet == 0 {
+			continue
+		}
+		player.Hand() // A player with a bet has at least one hand
+		for _, hand := range player.Hands {
+			live = live || hand.Score <= 21 && !hand.Surrendered && !hand.HasBlackjack()
+		}
+	}
+	for live && game.DealerHits() {
+		game.draw(Dealer, 0)
+	}
+
+	game.settleBets()
+	game.phase = PhaseRoundEnd
+	game.emit(Event{Kind: RoundOver})
+	return game.flush(), nil
+}
+
+// NextRound opens the bets of the next round, shuffling the shoe if the cut card was reached, or ends the game after
+// the last round or once no player has enough chips left to bet.
+func (game *Game) NextRound() ([]Event, error) {
+	if game.phase != PhaseRoundEnd {
+		return nil, fmt.Errorf("%w: the round is not over", ErrIllegal)
+	}
+
+	if game.currentRound >= game.numberOfRounds || !game.openBets() {
+		game.phase = PhaseGameOver
+		game.emit(Event{Kind: GameOver})
+		return game.flush(), nil
+	}
+
+	game.currentRound++
+	if game.shoe.CutCardReached() {
+		game.shuffle()
+	}
+	return game.flush(), nil
+}
+
+// emit records an event of the action being played.
+func (game *Game) emit(event Event) {
+	game.events = append(game.events, event)
+}
+
+// flush returns the events of the action and forgets them.
+func (game *Game) flush() []Event {
+	events := game.events
+	game.events = nil
+	return events
+}
+
+// shuffle shuffles the shoe.
+func (game *Game) shuffle() {
+	game.shoe.Shuffle()
+	game.emit(Event{Kind: Shuffled})
+}
+
+// draw deals a card from the shoe to the hand of the player, or to the dealer. A shoe running out in the middle of
+// a round is shuffled again. The dealer's second card is the face down hole card until the dealer's turn.
+func (game *Game) draw(player, hand int) {
+	if game.shoe.Len() == 0 {
+		game.shuffle()
+	}
+
+	card := game.shoe.Deal()
+	event := Event{Kind: Dealt, Player: player, Hand: hand, Card: card}
+	if player == Dealer {
+		game.dealer.Hand().add(card)
+		event.FaceDown = len(game.dealer.GetHand()) == 2 && game.HoleCardHidden()
+	} else {
+		game.players[player].Hands[hand].add(card)
+	}
+	game.emit(event)
+}
+
+// dealInitialCards deals a hand of two cards to each player with a bet and to the dealer, clearing the last round.
+// The natural blackjacks are done right away. Without a hole card, the dealer only gets one card.
+func (game *Game) dealInitialCards() {
+	for i, player := range game.players {
+		player.clearHands()
+		if player.Bet == 0 {
+			continue
+		}
+		player.Hand()
+		for j := 0; j < 2; j++ {
+			game.draw(i, 0)
+		}
+		player.Hand().Done = player.HasBlackjack()
+	}
+	game.dealer.clearHands()
+	game.draw(Dealer, 0)
+	if !game.rules.NoHoleCard {
+		game.draw(Dealer, 0)
+	}
+}
+
+// nextBettor returns the index of the first player with a bet after the given index, or the number of players if
+// there is none.
+func (game *Game) nextBettor(index int) int {
+	for index < len(game.players) && game.players[index].Bet == 0 {
+		index++
+	}
+	return index
+}
+
+// deal deals the cards of the round. The players are offered insurance if the dealer shows an ace, then the dealer
+// peeks for a blackjack.
+func (game *Game) deal() {
+	game.dealInitialCards()
+	game.currentPlayer = game.nextBettor(0)
+	game.phase = PhaseInsurance
+	if upcard, _ := game.Upcard(); upcard.Rank != cards.Ace {
+		game.peek()
+	}
+}
+
+// nextInsurer passes the insurance to the next player with a bet, or has the dealer peek after the last player.
+func (game *Game) nextInsurer() {
+	game.currentPlayer = game.nextBettor(game.currentPlayer + 1)
+	if game.currentPlayer >= len(game.players) {
+		game.peek()
+	}
+}
+
+// peek has the dealer check the hole card for a blackjack, which ends the round right away, and otherwise starts the
+// turns of the players. Without a hole card, the players always play their turns.
+func (game *Game) peek() {
+	game.currentPlayer = game.nextBettor(0)
+	game.phase = PhasePlayerTurn
+	if !game.rules.NoHoleCard && game.dealer.HasBlackjack() {
+		game.startDealerTurn()
+		return
+	}
+	game.startTurn()
+}
+
+// split splits the pair of the current hand of the player and deals a second card to both hands. Split aces get one
+// card each and can't be played any further.
+func (game *Game) split(player *Player) {
+	hand := player.Hand()
+	second := player.split()
+	aces := hand.Cards[0].Rank == cards.Ace
+
+	for i, h := range []*Hand{hand, second} {
+		game.draw(game.currentPlayer, player.Current+i)
+		h.SplitAces = aces
+		h.Done = aces || h.Score == 21
+	}
+}
+
+// nextHand passes to the next hand of the current player which isn't done yet, or to the next player after their
+// last hand.
+func (game *Game) nextHand() {
+	player := game.players[game.currentPlayer]
+	for player.Current+1 < len(player.Hands) {
+		player.Current++
+		if !player.Hand().Done {
+			return
+		}
+	}
+	game.nextPlayer()
+}
+
+// nextPlayer passes the turn to the next player with a bet, or to the dealer after the last player.
+func (game *Game) nextPlayer() {
+	game.currentPlayer = game.nextBettor(game.currentPlayer + 1)
+	game.startTurn()
+}
+
+// startTurn skips the players from the current one whose hand was done on the deal, a natural blackjack, and starts
+// the dealer's turn after the last player.
+func (game *Game) startTurn() {
+	for game.currentPlayer < len(game.players) && game.players[game.currentPlayer].Hand().Done {
+		game.currentPlayer = game.nextBettor(game.currentPlayer + 1)
+	}
+	if game.currentPlayer >= len(game.players) {
+		game.startDealerTurn()
+	}
+}
+
+// startDealerTurn turns the dealer's hole card.
+func (game *Game) startDealerTurn() {
+	game.phase = PhaseDealerTurn
+	if hand := game.dealer.GetHand(); len(hand) == 2 {
+		game.emit(Event{Kind: HoleCardTurned, Player: Dealer, Card: hand[1]})
+	}
+}
+
+// settleBets settles each hand of the players: the hands beating the dealer are paid, more for a natural blackjack,
+// the bets of a push are given back, surrendered hands lose half their bet and the others lose their bet. The
+// insurance pays 2:1 if the dealer has a blackjack and is lost otherwise.
+func (game *Game) settleBets() {
+	dealerScore := game.dealer.GetScore()
+	dealerBlackjack := game.dealer.HasBlackjack()
+
+	for i, player := range game.players {
+		if player.Bet == 0 {
+			continue
+		}
+
+		if player.Insurance > 0 {
+			player.InsuranceNet = -player.Insurance
+			if dealerBlackjack {
+				player.InsuranceNet = 2 * player.Insurance
+			}
+			player.Bankroll += player.InsuranceNet
+			game.emit(Event{Kind: InsuranceSettled, Player: i, Net: player.InsuranceNet})
+		}
+
+		for j, hand := range player.Hands {
+			player.settle(hand, hand.against(dealerScore, dealerBlackjack), game.rules.BlackjackPayout)
+			game.emit(Event{Kind: HandSettled, Player: i, Hand: j, Outcome: hand.Outcome, Net: hand.Net})
+		}
+	}
+}
+
+// openBets clears the table and starts the betting phase. Players keep their last bet if they can afford it, and
+// players without enough chips sit out. It returns false if nobody can bet.
+func (game *Game) openBets() bool {
+	game.dealer.clearHands()
+	for _, player := range game.players {
+		player.clearHands()
+		if player.Bankroll < MinBet {
+			player.Bet = 0
+		} else {
+			player.changeBet(0)
+		}
+	}
+
+	game.currentPlayer = game.nextBettor(0)
+	game.phase = PhaseBet
+	return game.currentPlayer < len(game.players)
+}
@@ -0,0 +1,812 @@
+package engine
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/app/cards"
+)
+
+// Test if there's a game instance properly created
+func TestGameInstanceCreation(t *testing.T) {
+	// Test creating a game with different configurations
+	tests := []struct {
+		name       string
+		numPlayers int
+		numRounds  int
+	}{
+		{"Single player, single round", 1, 1},
+		{"Two players, three rounds", 2, 3},
+		{"Four players, five rounds", 4, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := New(tt.numPlayers, tt.numRounds, DefaultRules)
+
+			// Check that game instance is created
+			if game == nil {
+				t.Fatal("Game instance should not be nil")
+			}
+
+			// Check that all components are initialized
+			if game.shoe == nil {
+				t.Error("Deck should be initialized")
+			}
+
+			if game.dealer == nil {
+				t.Error("Dealer should be initialized")
+			}
+
+			if game.players == nil {
+				t.Error("Players slice should be initialized")
+			}
+
+			if len(game.players) != tt.numPlayers {
+				t.Errorf("Expected %d players, got %d", tt.numPlayers, len(game.players))
+			}
+
+			if game.numberOfRounds != tt.numRounds {
+				t.Errorf("Expected %d rounds, got %d", tt.numRounds, game.numberOfRounds)
+			}
+
+			// Check initial state
+			if game.currentRound != 1 {
+				t.Errorf("Expected initial round to be 1, got %d", game.currentRound)
+			}
+
+			if game.currentPlayer != 0 {
+				t.Errorf("Expected initial player to be 0, got %d", game.currentPlayer)
+			}
+
+			if game.phase != PhaseBet {
+				t.Errorf("Expected initial phase to be 'bet', got '%s'", game.phase)
+			}
+
+			// Check that players are properly initialized
+			for i, player := range game.players {
+				if player == nil {
+					t.Errorf("Player %d should not be nil", i)
+					continue
+				}
+				if !strings.Contains(player.Name, "Player") {
+					t.Errorf("Player %d should have name containing 'Player', got '%s'", i, player.Name)
+				}
+			}
+
+			// Check that dealer is properly initialized
+			if game.dealer.Name != "Dealer" {
+				t.Errorf("Expected dealer name to be 'Dealer', got '%s'", game.dealer.Name)
+			}
+		})
+	}
+}
+
+// Test that total value of cards is respected when hit
+func TestCardValueRespectedOnHit(t *testing.T) {
+	player := &Player{Name: "Test Player"}
+	deck := NewShoe(1, 0)
+
+	// Stack the shoe with known cards, in the order they are dealt
+	deck.Stack(
+		cards.Card{Suit: cards.Diamond, Rank: cards.King}, // 10 points (King, will be dealt first)
+		cards.Card{Suit: cards.Club, Rank: 10},            // 10 points
+		cards.Card{Suit: cards.Spade, Rank: 7},            // 7 points
+		cards.Card{Suit: cards.Heart, Rank: 5},            // 5 points (will be dealt last)
+	)
+
+	// Test hitting with face card
+	initialScore := player.GetScore() // Should be 0
+	player.Hit(deck)                  // Should get King (10 points)
+	expectedScore := initialScore + 10
+	if player.GetScore() != expectedScore {
+		t.Errorf("Expected score %d after hitting King, got %d", expectedScore, player.GetScore())
+	}
+
+	// Test hitting with regular number
+	player.Hit(deck) // Should get 10
+	expectedScore += 10
+	if player.GetScore() != expectedScore {
+		t.Errorf("Expected score %d after hitting 10, got %d", expectedScore, player.GetScore())
+	}
+
+	// Test hitting with another number
+	player.Hit(deck) // Should get 7
+	expectedScore += 7
+	if player.GetScore() != expectedScore {
+		t.Errorf("Expected score %d after hitting 7, got %d", expectedScore, player.GetScore())
+	}
+}
+
+// Test Ace value adjustment separately
+func TestAceValueInHit(t *testing.T) {
+	player := &Player{Name: "Test Player"}
+	deck := NewShoe(1, 0)
+
+	// Test Ace as 11 when safe
+	deck.Stack(cards.Card{Suit: cards.Heart, Rank: cards.Ace})
+	player.Hit(deck) // Should get Ace (11 points)
+	if player.GetScore() != 11 {
+		t.Errorf("Expected score 11 after hitting Ace, got %d", player.GetScore())
+	}
+
+	// Test Ace adjustment when it would bust
+	player.SetHand([]cards.Card{{Suit: cards.Spade, Rank: 10}}) // Start with 10
+	player.UpdateScore()
+	deck.Stack(cards.Card{Suit: cards.Heart, Rank: cards.Ace})
+	player.Hit(deck) // Should get Ace (11 points, but will adjust to 1 if > 21)
+	if player.GetScore() != 21 {
+		t.Errorf("Expected score 21 (10 + 11) after hitting Ace, got %d", player.GetScore())
+	}
+
+	// Test Ace adjustment when adding to existing high score
+	player.SetHand([]cards.Card{{Suit: cards.Spade, Rank: 10}, {Suit: cards.Club, Rank: 5}}) // Start with 15
+	player.UpdateScore()
+	deck.Stack(cards.Card{Suit: cards.Heart, Rank: cards.Ace})
+	player.Hit(deck) // Should get Ace (1 point to avoid bust)
+	if player.GetScore() != 16 {
+		t.Errorf("Expected score 16 (15 + 1) after hitting Ace to avoid bust, got %d", player.GetScore())
+	}
+}
+
+// Test that Ace value adjusts correctly to prevent unnecessary busts
+func TestAceValueAdjustment(t *testing.T) {
+	player := &Player{Name: "Test Player"}
+
+	// Test Ace counting as 11 when safe
+	player.SetHand([]cards.Card{
+		{Suit: cards.Heart, Rank: 1}, // Ace
+		{Suit: cards.Spade, Rank: 5}, // 5
+	})
+	player.UpdateScore()
+
+	if player.GetScore() != 16 { // 11 + 5
+		t.Errorf("Expected score 16 (Ace as 11), got %d", player.GetScore())
+	}
+
+	// Test Ace counting as 1 when 11 would bust
+	player.SetHand([]cards.Card{
+		{Suit: cards.Heart, Rank: 1},  // Ace
+		{Suit: cards.Spade, Rank: 10}, // 10
+		{Suit: cards.Club, Rank: 8},   // 8
+	})
+	player.UpdateScore()
+
+	if player.GetScore() != 19 { // 1 + 10 + 8
+		t.Errorf("Expected score 19 (Ace as 1), got %d", player.GetScore())
+	}
+
+	// Test multiple Aces
+	player.SetHand([]cards.Card{
+		{Suit: cards.Heart, Rank: 1},   // Ace
+		{Suit: cards.Diamond, Rank: 1}, // Ace
+		{Suit: cards.Spade, Rank: 9},   // 9
+	})
+	player.UpdateScore()
+
+	if player.GetScore() != 21 { // 11 + 1 + 9
+		t.Errorf("Expected score 21 (one Ace as 11, one as 1), got %d", player.GetScore())
+	}
+}
+
+// Test that face cards are valued correctly
+func TestFaceCardValues(t *testing.T) {
+	player := &Player{Name: "Test Player"}
+
+	// Test Jack (11) = 10 points
+	player.SetHand([]cards.Card{{Suit: cards.Heart, Rank: 11}})
+	player.UpdateScore()
+	if player.GetScore() != 10 {
+		t.Errorf("Expected Jack to be worth 10 points, got %d", player.GetScore())
+	}
+
+	// Test Queen (12) = 10 points
+	player.SetHand([]cards.Card{{Suit: cards.Diamond, Rank: 12}})
+	player.UpdateScore()
+	if player.GetScore() != 10 {
+		t.Errorf("Expected Queen to be worth 10 points, got %d", player.GetScore())
+	}
+
+	// Test King (13) = 10 points
+	player.SetHand([]cards.Card{{Suit: cards.Club, Rank: 13}})
+	player.UpdateScore()
+	if player.GetScore() != 10 {
+		t.Errorf("Expected King to be worth 10 points, got %d", player.GetScore())
+	}
+
+	// Test all face cards together
+	player.SetHand([]cards.Card{
+		{Suit: cards.Heart, Rank: 11},   // Jack
+		{Suit: cards.Diamond, Rank: 12}, // Queen
+		{Suit: cards.Club, Rank: 13},    // King
+	})
+	player.UpdateScore()
+	if player.GetScore() != 30 {
+		t.Errorf("Expected J+Q+K to be worth 30 points, got %d", player.GetScore())
+	}
+}
+
+// Test that going over 21 is considered a bust/loss
+func TestBustOver21(t *testing.T) {
+	player := &Player{Name: "Test Player"}
+
+	// Create a hand that busts
+	player.SetHand([]cards.Card{
+		{Suit: cards.Heart, Rank: 10}, // 10
+		{Suit: cards.Spade, Rank: 5},  // 5
+		{Suit: cards.Club, Rank: 7},   // 7
+	})
+	player.UpdateScore()
+
+	score := player.GetScore()
+	if score <= 21 {
+		t.Errorf("Expected score to be over 21 (bust), got %d", score)
+	}
+
+	if score != 22 {
+		t.Errorf("Expected exact score of 22, got %d", score)
+	}
+}
+
+// Test that blackjack (21) is recognized correctly
+func TestBlackjackRecognition(t *testing.T) {
+	player := &Player{Name: "Test Player"}
+
+	// Test natural blackjack (Ace + 10-value card)
+	player.SetHand([]cards.Card{
+		{Suit: cards.Heart, Rank: 1},  // Ace (11)
+		{Suit: cards.Spade, Rank: 10}, // 10
+	})
+	player.UpdateScore()
+
+	if player.GetScore() != 21 {
+		t.Errorf("Expected blackjack score of 21, got %d", player.GetScore())
+	}
+
+	// Test blackjack with face card
+	player.SetHand([]cards.Card{
+		{Suit: cards.Diamond, Rank: 1}, // Ace (11)
+		{Suit: cards.Club, Rank: 13},   // King (10)
+	})
+	player.UpdateScore()
+
+	if player.GetScore() != 21 {
+		t.Errorf("Expected blackjack score of 21 with Ace+King, got %d", player.GetScore())
+	}
+}
+
+// Test flaws and edge cases
+func TestGameFlaws(t *testing.T) {
+	// Test all players bust scenario
+	t.Run("All players bust should skip dealer", func(t *testing.T) {
+		game := New(2, 1, DefaultRules)
+		game.phase = PhaseDealerTurn
+
+		// Make both players bust
+		game.players[0].SetHand([]cards.Card{{Suit: cards.Heart, Rank: 10}, {Suit: cards.Spade, Rank: 6}, {Suit: cards.Club, Rank: 6}}) // 22
+		game.players[0].UpdateScore()
+		game.players[1].SetHand([]cards.Card{{Suit: cards.Diamond, Rank: 10}, {Suit: cards.Heart, Rank: 5}, {Suit: cards.Spade, Rank: 7}}) // 22
+		game.players[1].UpdateScore()
+		game.dealer.SetHand([]cards.Card{{Suit: cards.Club, Rank: 10}, {Suit: cards.Club, Rank: 4}})
+		game.dealer.UpdateScore()
+		game.shoe.Stack(cards.Card{Suit: cards.Heart, Rank: 2})
+
+		game.PlayDealer()
+		if len(game.dealer.GetHand()) != 2 {
+			t.Errorf("Expected the dealer not to hit when every player busted, got %v", game.dealer.GetHand())
+		}
+	})
+
+	// Test shoe exhaustion during game
+	t.Run("Shoe exhaustion handling", func(t *testing.T) {
+		player := &Player{Name: "Test"}
+		shoe := NewShoe(1, 0)
+		shoe.Stack(cards.Card{Suit: cards.Heart, Rank: 5}) // Only one card
+
+		player.Hit(shoe) // Takes the only card
+
+		// Now the shoe is empty, the next hit shuffles it again
+		player.Hit(shoe)
+
+		hand := player.GetHand()
+		if len(hand) != 2 {
+			t.Errorf("Expected 2 cards in hand, got %d", len(hand))
+		}
+
+		// Last card should be dealt from the new shoe
+		if lastCard := hand[len(hand)-1]; lastCard.Rank < cards.Ace || shoe.Len() != 51 {
+			t.Errorf("Expected a card of the shuffled shoe, got %v", lastCard)
+		}
+	})
+
+	// Test nil hand handling
+	t.Run("Nil hand initialization", func(t *testing.T) {
+		player := &Player{Name: "Test"}
+		player.SetHand(nil)
+
+		// This should not panic
+		score := player.GetScore()
+		if score != 0 {
+			t.Errorf("Expected score 0 for empty hand, got %d", score)
+		}
+
+		player.UpdateScore() // Should not panic with nil hand
+	})
+}
+
+// Test that a bet can't exceed the bankroll
+func TestBetLimitedByBankroll(t *testing.T) {
+	player := &Player{Bankroll: 25, Bet: MinBet}
+
+	player.changeBet(10)
+	player.changeBet(10)
+
+	if player.Bet != 25 {
+		t.Errorf("Expected the bet to be limited to the bankroll of 25, got %d", player.Bet)
+	}
+}
+
+// Test the payouts of the outcomes of a hand
+func TestSettleBets(t *testing.T) {
+	tests := []struct {
+		name     string
+		player   []cards.Card
+		dealer   []cards.Card
+		outcome  Outcome
+		bankroll int
+	}{
+		{"Blackjack pays 3:2", []cards.Card{{Suit: cards.Heart, Rank: 1}, {Suit: cards.Spade, Rank: 13}}, []cards.Card{{Suit: cards.Club, Rank: 10}, {Suit: cards.Club, Rank: 9}}, BlackjackWin, 1015},
+		{"Higher score pays 1:1", []cards.Card{{Suit: cards.Heart, Rank: 10}, {Suit: cards.Spade, Rank: 9}}, []cards.Card{{Suit: cards.Club, Rank: 10}, {Suit: cards.Club, Rank: 8}}, Win, 1010},
+		{"Dealer bust pays 1:1", []cards.Card{{Suit: cards.Heart, Rank: 10}, {Suit: cards.Spade, Rank: 2}}, []cards.Card{{Suit: cards.Club, Rank: 10}, {Suit: cards.Club, Rank: 6}, {Suit: cards.Spade, Rank: 8}}, Win, 1010},
+		{"Tie is a push", []cards.Card{{Suit: cards.Heart, Rank: 10}, {Suit: cards.Spade, Rank: 8}}, []cards.Card{{Suit: cards.Club, Rank: 10}, {Suit: cards.Club, Rank: 8}}, Push, 1000},
+		{"Two blackjacks push", []cards.Card{{Suit: cards.Heart, Rank: 1}, {Suit: cards.Spade, Rank: 13}}, []cards.Card{{Suit: cards.Club, Rank: 1}, {Suit: cards.Club, Rank: 12}}, Push, 1000},
+		{"Dealer blackjack beats 21", []cards.Card{{Suit: cards.Heart, Rank: 7}, {Suit: cards.Spade, Rank: 7}, {Suit: cards.Club, Rank: 7}}, []cards.Card{{Suit: cards.Club, Rank: 1}, {Suit: cards.Club, Rank: 12}}, Loss, 990},
+		{"Lower score loses", []cards.Card{{Suit: cards.Heart, Rank: 10}, {Suit: cards.Spade, Rank: 7}}, []cards.Card{{Suit: cards.Club, Rank: 10}, {Suit: cards.Club, Rank: 8}}, Loss, 990},
+		{"Bust loses even if the dealer busts", []cards.Card{{Suit: cards.Heart, Rank: 10}, {Suit: cards.Spade, Rank: 7}, {Suit: cards.Club, Rank: 5}}, []cards.Card{{Suit: cards.Club, Rank: 10}, {Suit: cards.Club, Rank: 6}, {Suit: cards.Spade, Rank: 8}}, Loss, 990},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := New(1, 1, DefaultRules)
+			player := game.players[0]
+			player.SetHand(tt.player)
+			player.UpdateScore()
+			game.dealer.SetHand(tt.dealer)
+			game.dealer.UpdateScore()
+
+			game.settleBets()
+
+			if outcome := player.Hands[0].Outcome; outcome != tt.outcome {
+				t.Errorf("Expected outcome %d, got %d", tt.outcome, outcome)
+			}
+
+			if player.Bankroll != tt.bankroll {
+				t.Errorf("Expected a bankroll of %d, got %d", tt.bankroll, player.Bankroll)
+			}
+
+			if player.Wins+player.Losses+player.Pushes != 1 {
+				t.Errorf("Expected the hand to be counted once, got W%d L%d P%d", player.Wins, player.Losses, player.Pushes)
+			}
+		})
+	}
+}
+
+// Test that players without enough chips sit out and that the game ends when nobody can bet
+func TestPlayersWithoutChipsSitOut(t *testing.T) {
+	game := New(2, 3, DefaultRules)
+	game.phase = PhaseRoundEnd
+	game.players[0].Bankroll = 5
+
+	game.NextRound()
+
+	if game.phase != PhaseBet || game.currentPlayer != 1 || game.players[0].Bet != 0 {
+		t.Fatalf("Expected the first player to sit out, got phase '%s' and player %d", game.phase, game.currentPlayer)
+	}
+
+	// No natural blackjack is dealt, the second player plays after the deal
+	game.shoe.Stack(cards.Card{Suit: cards.Club, Rank: 6}, cards.Card{Suit: cards.Spade, Rank: 10}, cards.Card{Suit: cards.Diamond, Rank: 8}, cards.Card{Suit: cards.Heart, Rank: 9})
+	game.PlaceBet()
+	if len(game.players[0].GetHand()) != 0 || game.currentPlayer != 1 {
+		t.Errorf("Expected no cards for the player sitting out")
+	}
+
+	game.phase = PhaseRoundEnd
+	game.players[1].Bankroll = 0
+	game.NextRound()
+	if game.phase != PhaseGameOver {
+		t.Errorf("Expected the game to end when nobody can bet, got '%s'", game.phase)
+	}
+}
+
+// newActionGame creates a one player game on the player's turn with the given hands, the deck dealing the cards in the
+// given order.
+func newActionGame(player, dealer []cards.Card, deck ...cards.Card) *Game {
+	game := New(1, 1, DefaultRules)
+	game.phase = PhasePlayerTurn
+	game.players[0].SetHand(player)
+	game.players[0].UpdateScore()
+	game.dealer.SetHand(dealer)
+	game.dealer.UpdateScore()
+	game.shoe.Stack(deck...)
+	return game
+}
+
+// Test that doubling down doubles the bet and draws exactly one card
+func TestDoubleDown(t *testing.T) {
+	game := newActionGame([]cards.Card{{Suit: cards.Heart, Rank: 6}, {Suit: cards.Spade, Rank: 5}}, []cards.Card{{Suit: cards.Club, Rank: 10}, {Suit: cards.Club, Rank: 7}}, cards.Card{Suit: cards.Diamond, Rank: 10}, cards.Card{Suit: cards.Heart, Rank: 2})
+
+	if _, err := game.Take(ActionDouble); err != nil {
+		t.Fatal(err)
+	}
+
+	hand := game.players[0].Hands[0]
+	if hand.Bet != 2*MinBet || len(hand.Cards) != 3 || hand.Score != 21 {
+		t.Errorf("Expected one card on a doubled bet, got %d cards and a bet of %d", len(hand.Cards), hand.Bet)
+	}
+
+	if game.phase != PhaseDealerTurn {
+		t.Fatalf("Expected the hand to be done after doubling, got phase '%s'", game.phase)
+	}
+
+	game.PlayDealer()
+	if game.players[0].Bankroll != StartingBankroll+2*MinBet {
+		t.Errorf("Expected the doubled bet to be paid, got a bankroll of %d", game.players[0].Bankroll)
+	}
+
+	// A hit hand can't be doubled, nor a bet the bankroll can't cover
+	game = newActionGame([]cards.Card{{Suit: cards.Heart, Rank: 2}, {Suit: cards.Spade, Rank: 3}, {Suit: cards.Club, Rank: 4}}, []cards.Card{{Suit: cards.Club, Rank: 10}, {Suit: cards.Club, Rank: 7}})
+	if game.CanTake(ActionDouble) {
+		t.Error("Expected no double down after hitting")
+	}
+
+	game = newActionGame([]cards.Card{{Suit: cards.Heart, Rank: 6}, {Suit: cards.Spade, Rank: 5}}, []cards.Card{{Suit: cards.Club, Rank: 10}, {Suit: cards.Club, Rank: 7}})
+	game.players[0].Bankroll = 15
+	if game.CanTake(ActionDouble) {
+		t.Error("Expected no double down without the chips for it")
+	}
+}
+
+// Test that pairs are split into hands played one after the other, and re-split up to the maximum number of hands
+func TestSplitPairs(t *testing.T) {
+	game := newActionGame([]cards.Card{{Suit: cards.Heart, Rank: 8}, {Suit: cards.Spade, Rank: 8}}, []cards.Card{{Suit: cards.Club, Rank: 10}, {Suit: cards.Club, Rank: 7}},
+		cards.Card{Suit: cards.Club, Rank: 8}, cards.Card{Suit: cards.Diamond, Rank: 3}, cards.Card{Suit: cards.Diamond, Rank: 8}, cards.Card{Suit: cards.Heart, Rank: 9}, cards.Card{Suit: cards.Spade, Rank: 10}, cards.Card{Suit: cards.Heart, Rank: 10}, cards.Card{Suit: cards.Club, Rank: 2})
+
+	for i := 0; i < 3; i++ {
+		if _, err := game.Take(ActionSplit); err != nil {
+			t.Fatalf("Split %d: %v", i+1, err)
+		}
+	}
+
+	player := game.players[0]
+	if len(player.Hands) != maxHands || game.CanTake(ActionSplit) {
+		t.Fatalf("Expected %d hands at most, got %d", maxHands, len(player.Hands))
+	}
+
+	if player.CanAfford(MinBet*(StartingBankroll/MinBet-maxHands) + 1) {
+		t.Error("Expected each hand to have its own bet")
+	}
+
+	for i := 0; i < maxHands; i++ {
+		if player.Current != i {
+			t.Fatalf("Expected hand %d to be played, got hand %d", i+1, player.Current+1)
+		}
+		game.Take(ActionStand)
+	}
+
+	if game.phase != PhaseDealerTurn {
+		t.Errorf("Expected the dealer to play after the last hand, got phase '%s'", game.phase)
+	}
+
+	// Cards which aren't a pair can't be split
+	game = newActionGame([]cards.Card{{Suit: cards.Heart, Rank: 13}, {Suit: cards.Spade, Rank: 12}}, []cards.Card{{Suit: cards.Club, Rank: 10}, {Suit: cards.Club, Rank: 7}})
+	if game.CanTake(ActionSplit) {
+		t.Error("Expected a king and a queen not to be split")
+	}
+}
+
+// Test that split aces get one card each, and that 21 with a split ace isn't a blackjack
+func TestSplitAces(t *testing.T) {
+	game := newActionGame([]cards.Card{{Suit: cards.Heart, Rank: 1}, {Suit: cards.Spade, Rank: 1}}, []cards.Card{{Suit: cards.Club, Rank: 10}, {Suit: cards.Club, Rank: 9}}, cards.Card{Suit: cards.Club, Rank: 13}, cards.Card{Suit: cards.Diamond, Rank: 1})
+
+	if _, err := game.Take(ActionSplit); err != nil {
+		t.Fatal(err)
+	}
+
+	player := game.players[0]
+	if game.phase != PhaseDealerTurn {
+		t.Fatalf("Expected no action on split aces, got phase '%s'", game.phase)
+	}
+
+	if len(player.Hands[1].Cards) != 2 || !player.Hands[1].SplitAces {
+		t.Errorf("Expected the second ace to get one card, got %v", player.Hands[1].Cards)
+	}
+
+	game.PlayDealer()
+	if outcome := player.Hands[0].Outcome; outcome != Win || player.Hands[0].Net != MinBet {
+		t.Errorf("Expected 21 on a split ace to pay 1:1, got outcome %d", outcome)
+	}
+}
+
+// Test that a natural blackjack and a hand hit to 21 are done, and that the dealer doesn't draw against naturals only
+func TestTwentyOneEndsTheHand(t *testing.T) {
+	game := New(1, 1, DefaultRules)
+	// A natural for the player, 10-6 for the dealer
+	game.shoe.Stack(cards.Card{Suit: cards.Heart, Rank: 1}, cards.Card{Suit: cards.Spade, Rank: 13}, cards.Card{Suit: cards.Club, Rank: 10}, cards.Card{Suit: cards.Club, Rank: 6}, cards.Card{Suit: cards.Diamond, Rank: 5})
+
+	game.PlaceBet()
+	if game.phase != PhaseDealerTurn || game.CanTake(ActionHit) {
+		t.Fatalf("Expected a natural blackjack to be done on the deal, got phase '%s'", game.phase)
+	}
+
+	game.PlayDealer()
+	if len(game.dealer.GetHand()) != 2 || game.players[0].Bankroll != StartingBankroll+MinBet*3/2 {
+		t.Errorf("Expected the dealer not to draw against a natural, got %v", game.dealer.GetHand())
+	}
+
+	game = newActionGame([]cards.Card{{Suit: cards.Heart, Rank: 6}, {Suit: cards.Spade, Rank: 5}}, []cards.Card{{Suit: cards.Club, Rank: 10}, {Suit: cards.Club, Rank: 7}}, cards.Card{Suit: cards.Diamond, Rank: 10})
+	game.Take(ActionHit)
+	if game.phase != PhaseDealerTurn {
+		t.Errorf("Expected a hand hit to 21 to be done, got phase '%s'", game.phase)
+	}
+}
+
+// Test that insurance is offered when the dealer shows an ace, and pays 2:1 when the dealer has a blackjack
+func TestInsurance(t *testing.T) {
+	// 10-9 for the player, the dealer has a blackjack
+	game := New(1, 1, DefaultRules)
+	game.players[0].Bet = 20
+	game.shoe.Stack(cards.Card{Suit: cards.Heart, Rank: 10}, cards.Card{Suit: cards.Spade, Rank: 9}, cards.Card{Suit: cards.Club, Rank: 1}, cards.Card{Suit: cards.Club, Rank: 13})
+	game.deal()
+
+	if game.phase != PhaseInsurance || game.CanTake(ActionHit) {
+		t.Fatalf("Expected the insurance to be offered before the player's turn, got phase '%s'", game.phase)
+	}
+
+	if _, err := game.Take(ActionInsurance); err != nil {
+		t.Fatal(err)
+	}
+
+	// The dealer peeks at the blackjack, which ends the round
+	if game.phase != PhaseDealerTurn {
+		t.Fatalf("Expected the dealer to peek at the blackjack, got phase '%s'", game.phase)
+	}
+	game.PlayDealer()
+
+	// The hand loses 20 and the insurance of 10 wins 20
+	player := game.players[0]
+	if player.InsuranceNet != 20 || player.Bankroll != StartingBankroll {
+		t.Errorf("Expected the insurance to cover the hand, got a bankroll of %d", player.Bankroll)
+	}
+
+	// The dealer stands on a soft 18
+	game = New(1, 1, DefaultRules)
+	game.shoe.Stack(cards.Card{Suit: cards.Heart, Rank: 10}, cards.Card{Suit: cards.Spade, Rank: 9}, cards.Card{Suit: cards.Club, Rank: 1}, cards.Card{Suit: cards.Club, Rank: 7})
+	game.deal()
+	game.Take(ActionInsurance)
+	game.Take(ActionStand)
+	game.PlayDealer()
+	if player := game.players[0]; player.InsuranceNet != -MinBet/2 || player.Bankroll != StartingBankroll+MinBet-MinBet/2 {
+		t.Errorf("Expected the insurance to be lost, got a bankroll of %d", player.Bankroll)
+	}
+
+	game = New(1, 1, DefaultRules)
+	game.shoe.Stack(cards.Card{Suit: cards.Heart, Rank: 10}, cards.Card{Suit: cards.Spade, Rank: 9}, cards.Card{Suit: cards.Club, Rank: 10}, cards.Card{Suit: cards.Club, Rank: 9})
+	game.deal()
+	if game.phase != PhasePlayerTurn || game.CanTake(ActionInsurance) {
+		t.Errorf("Expected no insurance when the dealer doesn't show an ace, got phase '%s'", game.phase)
+	}
+}
+
+// Test that a surrendered hand loses half the bet, unless the dealer has a blackjack
+func TestLateSurrender(t *testing.T) {
+	game := newActionGame([]cards.Card{{Suit: cards.Heart, Rank: 10}, {Suit: cards.Spade, Rank: 6}}, []cards.Card{{Suit: cards.Club, Rank: 10}, {Suit: cards.Club, Rank: 9}})
+
+	if _, err := game.Take(ActionSurrender); err != nil {
+		t.Fatal(err)
+	}
+
+	game.PlayDealer()
+	if player := game.players[0]; player.Hands[0].Outcome != Surrender || player.Bankroll != StartingBankroll-MinBet/2 {
+		t.Errorf("Expected half the bet to be lost, got a bankroll of %d", player.Bankroll)
+	}
+
+	// Without a hole card, the dealer's blackjack shows up after the players' turns
+	game = newActionGame([]cards.Card{{Suit: cards.Heart, Rank: 10}, {Suit: cards.Spade, Rank: 6}}, []cards.Card{{Suit: cards.Club, Rank: 1}, {Suit: cards.Club, Rank: 13}})
+	game.rules.NoHoleCard = true
+	game.Take(ActionSurrender)
+	game.PlayDealer()
+	if player := game.players[0]; player.Bankroll != StartingBankroll-MinBet {
+		t.Errorf("Expected the dealer's blackjack to take the whole bet, got a bankroll of %d", player.Bankroll)
+	}
+
+	game = newActionGame([]cards.Card{{Suit: cards.Heart, Rank: 10}, {Suit: cards.Spade, Rank: 2}, {Suit: cards.Club, Rank: 3}}, []cards.Card{{Suit: cards.Club, Rank: 10}, {Suit: cards.Club, Rank: 9}})
+	if game.CanTake(ActionSurrender) {
+		t.Error("Expected no surrender after hitting")
+	}
+}
+
+// Test that the shoe holds every deck and is only reshuffled once the cut card is reached
+func TestShoeCutCard(t *testing.T) {
+	shoe := NewShoe(6, 0.75)
+	if !shoe.CutCardReached() {
+		t.Error("Expected an empty shoe to be shuffled")
+	}
+
+	shoe.Shuffle()
+	if shoe.Len() != 6*52 || shoe.cutCard != 78 {
+		t.Fatalf("Expected 312 cards and the cut card 78 cards from the end, got %d cards and %d", shoe.Len(), shoe.cutCard)
+	}
+
+	game := New(1, 3, Presets[1])
+	game.shoe = shoe
+	game.phase = PhaseRoundEnd
+	game.NextRound()
+	if game.shoe.Len() != 6*52 {
+		t.Fatalf("Expected the shoe not to be reshuffled before the cut card, got %d cards", game.shoe.Len())
+	}
+
+	for game.shoe.Len() > game.shoe.cutCard {
+		game.shoe.Deal()
+	}
+	game.phase = PhaseRoundEnd
+	game.NextRound()
+	if game.shoe.Len() != 6*52 {
+		t.Errorf("Expected the shoe to be reshuffled after the cut card, got %d cards", game.shoe.Len())
+	}
+
+	// A shoe running out in the middle of a round is shuffled again
+	shoe = NewShoe(1, 0)
+	if card := shoe.Deal(); card.Rank < 1 || shoe.Len() != 51 {
+		t.Errorf("Expected an empty shoe to be shuffled to deal, got %v", card)
+	}
+}
+
+// Test that the dealer hits a soft 17 only if the rules say so
+func TestDealerSoft17(t *testing.T) {
+	for _, hitSoft17 := range []bool{false, true} {
+		rules := DefaultRules
+		rules.HitSoft17 = hitSoft17
+
+		game := New(1, 1, rules)
+		game.phase = PhaseDealerTurn
+		game.players[0].SetHand([]cards.Card{{Suit: cards.Heart, Rank: 10}, {Suit: cards.Spade, Rank: 8}})
+		game.players[0].UpdateScore()
+		game.dealer.SetHand([]cards.Card{{Suit: cards.Club, Rank: 1}, {Suit: cards.Club, Rank: 6}})
+		game.dealer.UpdateScore()
+		game.shoe.Stack(cards.Card{Suit: cards.Diamond, Rank: 2})
+
+		game.PlayDealer()
+
+		if score := game.dealer.GetScore(); hitSoft17 && score != 19 || !hitSoft17 && score != 17 {
+			t.Errorf("Hit soft 17 %v: unexpected dealer score %d", hitSoft17, score)
+		}
+	}
+}
+
+// Test the table rules on the payout of a blackjack, doubling down split hands, surrender and the hole card
+func TestTableRules(t *testing.T) {
+	rules := DefaultRules
+	rules.BlackjackPayout = Ratio{6, 5}
+	game := New(1, 1, rules)
+	game.players[0].SetHand([]cards.Card{{Suit: cards.Heart, Rank: 1}, {Suit: cards.Spade, Rank: 13}})
+	game.players[0].UpdateScore()
+	game.dealer.SetHand([]cards.Card{{Suit: cards.Club, Rank: 10}, {Suit: cards.Club, Rank: 9}})
+	game.dealer.UpdateScore()
+	game.settleBets()
+	if net := game.players[0].Hands[0].Net; net != 12 {
+		t.Errorf("Expected a blackjack to pay 12 at 6:5, got %d", net)
+	}
+
+	game = newActionGame([]cards.Card{{Suit: cards.Heart, Rank: 8}, {Suit: cards.Spade, Rank: 8}}, []cards.Card{{Suit: cards.Club, Rank: 10}, {Suit: cards.Club, Rank: 7}}, cards.Card{Suit: cards.Club, Rank: 3}, cards.Card{Suit: cards.Diamond, Rank: 2})
+	game.rules.DoubleAfterSplit = false
+	game.rules.Surrender = false
+	if game.CanTake(ActionSurrender) {
+		t.Error("Expected no surrender when the rules forbid it")
+	}
+	game.Take(ActionSplit)
+	if game.CanTake(ActionDouble) {
+		t.Error("Expected no double down after a split when the rules forbid it")
+	}
+
+	// Without a hole card, the dealer gets the second card on the dealer's turn
+	game = New(1, 1, Presets[len(Presets)-1])
+	game.shoe.Stack(cards.Card{Suit: cards.Heart, Rank: 10}, cards.Card{Suit: cards.Spade, Rank: 9}, cards.Card{Suit: cards.Club, Rank: 10}, cards.Card{Suit: cards.Club, Rank: 1})
+	game.deal()
+	if len(game.dealer.GetHand()) != 1 || game.phase != PhasePlayerTurn {
+		t.Fatalf("Expected the dealer to get one card, got %d", len(game.dealer.GetHand()))
+	}
+	for _, event := range game.flush() {
+		if event.FaceDown {
+			t.Errorf("Expected no hole card, got %v face down", event.Card)
+		}
+	}
+
+	game.Take(ActionStand)
+	game.PlayDealer()
+	if !game.dealer.HasBlackjack() || game.players[0].Bankroll != StartingBankroll-MinBet {
+		t.Errorf("Expected the dealer to draw a blackjack, got %v", game.dealer.GetHand())
+	}
+}
+
+// Test that a round goes through the phases in order, and that the actions of the wrong phase are errors
+func TestPhases(t *testing.T) {
+	game := New(1, 1, DefaultRules)
+	// 10-7 for the player, 10-6 for the dealer who draws a 5
+	game.shoe.Stack(cards.Card{Suit: cards.Heart, Rank: 10}, cards.Card{Suit: cards.Spade, Rank: 7}, cards.Card{Suit: cards.Club, Rank: 10}, cards.Card{Suit: cards.Club, Rank: 6}, cards.Card{Suit: cards.Diamond, Rank: 5})
+
+	if _, err := game.Take(ActionHit); !errors.Is(err, ErrIllegal) {
+		t.Errorf("Expected hitting during the bets to be illegal, got %v", err)
+	}
+
+	if _, err := game.PlaceBet(); err != nil || game.phase != PhasePlayerTurn {
+		t.Fatalf("Expected the player's turn after the bets, got phase %s (%v)", game.phase, err)
+	}
+
+	if err := game.ChangeBet(10); !errors.Is(err, ErrIllegal) {
+		t.Errorf("Expected no bet change once the cards are dealt, got %v", err)
+	}
+
+	if _, err := game.PlayDealer(); !errors.Is(err, ErrIllegal) {
+		t.Errorf("Expected the dealer to wait for the player, got %v", err)
+	}
+
+	game.Take(ActionStand)
+	if game.phase != PhaseDealerTurn || game.HoleCardHidden() {
+		t.Fatalf("Expected the hole card to be turned on the dealer's turn, got phase %s", game.phase)
+	}
+
+	if _, err := game.NextRound(); !errors.Is(err, ErrIllegal) {
+		t.Errorf("Expected the round not to be over, got %v", err)
+	}
+
+	game.PlayDealer()
+	if game.phase != PhaseRoundEnd {
+		t.Fatalf("Expected the round to end after the dealer, got phase %s", game.phase)
+	}
+
+	game.NextRound()
+	if game.phase != PhaseGameOver {
+		t.Errorf("Expected the game to be over after the last round, got phase %s", game.phase)
+	}
+
+	for phase := PhaseBet; phase <= PhaseGameOver; phase++ {
+		if parsed, err := ParsePhase(phase.String()); err != nil || parsed != phase {
+			t.Errorf("Expected %s to be parsed back, got %s (%v)", phase, parsed, err)
+		}
+	}
+	if _, err := ParsePhase("deal"); err == nil {
+		t.Error("Expected an error for an unknown phase")
+	}
+}
+
+// Test the events of the deal, the actions and the dealer's turn
+func TestEvents(t *testing.T) {
+	game := New(1, 1, DefaultRules)
+	game.shoe.Stack(cards.Card{Suit: cards.Heart, Rank: 10}, cards.Card{Suit: cards.Spade, Rank: 7}, cards.Card{Suit: cards.Club, Rank: 10}, cards.Card{Suit: cards.Club, Rank: 6}, cards.Card{Suit: cards.Diamond, Rank: 5}, cards.Card{Suit: cards.Heart, Rank: 3})
+
+	events, _ := game.PlaceBet()
+	kinds := []EventKind{BetPlaced, Dealt, Dealt, Dealt, Dealt}
+	if len(events) != len(kinds) {
+		t.Fatalf("Expected %d events for the bet and the deal, got %v", len(kinds), events)
+	}
+	for i, kind := range kinds {
+		if events[i].Kind != kind {
+			t.Errorf("Expected event %d to be %d, got %d", i, kind, events[i].Kind)
+		}
+	}
+	if hole := events[4]; hole.Player != Dealer || !hole.FaceDown || hole.Card != (cards.Card{Suit: cards.Club, Rank: 6}) {
+		t.Errorf("Expected the dealer's second card face down, got %+v", hole)
+	}
+
+	events, _ = game.Take(ActionHit)
+	if len(events) != 3 || events[1].Card != (cards.Card{Suit: cards.Diamond, Rank: 5}) || events[2].Kind != HoleCardTurned {
+		t.Errorf("Expected the hit card then the hole card turned on 22, got %+v", events)
+	}
+
+	events, _ = game.PlayDealer()
+	last := events[len(events)-1]
+	if len(events) != 2 || events[0].Kind != HandSettled || events[0].Outcome != Loss || last.Kind != RoundOver {
+		t.Errorf("Expected the busted hand to be settled without the dealer hitting, got %+v", events)
+	}
+}
@@ -0,0 +1,244 @@
+package engine
+
+import (
+	"slices"
+
+	"github.com/Kaamkiya/gg/internal/app/cards"
+)
+
+// -------------------- ENUM: Outcome --------------------
+
+// Outcome of a player's hand against the dealer's hand.
+type Outcome int
+
+const (
+	// Loss loses the bet: the player busted or has less than the dealer.
+	Loss Outcome = iota
+	// Push gives the bet back: the player and the dealer tie.
+	Push
+	// Win pays the bet 1:1.
+	Win
+	// BlackjackWin pays the bet at the blackjack payout of the table for a natural blackjack the dealer doesn't have.
+	BlackjackWin
+	// Surrender loses half the bet: the player gave up the hand.
+	Surrender
+)
+
+// -------------------- Betting --------------------
+
+const (
+	StartingBankroll = 1000 // Chips each player starts the game with.
+	MinBet           = 10   // Smallest bet, a player with fewer chips sits out.
+	maxHands         = 4    // Hands a player can have by splitting and re-splitting pairs.
+)
+
+// -------------------- STRUCT: Hand --------------------
+
+// One hand of cards with its own bet: a player plays several hands after splitting a pair.
+type Hand struct {
+	Cards       []cards.Card
+	Score       int     // Current score based on the cards.
+	Bet         int     // Chips bet on the hand, doubled when doubling down.
+	Doubled     bool    // Doubled down, the hand got exactly one more card.
+	Surrendered bool    // Given up for half the bet.
+	Split       bool    // Comes from a split, so 21 with two cards isn't a natural blackjack.
+	SplitAces   bool    // Comes from splitting aces: one card for each ace and no further action.
+	Done        bool    // The player has finished playing the hand.
+	Outcome     Outcome // Outcome of the hand once settled.
+	Net         int     // Chips won on the hand once settled, negative when lost.
+}
+
+// Draws one card from the deck into the hand.
+func (hand *Hand) Hit(deck CardSource) {
+	hand.add(deck.Deal())
+}
+
+// add puts the card in the hand.
+func (hand *Hand) add(card cards.Card) {
+	hand.Cards = append(hand.Cards, card)
+	hand.UpdateScore()
+}
+
+// HasBlackjack tells if the hand is a natural blackjack: 21 with the first two cards, not after a split.
+func (hand *Hand) HasBlackjack() bool {
+	return len(hand.Cards) == 2 && hand.Score == 21 && !hand.Split
+}
+
+// Calculates and updates the score of the hand.
+// Aces are counted as 11 unless it causes a bust, then as 1; face cards (J, Q, K) are 10.
+func (hand *Hand) UpdateScore() {
+	sum := 0
+	aces := 0
+	for _, c := range hand.Cards {
+		switch c.Rank {
+		case cards.Jack, cards.Queen, cards.King:
+			sum += 10
+		case cards.Ace:
+			sum += 11
+			aces++
+		default:
+			sum += int(c.Rank)
+		}
+	}
+	// adjust for aces if bust
+	for sum > 21 && aces > 0 {
+		sum -= 10
+		aces--
+	}
+	hand.Score = sum
+}
+
+// Soft tells if an ace of the hand counts as 11.
+func (hand *Hand) Soft() bool {
+	sum := 0
+	ace := false
+	for _, c := range hand.Cards {
+		sum += int(min(c.Rank, cards.Ten))
+		ace = ace || c.Rank == cards.Ace
+	}
+	return ace && sum+10 <= 21
+}
+
+// IsPair tells if the hand is two cards of the same rank, which can be split.
+func (hand *Hand) IsPair() bool {
+	return len(hand.Cards) == 2 && hand.Cards[0].Rank == hand.Cards[1].Rank
+}
+
+// against returns the outcome of the hand against the dealer's score.
+func (hand *Hand) against(dealerScore int, dealerBlackjack bool) Outcome {
+	switch {
+	case hand.Surrendered && dealerBlackjack:
+		// Late surrender: the dealer's blackjack takes the whole bet
+		return Loss
+	case hand.Surrendered:
+		return Surrender
+	case hand.Score > 21:
+		return Loss
+	case hand.HasBlackjack() && dealerBlackjack:
+		return Push
+	case hand.HasBlackjack():
+		return BlackjackWin
+	case dealerBlackjack:
+		return Loss
+	case dealerScore > 21 || hand.Score > dealerScore:
+		return Win
+	case hand.Score == dealerScore:
+		return Push
+	}
+	return Loss
+}
+
+// -------------------- STRUCT: Player --------------------
+
+// Representation of a blackjack participant (dealer or player).
+type Player struct {
+	Name         string
+	Hands        []*Hand // Hands of the round, one unless the player split pairs.
+	Current      int     // Index of the hand being played.
+	Bankroll     int     // Chips the player has, the bets of the round included.
+	Bet          int     // Chips bet on the hand of each round, 0 when sitting out.
+	Insurance    int     // Insurance bet against a dealer blackjack, 0 when not insured.
+	InsuranceNet int     // Chips won on the insurance once settled, negative when lost.
+	Wins         int     // Number of hands won.
+	Losses       int     // Number of hands lost.
+	Pushes       int     // Number of hands tied with the dealer.
+}
+
+// Hand returns the hand being played, creating a first hand with the player's bet if there is none.
+func (player *Player) Hand() *Hand {
+	if len(player.Hands) == 0 {
+		player.Hands = []*Hand{{Bet: player.Bet}}
+		player.Current = 0
+	}
+	return player.Hands[player.Current]
+}
+
+// Draws one card from the deck into the player's current hand.
+func (player *Player) Hit(deck CardSource) {
+	player.Hand().Hit(deck)
+}
+
+// Returns the score of the player's current hand.
+func (player *Player) GetScore() int {
+	return player.Hand().Score
+}
+
+// Returns the cards of the player's current hand.
+func (player *Player) GetHand() []cards.Card {
+	return player.Hand().Cards
+}
+
+// SetHand replaces the player's hands with a single hand of the provided slice of cards.
+func (player *Player) SetHand(hand []cards.Card) {
+	player.Hands = []*Hand{{Cards: hand, Bet: player.Bet}}
+	player.Current = 0
+}
+
+// HasBlackjack tells if the current hand is a natural blackjack.
+func (player *Player) HasBlackjack() bool {
+	return player.Hand().HasBlackjack()
+}
+
+// Calculates and updates the score of the player's current hand.
+func (player *Player) UpdateScore() {
+	player.Hand().UpdateScore()
+}
+
+// clearHands removes the hands and the insurance of the last round.
+func (player *Player) clearHands() {
+	player.Hands = nil
+	player.Current = 0
+	player.Insurance = 0
+	player.InsuranceNet = 0
+}
+
+// changeBet adds delta chips to the bet, keeping it between the minimum bet and the bankroll.
+func (player *Player) changeBet(delta int) {
+	player.Bet = max(MinBet, min(player.Bet+delta, player.Bankroll))
+}
+
+// CanAfford tells if the player has the chips for another bet on top of the bets of the round.
+func (player *Player) CanAfford(chips int) bool {
+	committed := player.Insurance
+	for _, hand := range player.Hands {
+		committed += hand.Bet
+	}
+	return player.Bankroll-committed >= chips
+}
+
+// split moves the second card of the current hand to a new hand with the same bet played right after it, and
+// returns the new hand. Both hands are left with one card.
+func (player *Player) split() *Hand {
+	hand := player.Hand()
+	second := &Hand{Cards: []cards.Card{hand.Cards[1]}, Bet: hand.Bet, Split: true}
+	hand.Cards = hand.Cards[:1]
+	hand.Split = true
+	hand.UpdateScore()
+	second.UpdateScore()
+	player.Hands = slices.Insert(player.Hands, player.Current+1, second)
+	return second
+}
+
+// settle pays or takes the bet of the hand according to its outcome, a natural blackjack being paid the payout of the
+// table, and counts it in the tally.
+func (player *Player) settle(hand *Hand, outcome Outcome, blackjackPayout Ratio) {
+	hand.Outcome = outcome
+	switch outcome {
+	case BlackjackWin:
+		hand.Net = blackjackPayout.pay(hand.Bet)
+		player.Wins++
+	case Win:
+		hand.Net = hand.Bet
+		player.Wins++
+	case Push:
+		hand.Net = 0
+		player.Pushes++
+	case Surrender:
+		hand.Net = -hand.Bet / 2
+		player.Losses++
+	default:
+		hand.Net = -hand.Bet
+		player.Losses++
+	}
+	player.Bankroll += hand.Net
+}
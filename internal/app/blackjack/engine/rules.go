@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// -------------------- STRUCT: Ratio --------------------
+
+// Ratio of a payout, e.g. 3:2 pays 3 chips for every 2 chips bet.
+type Ratio struct {
+	Win int // Chips paid for every Bet chips.
+	Bet int
+}
+
+// String returns the ratio as it is written on the tables, e.g. "3:2".
+func (ratio Ratio) String() string {
+	return fmt.Sprintf("%d:%d", ratio.Win, ratio.Bet)
+}
+
+// pay returns the chips paid for the bet, rounded down.
+func (ratio Ratio) pay(bet int) int {
+	return bet * ratio.Win / ratio.Bet
+}
+
+// -------------------- STRUCT: Rules --------------------
+
+// Rules of a blackjack table.
+type Rules struct {
+	Name             string  // Name of the table.
+	Decks            int     // Number of 52-card decks in the shoe.
+	Penetration      float64 // Share of the shoe dealt before the cut card, 0 to reshuffle every round.
+	HitSoft17        bool    // The dealer hits a soft 17 instead of standing on it.
+	BlackjackPayout  Ratio   // Payout of a natural blackjack.
+	DoubleAfterSplit bool    // The hands of a split can be doubled down.
+	Surrender        bool    // Hands can be given up for half the bet (late surrender).
+	// The dealer draws the second card on the dealer's turn instead of peeking at a face down hole card for a
+	// blackjack, so the players lose their doubled and split bets to a dealer blackjack.
+	NoHoleCard bool
+}
+
+// DefaultRules deals a single deck shuffled every round.
+var DefaultRules = Rules{
+	Name:             "Classic",
+	Decks:            1,
+	BlackjackPayout:  Ratio{3, 2},
+	DoubleAfterSplit: true,
+	Surrender:        true,
+}
+
+// Presets are the rules of the tables to choose from.
+var Presets = []Rules{
+	DefaultRules,
+	{
+		Name:             "Vegas Strip",
+		Decks:            6,
+		Penetration:      0.75,
+		BlackjackPayout:  Ratio{3, 2},
+		DoubleAfterSplit: true,
+		Surrender:        true,
+	},
+	{
+		Name:             "Downtown Vegas",
+		Decks:            2,
+		Penetration:      0.65,
+		HitSoft17:        true,
+		BlackjackPayout:  Ratio{3, 2},
+		DoubleAfterSplit: true,
+	},
+	{
+		Name:            "Single deck 6:5",
+		Decks:           1,
+		Penetration:     0.5,
+		HitSoft17:       true,
+		BlackjackPayout: Ratio{6, 5},
+	},
+	{
+		Name:             "European no-hole-card",
+		Decks:            6,
+		Penetration:      0.75,
+		BlackjackPayout:  Ratio{3, 2},
+		DoubleAfterSplit: true,
+		NoHoleCard:       true,
+	},
+}
+
+// Description summarizes the rules, e.g. "6 decks, dealer stands on soft 17, blackjack pays 3:2".
+func (rules Rules) Description() string {
+	parts := []string{fmt.Sprintf("%d decks", rules.Decks)}
+	if rules.Decks == 1 {
+		parts[0] = "1 deck"
+	}
+
+	if rules.Penetration == 0 {
+		parts = append(parts, "shuffled every round")
+	} else {
+		parts = append(parts, fmt.Sprintf("cut card at %.0f%%", rules.Penetration*100))
+	}
+
+	if rules.HitSoft17 {
+		parts = append(parts, "dealer hits soft 17")
+	} else {
+		parts = append(parts, "dealer stands on soft 17")
+	}
+
+	parts = append(parts, "blackjack pays "+rules.BlackjackPayout.String())
+	if rules.DoubleAfterSplit {
+		parts = append(parts, "double after split")
+	}
+	if rules.Surrender {
+		parts = append(parts, "late surrender")
+	}
+	if rules.NoHoleCard {
+		parts = append(parts, "no hole card")
+	}
+
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,39 @@
+package engine
+
+import "testing"
+
+// Test the descriptions of the presets
+func TestRulesDescription(t *testing.T) {
+	tests := []struct {
+		rules       Rules
+		description string
+	}{
+		{DefaultRules, "1 deck, shuffled every round, dealer stands on soft 17, blackjack pays 3:2, double after split, late surrender"},
+		{Presets[len(Presets)-1], "6 decks, cut card at 75%, dealer stands on soft 17, blackjack pays 3:2, double after split, no hole card"},
+	}
+
+	for _, tt := range tests {
+		if description := tt.rules.Description(); description != tt.description {
+			t.Errorf("%s: expected %q, got %q", tt.rules.Name, tt.description, description)
+		}
+	}
+}
+
+// Test that the presets are valid tables
+func TestPresets(t *testing.T) {
+	names := map[string]bool{}
+	for _, rules := range Presets {
+		if names[rules.Name] {
+			t.Errorf("Duplicate preset %q", rules.Name)
+		}
+		names[rules.Name] = true
+
+		if rules.Decks < 1 || rules.Penetration < 0 || rules.Penetration >= 1 || rules.BlackjackPayout.Bet == 0 {
+			t.Errorf("Invalid preset %q: %+v", rules.Name, rules)
+		}
+	}
+
+	if !names["Vegas Strip"] || !names["European no-hole-card"] {
+		t.Errorf("Expected the Vegas Strip and European no-hole-card presets, got %v", names)
+	}
+}
@@ -0,0 +1,51 @@
+package engine
+
+import "github.com/Kaamkiya/gg/internal/app/cards"
+
+// Value returns the points of the card: face cards are 10 and aces 11.
+func Value(card cards.Card) int {
+	if card.Rank == cards.Ace {
+		return 11
+	}
+	return int(min(card.Rank, cards.Ten))
+}
+
+// -------------------- STRUCT: Shoe --------------------
+
+// Holds the decks of a table. A cut card is placed in the shoe when it is shuffled, and the shoe is only reshuffled
+// between rounds once the cut card is reached.
+type Shoe struct {
+	*cards.Deck         // Cards left in the shoe.
+	penetration float64 // Share of the cards dealt before the cut card.
+	cutCard     int     // Number of cards left in the shoe when the cut card is reached.
+}
+
+// NewShoe creates an empty shoe of the given number of decks, to be shuffled before dealing.
+func NewShoe(decks int, penetration float64) *Shoe {
+	return &Shoe{Deck: cards.NewDeck(max(decks, 1), 0), penetration: penetration}
+}
+
+// Shuffle puts the cards of all the decks back in the shoe, randomizes their order and places the cut card.
+func (shoe *Shoe) Shuffle() {
+	shoe.Deck.Shuffle()
+	shoe.cutCard = shoe.Len() - int(float64(shoe.Len())*shoe.penetration)
+}
+
+// Deal pops a card off the top of the shoe, shuffling the shoe again if it runs out.
+func (shoe *Shoe) Deal() cards.Card {
+	if shoe.Len() == 0 {
+		shoe.Shuffle()
+	}
+	card, _ := shoe.Deck.Deal()
+	return card
+}
+
+// CutCardReached tells if the cut card came out of the shoe: the shoe is reshuffled before the next round.
+func (shoe *Shoe) CutCardReached() bool {
+	return shoe.Len() <= shoe.cutCard
+}
+
+// CardSource deals the cards drawn by the hands: a Shoe, which never runs out.
+type CardSource interface {
+	Deal() cards.Card
+}
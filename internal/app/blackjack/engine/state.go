@@ -0,0 +1,232 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Kaamkiya/gg/internal/app/cards"
+)
+
+// State encodes the table: the round, the number of rounds, the phase, the current player, the hand of the dealer and
+// the players. The hole card of the dealer is left out while it is face down. Each player is written as their
+// bankroll, bet, insurance and its winnings, current hand, wins, losses, pushes and hands separated by colons. The
+// hands are separated by bars, each hand being its cards, bet, flags, outcome and winnings separated by semicolons:
+//
+//	1 3 player_turn 0 A♠ 1000:20:10:0:1:0:0:0:8♥,3♦;40;dpx;0;0|8♣,K♦;20;p;0;0 1000:10:0:0:0:0:0:0:10♣,2♠;10;-;0;0
+func (game *Game) State() string {
+	dealerHand := game.dealer.GetHand()
+	if game.HoleCardHidden() && len(dealerHand) > 0 {
+		dealerHand = dealerHand[:1]
+	}
+
+	fields := []string{
+		strconv.Itoa(game.currentRound),
+		strconv.Itoa(game.numberOfRounds),
+		game.phase.String(),
+		strconv.Itoa(game.currentPlayer),
+		formatCards(dealerHand),
+	}
+	for _, player := range game.players {
+		fields = append(fields, formatPlayer(player))
+	}
+
+	return strings.Join(fields, " ")
+}
+
+// Load reads a table encoded by Game.State, to be played with the rules.
+func Load(state string, rules Rules) (*Game, error) {
+	fields := strings.Fields(state)
+	if len(fields) < 6 {
+		return nil, fmt.Errorf("invalid state %q", state)
+	}
+
+	round, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid round in state %q", state)
+	}
+
+	rounds, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid number of rounds in state %q", state)
+	}
+
+	phase, err := ParsePhase(fields[2])
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := strconv.Atoi(fields[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid current player in state %q", state)
+	}
+
+	dealerHand, err := parseCards(fields[4])
+	if err != nil {
+		return nil, err
+	}
+
+	game := New(len(fields)-5, rounds, rules)
+	game.currentRound = round
+	game.phase = phase
+	game.currentPlayer = current
+
+	game.dealer.SetHand(dealerHand)
+	game.dealer.UpdateScore()
+	for i, player := range game.players {
+		if err := parsePlayer(fields[i+5], player); err != nil {
+			return nil, err
+		}
+	}
+
+	return game, nil
+}
+
+// formatPlayer writes the chips, the tally and the hands of the player separated by colons.
+func formatPlayer(player *Player) string {
+	hands := make([]string, 0, len(player.Hands))
+	for _, hand := range player.Hands {
+		hands = append(hands, formatHand(hand))
+	}
+	if len(hands) == 0 {
+		hands = append(hands, "-")
+	}
+
+	return fmt.Sprintf("%d:%d:%d:%d:%d:%d:%d:%d:%s", player.Bankroll, player.Bet, player.Insurance,
+		player.InsuranceNet, player.Current, player.Wins, player.Losses, player.Pushes, strings.Join(hands, "|"))
+}
+
+// parsePlayer reads a player written by formatPlayer into the player.
+func parsePlayer(text string, player *Player) error {
+	fields := strings.Split(text, ":")
+	if len(fields) != 9 {
+		return fmt.Errorf("invalid player %q", text)
+	}
+
+	numbers := make([]int, len(fields)-1)
+	for i, field := range fields[:len(fields)-1] {
+		var err error
+		if numbers[i], err = strconv.Atoi(field); err != nil {
+			return fmt.Errorf("invalid player %q", text)
+		}
+	}
+	player.Bankroll, player.Bet, player.Insurance, player.InsuranceNet = numbers[0], numbers[1], numbers[2], numbers[3]
+	player.Wins, player.Losses, player.Pushes = numbers[5], numbers[6], numbers[7]
+
+	player.Hands = nil
+	if fields[8] != "-" {
+		for _, field := range strings.Split(fields[8], "|") {
+			hand, err := parseHand(field)
+			if err != nil {
+				return err
+			}
+			player.Hands = append(player.Hands, hand)
+		}
+	}
+
+	player.Current = numbers[4]
+	if player.Current < 0 || player.Current >= max(len(player.Hands), 1) {
+		return fmt.Errorf("invalid current hand in player %q", text)
+	}
+
+	return nil
+}
+
+// handFlagLetters are the letters of the flags of a hand in a state, in the order of handFlagFields.
+const handFlagLetters = "drpax"
+
+// handFlagFields returns the flags of the hand: doubled, surrendered, split, split aces and done.
+func handFlagFields(hand *Hand) []*bool {
+	return []*bool{&hand.Doubled, &hand.Surrendered, &hand.Split, &hand.SplitAces, &hand.Done}
+}
+
+// formatHand writes the cards, the bet, the flags, the outcome and the winnings of the hand separated by semicolons.
+// The flags are letters: d for doubled, r for surrendered, p for split, a for split aces and x for done, or "-" for
+// none.
+func formatHand(hand *Hand) string {
+	flags := ""
+	for i, flag := range handFlagFields(hand) {
+		if *flag {
+			flags += string(handFlagLetters[i])
+		}
+	}
+	if flags == "" {
+		flags = "-"
+	}
+
+	return fmt.Sprintf("%s;%d;%s;%d;%d", formatCards(hand.Cards), hand.Bet, flags, hand.Outcome, hand.Net)
+}
+
+// parseHand reads a hand written by formatHand.
+func parseHand(text string) (*Hand, error) {
+	fields := strings.Split(text, ";")
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid hand %q", text)
+	}
+
+	cards, err := parseCards(fields[0])
+	if err != nil {
+		return nil, err
+	}
+
+	hand := &Hand{Cards: cards}
+	hand.UpdateScore()
+
+	if hand.Bet, err = strconv.Atoi(fields[1]); err != nil {
+		return nil, fmt.Errorf("invalid bet in hand %q", text)
+	}
+
+	outcome, err := strconv.Atoi(fields[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid outcome in hand %q", text)
+	}
+	hand.Outcome = Outcome(outcome)
+
+	if hand.Net, err = strconv.Atoi(fields[4]); err != nil {
+		return nil, fmt.Errorf("invalid winnings in hand %q", text)
+	}
+
+	if fields[2] != "-" {
+		for _, letter := range fields[2] {
+			i := strings.IndexRune(handFlagLetters, letter)
+			if i < 0 {
+				return nil, fmt.Errorf("invalid flags in hand %q", text)
+			}
+			*handFlagFields(hand)[i] = true
+		}
+	}
+
+	return hand, nil
+}
+
+// formatCards writes the cards separated by commas, or "-" for no cards.
+func formatCards(hand []cards.Card) string {
+	if len(hand) == 0 {
+		return "-"
+	}
+
+	cards := make([]string, 0, len(hand))
+	for _, card := range hand {
+		cards = append(cards, card.String())
+	}
+
+	return strings.Join(cards, ",")
+}
+
+// parseCards reads cards written by formatCards.
+func parseCards(text string) ([]cards.Card, error) {
+	if text == "-" {
+		return nil, nil
+	}
+
+	var hand []cards.Card
+	for _, field := range strings.Split(text, ",") {
+		card, err := cards.Parse(field)
+		if err != nil {
+			return nil, err
+		}
+		hand = append(hand, card)
+	}
+
+	return hand, nil
+}
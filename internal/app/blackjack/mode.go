@@ -0,0 +1,70 @@
+package blackjack
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/huh"
+)
+
+// -------------------- ENUM: gameMode --------------------
+
+// gameMode of a local game.
+type gameMode int
+
+const (
+	modePlay     gameMode = iota // Bet against the dealer.
+	modeTrainer                  // Check the decisions against the basic strategy.
+	modeCounting                 // Keep the Hi-Lo count of a shoe and answer quizzes on it.
+)
+
+const (
+	countingRounds = 20   // Rounds of the counting drill.
+	quizEvery      = 2    // Rounds between the quizzes of the counting drill.
+	drillCutCard   = 0.75 // Penetration of the drill shoe for the tables shuffling every round.
+)
+
+// gameModes are the modes to choose from.
+var gameModes = []gameMode{modePlay, modeTrainer, modeCounting}
+
+func (m gameMode) String() string {
+	switch m {
+	case modeTrainer:
+		return "basic strategy trainer"
+	case modeCounting:
+		return "counting drill"
+	default:
+		return "play"
+	}
+}
+
+func (m gameMode) description() string {
+	switch m {
+	case modeTrainer:
+		return "check every decision against the basic strategy"
+	case modeCounting:
+		return fmt.Sprintf("keep the Hi-Lo count over %d rounds, with a quiz every %d rounds", countingRounds, quizEvery)
+	default:
+		return "bet against the dealer"
+	}
+}
+
+// selectMode asks for the mode of the game.
+func selectMode() gameMode {
+	choice := modePlay
+
+	options := make([]huh.Option[gameMode], 0, len(gameModes))
+	for _, m := range gameModes {
+		options = append(options, huh.NewOption(fmt.Sprintf("%s - %s", m, m.description()), m))
+	}
+
+	err := huh.NewSelect[gameMode]().
+		Title("choose a mode:").
+		Options(options...).
+		Value(&choice).
+		Run()
+	if err != nil {
+		panic(err)
+	}
+
+	return choice
+}
@@ -0,0 +1,144 @@
+package blackjack
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/Kaamkiya/gg/internal/app/blackjack/engine"
+)
+
+// -------------------- STRUCT: NetGame --------------------
+
+// NetGame is a Blackjack game for two players playing over the network. The host deals the cards and plays the
+// dealer as soon as the players are done, and the players see the table from the states it sends.
+// The states are the tables encoded by engine.Game.State, the hole card of the dealer being left out until the
+// dealer's turn.
+type NetGame struct {
+	model model
+}
+
+// NewNetGame creates a game of 3 rounds for 2 players, starting with the bets of the first round.
+func NewNetGame() *NetGame {
+	game := &NetGame{model: initialModel().(model)}
+	// The hint and count keys aren't moves of the network protocol
+	game.model.strategy, game.model.count = nil, nil
+	game.Reset()
+	return game
+}
+
+// Name returns the name of the game for the network protocol.
+func (game *NetGame) Name() string {
+	return "blackjack"
+}
+
+// Seats returns the number of players.
+func (game *NetGame) Seats() int {
+	return len(game.model.game.Players())
+}
+
+// Over tells if all the rounds were played.
+func (game *NetGame) Over() bool {
+	return game.model.game.Phase() == engine.PhaseGameOver
+}
+
+// Reset starts a new game with a freshly shuffled deck.
+func (game *NetGame) Reset() {
+	game.model.game = engine.New(2, 3, engine.DefaultRules)
+	game.model.game.Shuffle()
+}
+
+// Play plays the move of the player of the seat: raise, lower or bet to place their bet, insurance or decline while
+// the dealer shows an ace, an action on their turn, or next to open the bets of the next round once the round is
+// over.
+func (game *NetGame) Play(seat int, move string) error {
+	g := game.model.game
+
+	switch move {
+	case "raise", "lower", "bet":
+		if g.Phase() != engine.PhaseBet || g.CurrentPlayer() != seat {
+			return errors.New("it is not your turn to bet")
+		}
+
+		switch move {
+		case "raise":
+			g.ChangeBet(betStep)
+		case "lower":
+			g.ChangeBet(-betStep)
+		default:
+			g.PlaceBet()
+		}
+	case "next":
+		if _, err := g.NextRound(); err != nil {
+			return err
+		}
+	default:
+		action := engine.Action(move)
+		if !slices.Contains(engine.Actions, action) {
+			return fmt.Errorf("unknown move %q, expected raise, lower, bet, hit, stand, double, split, surrender, insurance, decline or next", move)
+		}
+
+		phase := g.Phase()
+		if phase != engine.PhaseInsurance && phase != engine.PhasePlayerTurn || g.CurrentPlayer() != seat {
+			return errors.New("it is not your turn")
+		}
+
+		if _, err := g.Take(action); err != nil {
+			return err
+		}
+	}
+
+	// The dealer plays as soon as the players are done, or right after the deal when peeking at a blackjack or when
+	// every hand is a natural
+	if g.Phase() == engine.PhaseDealerTurn {
+		g.PlayDealer()
+	}
+
+	return nil
+}
+
+// State encodes the table for the players.
+func (game *NetGame) State() string {
+	return game.model.game.State()
+}
+
+// Load replaces the table with the one of the state.
+func (game *NetGame) Load(state string) error {
+	g, err := engine.Load(state, engine.DefaultRules)
+	if err != nil {
+		return err
+	}
+
+	game.model.game = g
+	return nil
+}
+
+// View renders the table like the local game, the prompts telling which keys to press.
+func (game *NetGame) View(seat int) string {
+	return game.model.View()
+}
+
+// Key converts the keys of the local game to moves.
+func (game *NetGame) Key(key string) (string, bool) {
+	if game.model.game.Phase() == engine.PhaseBet {
+		switch key {
+		case "up", "k", "+":
+			return "raise", true
+		case "down", "j", "-":
+			return "lower", true
+		case "enter", " ":
+			return "bet", true
+		}
+		return "", false
+	}
+
+	if action, ok := keyAction(key); ok {
+		return string(action), true
+	}
+
+	if key == "enter" || key == " " {
+		return "next", true
+	}
+
+	return "", false
+}
@@ -0,0 +1,204 @@
+package blackjack
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/app/blackjack/engine"
+	"github.com/Kaamkiya/gg/internal/app/cards"
+)
+
+// stack puts the cards on top of the shoe of the game, to be dealt in the given order.
+func stack(game *NetGame, texts ...string) {
+	var stacked []cards.Card
+	for _, text := range texts {
+		card, err := cards.Parse(text)
+		if err != nil {
+			panic(err)
+		}
+		stacked = append(stacked, card)
+	}
+	game.model.game.Shoe().Stack(stacked...)
+}
+
+// Test that the state hides the second card of the dealer and is loaded back
+func TestNetGameStateIsLoaded(t *testing.T) {
+	host := NewNetGame()
+	stack(host, "A♥", "7♦", "10♣", "2♠", "K♠", "5♥")
+	host.Play(0, "raise")
+	host.Play(0, "bet")
+	host.Play(1, "bet")
+
+	state := host.State()
+	if state != "1 3 player_turn 0 K♠ 1000:20:0:0:0:0:0:0:A♥,7♦;20;-;0;0 1000:10:0:0:0:0:0:0:10♣,2♠;10;-;0;0" {
+		t.Fatalf("Unexpected state %q", state)
+	}
+
+	client := NewNetGame()
+	if err := client.Load(state); err != nil {
+		t.Fatal(err)
+	}
+
+	if client.State() != state {
+		t.Errorf("Expected %q after loading, got %q", state, client.State())
+	}
+
+	if client.model.game.Players()[0].GetScore() != 18 {
+		t.Errorf("Expected the score of the loaded hand to be 18, got %d", client.model.game.Players()[0].GetScore())
+	}
+
+	// The split hands of a player are loaded with their bets and flags
+	split := "1 3 player_turn 0 A♠ 1000:20:10:0:1:0:0:0:8♥,3♦;40;dpx;0;0|8♣,K♦;20;p;0;0 1000:10:0:0:0:0:0:0:10♣,2♠;10;-;0;0"
+	if err := client.Load(split); err != nil {
+		t.Fatal(err)
+	}
+
+	player := client.model.game.Players()[0]
+	if len(player.Hands) != 2 || player.Current != 1 || !player.Hands[0].Doubled || player.Hands[0].Bet != 40 || player.Insurance != 10 {
+		t.Errorf("Expected the split hands to be loaded, got %d hands", len(player.Hands))
+	}
+
+	if client.State() != split {
+		t.Errorf("Expected %q after loading, got %q", split, client.State())
+	}
+
+	for _, invalid := range []string{
+		"",
+		"1 3 player_turn",
+		"1 3 player_turn 0 K♠ 1000:10:0:0:0:0:0:0:Z♥;10;-;0;0",
+		"1 3 player_turn 0 K♠ 1000:10:0:0:0:0:0:0:14♠;10;-;0;0",
+		"1 3 player_turn 0 K♠ 1000:A♥",
+		"1 3 player_turn 0 K♠ 1000:10:0:0:2:0:0:0:A♥;10;-;0;0",
+		"1 3 player_turn 0 K♠ 1000:10:0:0:0:0:0:0:A♥;10;z;0;0",
+	} {
+		if err := client.Load(invalid); err == nil {
+			t.Errorf("Expected an error for state %q", invalid)
+		}
+	}
+}
+
+// Test that the players play in turn and that the dealer plays once they are done
+func TestNetGamePlay(t *testing.T) {
+	game := NewNetGame()
+	// 10-7 and 9-8 for the players, 10-6 for the dealer who draws a 5
+	stack(game, "10♠", "7♣", "9♥", "8♦", "10♠", "6♣", "5♥")
+
+	if err := game.Play(1, "bet"); err == nil {
+		t.Error("Expected the second player to wait for the first one to bet")
+	}
+
+	game.Play(0, "raise")
+	game.Play(0, "bet")
+	game.Play(1, "bet")
+
+	if game.model.game.Phase() != engine.PhasePlayerTurn || game.model.game.Players()[0].Bet != 20 {
+		t.Fatalf("Expected the cards to be dealt after the bets, got phase %s", game.model.game.Phase())
+	}
+
+	if err := game.Play(1, "stand"); err == nil {
+		t.Error("Expected the second player to wait for the first one")
+	}
+
+	if err := game.Play(0, "next"); err == nil {
+		t.Error("Expected the round not to be over")
+	}
+
+	if err := game.Play(0, "stand"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := game.Play(1, "stand"); err != nil {
+		t.Fatal(err)
+	}
+
+	if game.model.game.Phase() != engine.PhaseRoundEnd || game.model.game.Dealer().GetScore() < 17 {
+		t.Fatalf("Expected the dealer to play, got phase %s and score %d", game.model.game.Phase(), game.model.game.Dealer().GetScore())
+	}
+
+	// The whole hand of the dealer is shown once the players are done
+	if dealer := strings.Fields(game.State())[4]; len(strings.Split(dealer, ",")) < 2 {
+		t.Errorf("Expected the hand of the dealer to be shown, got %q", dealer)
+	}
+
+	for round := 1; round < 3; round++ {
+		if err := game.Play(1, "next"); err != nil {
+			t.Fatal(err)
+		}
+		game.Play(0, "bet")
+		game.Play(1, "bet")
+		// Decline the insurance if the dealer shows an ace
+		game.Play(0, "decline")
+		game.Play(1, "decline")
+		game.Play(0, "stand")
+		game.Play(1, "stand")
+	}
+
+	if err := game.Play(0, "next"); err != nil || !game.Over() {
+		t.Errorf("Expected the game to be over after 3 rounds (%v)", err)
+	}
+}
+
+// Test that the players are offered insurance when the dealer shows an ace, and that the dealer peeks for a blackjack
+func TestNetGameInsurance(t *testing.T) {
+	game := NewNetGame()
+	// 10-9 and 10-8 for the players, the dealer has a blackjack
+	stack(game, "10♣", "9♥", "10♥", "8♦", "A♠", "K♣")
+	game.Play(0, "bet")
+	game.Play(1, "bet")
+
+	if game.model.game.Phase() != engine.PhaseInsurance {
+		t.Fatalf("Expected the insurance to be offered, got phase %s", game.model.game.Phase())
+	}
+
+	if move, ok := game.Key("n"); !ok || move != "decline" {
+		t.Errorf("Expected 'n' to decline the insurance, got %q", move)
+	}
+
+	if err := game.Play(0, "insurance"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := game.Play(1, "decline"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The dealer's blackjack ends the round before the players' turns
+	g := game.model.game
+	players := g.Players()
+	if g.Phase() != engine.PhaseRoundEnd || players[0].Bankroll != engine.StartingBankroll || players[1].Bankroll != engine.StartingBankroll-engine.MinBet {
+		t.Errorf("Expected the insured player to break even, got phase %s and bankrolls %d and %d", g.Phase(), players[0].Bankroll, players[1].Bankroll)
+	}
+}
+
+// Test that the players double down, split and surrender over the network
+func TestNetGameActions(t *testing.T) {
+	game := NewNetGame()
+	// 8-8 and 10-6 for the players, 10-8 for the dealer, then the cards of the split hands and the double down
+	stack(game, "8♥", "8♣", "10♣", "6♠", "10♠", "8♥", "3♠", "2♥", "9♦")
+	game.Play(0, "bet")
+	game.Play(1, "bet")
+
+	if move, ok := game.Key("p"); !ok || move != "split" {
+		t.Fatalf("Expected 'p' to split, got %q", move)
+	}
+
+	for _, move := range []string{"split", "double", "stand"} {
+		if err := game.Play(0, move); err != nil {
+			t.Fatalf("%s: %v", move, err)
+		}
+	}
+
+	if err := game.Play(1, "insurance"); err == nil {
+		t.Error("Expected no insurance without an ace for the dealer")
+	}
+
+	if err := game.Play(1, "surrender"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Hands: 8-3-9 doubled (20 wins 20), 8-2 (10 loses 10), surrendered 16 (loses 5)
+	g, players := game.model.game, game.model.game.Players()
+	if g.Phase() != engine.PhaseRoundEnd || players[0].Bankroll != 1010 || players[1].Bankroll != 995 {
+		t.Errorf("Expected the hands to be settled, got phase %s and bankrolls %d and %d", g.Phase(), players[0].Bankroll, players[1].Bankroll)
+	}
+}
@@ -0,0 +1,145 @@
+package blackjack
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/app/blackjack/engine"
+	"github.com/Kaamkiya/gg/internal/app/blackjack/strategy"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+)
+
+// -------------------- ENUM: seat --------------------
+
+// seat of the table, played from the keyboard or by the computer.
+type seat int
+
+const (
+	seatHuman      seat = iota // Played from the keyboard.
+	seatBasic                  // Bot playing the basic strategy of the table.
+	seatHitUnder17             // Bot hitting until 17 like the dealer.
+)
+
+// botDelay is the time a bot waits before each of its moves, for the players to follow the game.
+const botDelay = 600 * time.Millisecond
+
+// seatKinds are the kinds of seats to choose from.
+var seatKinds = []seat{seatHuman, seatBasic, seatHitUnder17}
+
+func (s seat) String() string {
+	switch s {
+	case seatBasic:
+		return "basic strategy bot"
+	case seatHitUnder17:
+		return "hit under 17 bot"
+	default:
+		return "human"
+	}
+}
+
+func (s seat) description() string {
+	switch s {
+	case seatBasic:
+		return "plays the basic strategy of the table"
+	case seatHitUnder17:
+		return "hits until 17 like the dealer"
+	default:
+		return "played from the keyboard"
+	}
+}
+
+// policy returns the policy of the bot of the seat at a table with the rules, nil for a human seat.
+func (s seat) policy(rules engine.Rules) strategy.Policy {
+	switch s {
+	case seatBasic:
+		return strategy.Generate(rules)
+	case seatHitUnder17:
+		return strategy.HitUnder17{}
+	}
+	return nil
+}
+
+// selectSeats asks for the number of seats of the table, then for who plays each of them. The first seat is human and
+// the others basic strategy bots unless changed.
+func selectSeats() []seat {
+	count := 2
+
+	counts := make([]huh.Option[int], 0, engine.MaxPlayers)
+	for i := 1; i <= engine.MaxPlayers; i++ {
+		counts = append(counts, huh.NewOption(fmt.Sprint(i), i))
+	}
+
+	err := huh.NewSelect[int]().
+		Title("choose the number of seats:").
+		Options(counts...).
+		Value(&count).
+		Run()
+	if err != nil {
+		panic(err)
+	}
+
+	options := make([]huh.Option[seat], 0, len(seatKinds))
+	for _, s := range seatKinds {
+		options = append(options, huh.NewOption(fmt.Sprintf("%s - %s", s, s.description()), s))
+	}
+
+	seats := make([]seat, count)
+	fields := make([]huh.Field, count)
+	for i := range seats {
+		if i > 0 {
+			seats[i] = seatBasic
+		}
+		fields[i] = huh.NewSelect[seat]().
+			Title(fmt.Sprintf("choose who plays seat %d:", i+1)).
+			Options(options...).
+			Value(&seats[i])
+	}
+
+	if err := huh.NewForm(huh.NewGroup(fields...)).Run(); err != nil {
+		panic(err)
+	}
+
+	return seats
+}
+
+// botMsg tells the bot of the current seat to play its move.
+type botMsg struct{}
+
+// bot returns the policy of the bot whose move the game waits for, nil if the game waits for a human or the dealer.
+func (m model) bot() strategy.Policy {
+	switch m.game.Phase() {
+	case engine.PhaseBet, engine.PhaseInsurance, engine.PhasePlayerTurn:
+		if m.game.CurrentPlayer() < len(m.bots) {
+			return m.bots[m.game.CurrentPlayer()]
+		}
+	}
+	return nil
+}
+
+// botTurn schedules the move of the bot the game waits for, after a delay.
+func (m model) botTurn() tea.Cmd {
+	if m.bot() == nil {
+		return nil
+	}
+	return tea.Tick(botDelay, func(time.Time) tea.Msg {
+		return botMsg{}
+	})
+}
+
+// playBot plays the move of the bot the game waits for: it places the bet it was given, or takes the action of its
+// policy.
+func (m model) playBot() {
+	bot := m.bot()
+	if bot == nil {
+		return
+	}
+
+	var events []engine.Event
+	if m.game.Phase() == engine.PhaseBet {
+		events, _ = m.game.PlaceBet()
+	} else {
+		events, _ = m.game.Take(bot.Action(m.game))
+	}
+	m.observe(events)
+}
@@ -0,0 +1,96 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/Kaamkiya/gg/internal/app/blackjack/engine"
+)
+
+// z is the quantile of the normal distribution for 95% confidence intervals.
+const z = 1.96
+
+// Result sums up the rounds from the point of view of the house. HouseEdge is the share of the initial bet the house
+// wins on average, EdgeLow and EdgeHigh bound its 95% confidence interval, and Variance is the variance of the
+// winnings of a round in initial bets. The outcomes count the hands, the split hands included, and the rates are
+// shares of the hands, except for the dealer busts and the splits which are shares of the rounds.
+type Result struct {
+	Rules       string  `json:"rules"`
+	Strategy    string  `json:"strategy"`
+	Rounds      int     `json:"rounds"`
+	Hands       int     `json:"hands"`
+	HouseEdge   float64 `json:"house_edge"`
+	EdgeLow     float64 `json:"edge_low"`
+	EdgeHigh    float64 `json:"edge_high"`
+	Variance    float64 `json:"variance"`
+	Blackjacks  int     `json:"blackjacks"`
+	Wins        int     `json:"wins"`
+	Pushes      int     `json:"pushes"`
+	Losses      int     `json:"losses"`
+	Surrenders  int     `json:"surrenders"`
+	PlayerBusts float64 `json:"player_busts"`
+	DealerBusts float64 `json:"dealer_busts"`
+	Doubles     float64 `json:"doubles"`
+	Splits      float64 `json:"splits"`
+}
+
+// newResult computes the result of the rounds of the tally.
+func newResult(rules, strategy string, t tally) Result {
+	result := Result{
+		Rules:      rules,
+		Strategy:   strategy,
+		Rounds:     t.rounds,
+		Hands:      t.hands,
+		Blackjacks: t.outcomes[engine.BlackjackWin],
+		Wins:       t.outcomes[engine.Win],
+		Pushes:     t.outcomes[engine.Push],
+		Losses:     t.outcomes[engine.Loss],
+		Surrenders: t.outcomes[engine.Surrender],
+	}
+
+	rounds, hands := float64(t.rounds), float64(max(t.hands, 1))
+	mean := t.net / rounds
+	result.Variance = t.squares/rounds - mean*mean
+	margin := z * math.Sqrt(result.Variance/rounds)
+
+	result.HouseEdge = -mean
+	result.EdgeLow = result.HouseEdge - margin
+	result.EdgeHigh = result.HouseEdge + margin
+	result.PlayerBusts = float64(t.busts) / hands
+	result.DealerBusts = float64(t.dealerBusts) / rounds
+	result.Doubles = float64(t.doubles) / hands
+	result.Splits = float64(t.splits) / rounds
+
+	return result
+}
+
+// Write writes the result in the format: text or json.
+func (r Result) Write(w io.Writer, format string) error {
+	if format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	}
+
+	percent := func(count int) float64 {
+		return 100 * float64(count) / float64(max(r.Hands, 1))
+	}
+
+	_, err := fmt.Fprintf(w, `%s: %s strategy, %d rounds (%d hands)
+house edge: %.2f%% (95%% CI %.2f%% - %.2f%%)
+variance: %.3f (standard deviation %.3f)
+blackjacks: %.1f%%, wins: %.1f%%, pushes: %.1f%%, losses: %.1f%%, surrenders: %.1f%%
+player busts: %.1f%% of hands, dealer busts: %.1f%% of rounds
+doubles: %.1f%% of hands, splits: %.1f%% of rounds
+`,
+		r.Rules, r.Strategy, r.Rounds, r.Hands,
+		100*r.HouseEdge, 100*r.EdgeLow, 100*r.EdgeHigh,
+		r.Variance, math.Sqrt(r.Variance),
+		percent(r.Blackjacks), percent(r.Wins), percent(r.Pushes), percent(r.Losses), percent(r.Surrenders),
+		100*r.PlayerBusts, 100*r.DealerBusts,
+		100*r.Doubles, 100*r.Splits)
+
+	return err
+}
@@ -0,0 +1,240 @@
+// Package sim plays rounds of blackjack by a policy on parallel workers, without any user interface, to estimate the
+// house edge of the rules of a table and check the engine against the published values.
+package sim
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/Kaamkiya/gg/internal/app/blackjack/engine"
+	"github.com/Kaamkiya/gg/internal/app/blackjack/strategy"
+)
+
+// chunk is the number of rounds a worker plays at once, with a shoe of its own.
+const chunk = 10000
+
+// Strategies are the names of the policies the rounds can be played by.
+var Strategies = []string{"basic", "hit-under-17"}
+
+// FindRules returns the preset of the name: the first word of its name in lowercase, e.g. vegas for Vegas Strip.
+func FindRules(name string) (engine.Rules, error) {
+	for _, rules := range engine.Presets {
+		if rulesKey(rules) == name {
+			return rules, nil
+		}
+	}
+
+	return engine.Rules{}, fmt.Errorf("unknown rules %q, expected one of %s", name, strings.Join(rulesKeys(), ", "))
+}
+
+func rulesKey(rules engine.Rules) string {
+	return strings.ToLower(strings.Fields(rules.Name)[0])
+}
+
+func rulesKeys() []string {
+	keys := []string{}
+	for _, rules := range engine.Presets {
+		keys = append(keys, rulesKey(rules))
+	}
+
+	return keys
+}
+
+// NewPolicy returns the policy of the strategy at a table with the rules: basic for the basic strategy, or
+// hit-under-17 to play like the dealer.
+func NewPolicy(name string, rules engine.Rules) (strategy.Policy, error) {
+	switch name {
+	case "basic":
+		return strategy.Generate(rules), nil
+	case "hit-under-17":
+		return strategy.HitUnder17{}, nil
+	}
+
+	return nil, fmt.Errorf("unknown strategy %q, expected one of %s", name, strings.Join(Strategies, ", "))
+}
+
+// tally sums up the rounds played by a worker.
+type tally struct {
+	rounds      int
+	hands       int     // Hands played, the split hands included.
+	net         float64 // Winnings of the rounds, in initial bets.
+	squares     float64 // Sum of the squares of the winnings of the rounds.
+	outcomes    [engine.Surrender + 1]int
+	busts       int // Hands busted.
+	dealerBusts int // Rounds the dealer busted.
+	doubles     int // Hands doubled down.
+	splits      int // Rounds the pairs were split.
+}
+
+// record counts the round the game just settled, the player having won net initial bets.
+func (t *tally) record(game *engine.Game, net float64) {
+	t.rounds++
+	t.net += net
+	t.squares += net * net
+
+	player := game.Players()[0]
+	for _, hand := range player.Hands {
+		t.hands++
+		t.outcomes[hand.Outcome]++
+		if hand.Score > 21 {
+			t.busts++
+		}
+		if hand.Doubled {
+			t.doubles++
+		}
+	}
+	if len(player.Hands) > 1 {
+		t.splits++
+	}
+	if game.Dealer().GetScore() > 21 {
+		t.dealerBusts++
+	}
+}
+
+func (t *tally) add(other tally) {
+	t.rounds += other.rounds
+	t.hands += other.hands
+	t.net += other.net
+	t.squares += other.squares
+	for outcome, hands := range other.outcomes {
+		t.outcomes[outcome] += hands
+	}
+	t.busts += other.busts
+	t.dealerBusts += other.dealerBusts
+	t.doubles += other.doubles
+	t.splits += other.splits
+}
+
+// Simulate plays the rounds on parallel workers, a single player betting the minimum against the dealer by the
+// strategy. The player never runs out of chips.
+func Simulate(rules engine.Rules, strategyName string, rounds, workers int) (Result, error) {
+	policy, err := NewPolicy(strategyName, rules)
+	if err != nil {
+		return Result{}, err
+	}
+
+	chunks := make(chan int)
+	tallies := make([]tally, max(workers, 1))
+	errs := make([]error, len(tallies))
+
+	var wg sync.WaitGroup
+	for i := range tallies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for size := range chunks {
+				if errs[i] != nil {
+					continue
+				}
+				errs[i] = play(rules, policy, size, &tallies[i])
+			}
+		}()
+	}
+
+	for left := rounds; left > 0; left -= chunk {
+		chunks <- min(left, chunk)
+	}
+	close(chunks)
+	wg.Wait()
+
+	total := tally{}
+	for i, t := range tallies {
+		if errs[i] != nil {
+			return Result{}, errs[i]
+		}
+		total.add(t)
+	}
+
+	return newResult(rules.Name, strategyName, total), nil
+}
+
+// play plays the rounds with a new shoe and counts them in the tally.
+func play(rules engine.Rules, policy strategy.Policy, rounds int, t *tally) error {
+	game := engine.New(1, rounds, rules)
+	game.Shuffle()
+	player := game.Players()[0]
+
+	for game.Phase() != engine.PhaseGameOver {
+		bankroll := player.Bankroll
+		if _, err := game.PlaceBet(); err != nil {
+			return err
+		}
+
+		for game.Phase() == engine.PhaseInsurance || game.Phase() == engine.PhasePlayerTurn {
+			if _, err := game.Take(policy.Action(game)); err != nil {
+				return err
+			}
+		}
+
+		if _, err := game.PlayDealer(); err != nil {
+			return err
+		}
+
+		t.record(game, float64(player.Bankroll-bankroll)/engine.MinBet)
+		player.Bankroll = engine.StartingBankroll
+		if _, err := game.NextRound(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Run runs the simulator command: gg blackjack sim --rules <rules> --hands <n> --strategy <strategy>.
+func Run(args []string) error {
+	flags := flag.NewFlagSet("sim", flag.ContinueOnError)
+	rulesName := flags.String("rules", "vegas", "rules of the table: "+strings.Join(rulesKeys(), ", "))
+	hands := flags.Int("hands", 100000, "number of rounds, each starting with one hand")
+	strategyName := flags.String("strategy", "basic", "strategy of the player: "+strings.Join(Strategies, ", "))
+	workers := flags.Int("workers", runtime.GOMAXPROCS(0), "number of rounds played in parallel")
+	format := flags.String("format", "text", "output format: text or json")
+	output := flags.String("o", "", "file to write the results to instead of the standard output")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: gg blackjack sim [flags]")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	rules, err := FindRules(*rulesName)
+	if err != nil {
+		return err
+	}
+
+	if *hands <= 0 {
+		return fmt.Errorf("expected a positive number of hands, got %d", *hands)
+	}
+
+	if !slices.Contains([]string{"text", "json"}, *format) {
+		return fmt.Errorf("unknown format %q, expected text or json", *format)
+	}
+
+	result, err := Simulate(rules, *strategyName, *hands, *workers)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return result.Write(os.Stdout, *format)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+
+	if err := result.Write(file, *format); err != nil {
+		file.Close()
+		return err
+	}
+
+	// The data may only reach the disk on close, which must not fail silently
+	return file.Close()
+}
@@ -0,0 +1,128 @@
+package sim
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestFindRules(t *testing.T) {
+	valid := map[string]string{
+		"classic":  "Classic",
+		"vegas":    "Vegas Strip",
+		"downtown": "Downtown Vegas",
+		"single":   "Single deck 6:5",
+		"european": "European no-hole-card",
+	}
+
+	for name, expected := range valid {
+		rules, err := FindRules(name)
+		if err != nil || rules.Name != expected {
+			t.Errorf("%s: expected %s, got %s (%v)", name, expected, rules.Name, err)
+		}
+	}
+
+	for _, name := range []string{"", "Vegas", "strip", "macau"} {
+		if _, err := FindRules(name); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// The house edges published for the rules of the presets, which are within about 0.1% of each other depending on
+// the source. The estimate can be 4 standard errors away from them, plus that 0.1%, which fails once in about 15000
+// runs by chance.
+func TestSimulateMatchesThePublishedHouseEdges(t *testing.T) {
+	if testing.Short() {
+		t.Skip("simulates hundreds of thousands of rounds")
+	}
+
+	tests := []struct {
+		rules     string
+		strategy  string
+		houseEdge float64
+	}{
+		// 6 decks, dealer stands on soft 17, double after split, late surrender
+		{"vegas", "basic", 0.0030},
+		// 1 deck, dealer hits soft 17, blackjack pays 6:5: 1.4% more than at 3:2
+		{"single", "basic", 0.0150},
+		// Playing like the dealer loses when both bust
+		{"vegas", "hit-under-17", 0.0550},
+	}
+
+	for _, tt := range tests {
+		rules, _ := FindRules(tt.rules)
+		result, err := Simulate(rules, tt.strategy, 200000, runtime.GOMAXPROCS(0))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		standardError := math.Sqrt(result.Variance / float64(result.Rounds))
+		margin := 4*standardError + 0.001
+		if math.Abs(result.HouseEdge-tt.houseEdge) > margin {
+			t.Errorf("%s, %s: expected a house edge of %.2f%%, got %.2f%% ± %.2f%%", tt.rules, tt.strategy,
+				100*tt.houseEdge, 100*result.HouseEdge, 100*margin)
+		}
+
+		if result.Rounds != 200000 || result.Hands < result.Rounds {
+			t.Errorf("%s, %s: expected 200000 rounds, got %+v", tt.rules, tt.strategy, result)
+		}
+	}
+}
+
+func TestSimulateCountsTheOutcomes(t *testing.T) {
+	rules, _ := FindRules("vegas")
+	result, err := Simulate(rules, "hit-under-17", 20000, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Blackjacks+result.Wins+result.Pushes+result.Losses+result.Surrenders != result.Hands {
+		t.Errorf("expected an outcome for every hand, got %+v", result)
+	}
+
+	// Hitting under 17 never doubles, splits or surrenders
+	if result.Hands != result.Rounds || result.Doubles != 0 || result.Splits != 0 || result.Surrenders != 0 {
+		t.Errorf("expected a single hand played without doubling, splitting or surrendering, got %+v", result)
+	}
+
+	// The variance of a round is close to 1 without doubling nor splitting
+	if result.Variance < 0.8 || result.Variance > 1.1 || result.PlayerBusts < 0.2 || result.DealerBusts < 0.15 {
+		t.Errorf("unexpected variance or bust rates %+v", result)
+	}
+}
+
+func TestRun(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "result.json")
+
+	err := Run(strings.Fields("--rules downtown --hands 5000 --strategy basic --format json -o " + output))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := Result{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Rounds != 5000 || result.Rules != "Downtown Vegas" || result.Strategy != "basic" || result.Surrenders != 0 {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+func TestRunRejectsInvalidArguments(t *testing.T) {
+	for _, args := range []string{"--rules macau", "--strategy counting", "--hands 0", "--format xml"} {
+		if err := Run(strings.Fields(args)); err == nil {
+			t.Errorf("%s: expected an error", args)
+		}
+	}
+}
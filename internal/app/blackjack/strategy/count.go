@@ -0,0 +1,66 @@
+package strategy
+
+import (
+	"math"
+
+	"github.com/Kaamkiya/gg/internal/app/blackjack/engine"
+	"github.com/Kaamkiya/gg/internal/app/cards"
+)
+
+// -------------------- STRUCT: Count --------------------
+
+// HiLo returns the Hi-Lo value of the card: +1 for the low cards from 2 to 6, which are good for the dealer, -1 for
+// the tens and aces, which are good for the players, and 0 for 7 to 9.
+func HiLo(card cards.Card) int {
+	switch value := engine.Value(card); {
+	case value <= 6:
+		return 1
+	case value >= 10:
+		return -1
+	}
+	return 0
+}
+
+// Count keeps the Hi-Lo count of the cards seen since the shoe was shuffled. The running count is the sum of the
+// values of the cards, and the true count is the running count for each deck left in the shoe: the higher it is, the
+// more tens and aces are left for the players.
+type Count struct {
+	shoe    *engine.Shoe // Shoe the cards are dealt from.
+	running int          // Sum of the Hi-Lo values of the cards seen.
+}
+
+// NewCount creates the count of the cards dealt from the shoe.
+func NewCount(shoe *engine.Shoe) *Count {
+	return &Count{shoe: shoe}
+}
+
+// Observe counts the cards turned face up by the events, and starts over when the shoe is shuffled. The hole card
+// is counted once the dealer turns it.
+func (count *Count) Observe(events []engine.Event) {
+	for _, event := range events {
+		switch event.Kind {
+		case engine.Shuffled:
+			count.running = 0
+		case engine.Dealt, engine.HoleCardTurned:
+			if !event.FaceDown {
+				count.running += HiLo(event.Card)
+			}
+		}
+	}
+}
+
+// Running returns the running count.
+func (count *Count) Running() int {
+	return count.running
+}
+
+// DecksLeft returns the number of decks left in the shoe, estimated to the half deck like the players do by looking
+// at the discard tray, and at least half a deck.
+func (count *Count) DecksLeft() float64 {
+	return max(0.5, math.Round(float64(count.shoe.Len())/52*2)/2)
+}
+
+// True returns the true count: the running count divided by the decks left, rounded toward zero.
+func (count *Count) True() int {
+	return int(float64(count.running) / count.DecksLeft())
+}
@@ -0,0 +1,27 @@
+package strategy
+
+import "github.com/Kaamkiya/gg/internal/app/blackjack/engine"
+
+// -------------------- INTERFACE: Policy --------------------
+
+// Policy decides the actions of a player the computer plays for. The basic strategy Table is one.
+type Policy interface {
+	// Action returns the action of the current player of the game, during the insurance or on their turn.
+	Action(game *engine.Game) engine.Action
+}
+
+// HitUnder17 plays like the dealer: it hits until reaching 17 and never takes the insurance, doubles down, splits or
+// surrenders.
+type HitUnder17 struct{}
+
+// Action returns the action of the current player of the game: decline the insurance, hit under 17 and stand
+// otherwise.
+func (HitUnder17) Action(game *engine.Game) engine.Action {
+	if game.Phase() == engine.PhaseInsurance {
+		return engine.ActionDecline
+	}
+	if game.Players()[game.CurrentPlayer()].Hand().Score < 17 && game.CanTake(engine.ActionHit) {
+		return engine.ActionHit
+	}
+	return engine.ActionStand
+}
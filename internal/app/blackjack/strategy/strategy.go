@@ -0,0 +1,340 @@
+// Package strategy plays blackjack by the basic strategy: the action with the best expected return for each hand
+// against each dealer upcard, without counting the cards. The strategy is generated for the rules of a table, which
+// change a few of the decisions. The computer plays its seats by the strategy or by a simpler policy. The Hi-Lo count
+// of the cards tells how good the cards left in the shoe are.
+package strategy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Kaamkiya/gg/internal/app/blackjack/engine"
+	"github.com/Kaamkiya/gg/internal/app/cards"
+)
+
+// -------------------- ENUM: Move --------------------
+
+// Move of a cell of the basic strategy table, as written on the strategy cards.
+type Move string
+
+const (
+	Hit            Move = "H"  // Hit.
+	Stand          Move = "S"  // Stand.
+	Double         Move = "D"  // Double down if allowed, otherwise hit.
+	DoubleStand    Move = "Ds" // Double down if allowed, otherwise stand.
+	Split          Move = "P"  // Split the pair.
+	SurrenderHit   Move = "Rh" // Surrender if allowed, otherwise hit.
+	SurrenderStand Move = "Rs" // Surrender if allowed, otherwise stand.
+	SurrenderSplit Move = "Rp" // Surrender if allowed, otherwise split.
+)
+
+const (
+	upcards     = 10 // Dealer upcards from 2 to ace.
+	minHard     = 4  // Lowest hard total of a table row.
+	minSoft     = 12 // Lowest soft total of a table row, a pair of aces.
+	columnWidth = 3  // Width of the columns of the rendered table.
+)
+
+// upcardIndex returns the column of the dealer's upcard: 0 for a 2 up to 9 for an ace.
+func upcardIndex(upcard cards.Card) int {
+	return engine.Value(upcard) - 2
+}
+
+// -------------------- STRUCT: Table --------------------
+
+// Table of the basic strategy for a rule set: the move of each hard total, soft total and pair against each dealer
+// upcard, from 2 to ace.
+type Table struct {
+	Rules engine.Rules
+	hard  [22][upcards]Move // Moves of the hard totals from 4 to 21.
+	soft  [22][upcards]Move // Moves of the soft totals from 12 to 21.
+	pairs [11][upcards]Move // Split or the move of the total, for the pairs of aces (1) up to tens and faces (10).
+}
+
+// Generate builds the basic strategy table of the rules. The table is the multi-deck strategy with the changes of
+// the common rule variations:
+//   - One or two decks double down a little more, and surrender a little less.
+//   - A dealer hitting soft 17 makes doubling down 11 and soft 18 and 19 worth it, and a few more hands surrender.
+//   - Doubling down after splitting makes splitting the small pairs worth it.
+//   - Without a hole card, the doubled and split bets are lost to a dealer blackjack, so the hands against a 10 or an
+//     ace don't double down or split, but for aces against a 10, and don't surrender against an ace.
+func Generate(rules engine.Rules) *Table {
+	table := &Table{Rules: rules}
+	fewDecks := rules.Decks <= 2
+	h17 := rules.HitSoft17
+
+	// against returns the move for the upcards from low to high, and the other move against the other upcards.
+	against := func(move Move, low, high int, other Move) [upcards]Move {
+		var row [upcards]Move
+		for i := range row {
+			row[i] = other
+			if i+2 >= low && i+2 <= high {
+				row[i] = move
+			}
+		}
+		return row
+	}
+
+	for total := minHard; total <= 21; total++ {
+		row := against(Stand, 2, 11, Stand)
+		switch {
+		case total <= 8:
+			row = against(Hit, 2, 11, Hit)
+			if total == 8 && rules.Decks == 1 {
+				row = against(Double, 5, 6, Hit)
+			}
+		case total == 9 && fewDecks:
+			row = against(Double, 2, 6, Hit)
+		case total == 9:
+			row = against(Double, 3, 6, Hit)
+		case total == 10:
+			row = against(Double, 2, 9, Hit)
+		case total == 11:
+			row = against(Double, 2, 10, Hit)
+			if h17 || fewDecks {
+				row[upcardIndex(ace)] = Double
+			}
+		case total == 12:
+			row = against(Stand, 4, 6, Hit)
+		case total <= 16:
+			row = against(Stand, 2, 6, Hit)
+		}
+
+		if rules.Surrender {
+			switch {
+			case total == 15:
+				row[upcardIndex(ten)] = SurrenderHit
+				if h17 {
+					row[upcardIndex(ace)] = SurrenderHit
+				}
+			case total == 16:
+				row[upcardIndex(ten)] = SurrenderHit
+				row[upcardIndex(ace)] = SurrenderHit
+				if !fewDecks {
+					row[upcardIndex(cards.Card{Rank: cards.Nine})] = SurrenderHit
+				}
+			case total == 17 && h17:
+				row[upcardIndex(ace)] = SurrenderStand
+			}
+		}
+		table.hard[total] = row
+	}
+
+	for total := minSoft; total <= 21; total++ {
+		row := against(Stand, 2, 11, Stand)
+		switch total {
+		case 12:
+			row = against(Hit, 2, 11, Hit)
+		case 13, 14:
+			row = against(Double, 5, 6, Hit)
+		case 15, 16:
+			row = against(Double, 4, 6, Hit)
+		case 17:
+			row = against(Double, 3, 6, Hit)
+		case 18:
+			row = against(DoubleStand, 3, 6, Stand)
+			if h17 {
+				row[0] = DoubleStand
+			}
+			for _, upcard := range []cards.Card{{Rank: cards.Nine}, ten, ace} {
+				row[upcardIndex(upcard)] = Hit
+			}
+		case 19:
+			if h17 {
+				row = against(DoubleStand, 6, 6, Stand)
+			}
+		}
+		table.soft[total] = row
+	}
+
+	for rank := 1; rank <= 10; rank++ {
+		row := table.soft[minSoft]
+		if rank > 1 {
+			row = table.hard[2*rank]
+		}
+
+		split := [upcards]bool{}
+		splitAgainst := func(low, high int) {
+			for i := low; i <= high; i++ {
+				split[i-2] = true
+			}
+		}
+		switch rank {
+		case 1, 8:
+			splitAgainst(2, 11)
+		case 9:
+			splitAgainst(2, 6)
+			splitAgainst(8, 9)
+		case 7:
+			splitAgainst(2, 7)
+		case 6:
+			if rules.DoubleAfterSplit {
+				splitAgainst(2, 6)
+			} else {
+				splitAgainst(3, 6)
+			}
+		case 4:
+			if rules.DoubleAfterSplit {
+				splitAgainst(5, 6)
+			}
+		case 2, 3:
+			if rules.DoubleAfterSplit {
+				splitAgainst(2, 7)
+			} else {
+				splitAgainst(4, 7)
+			}
+		}
+
+		for i := range row {
+			if split[i] {
+				row[i] = Split
+			}
+		}
+		if rank == 8 && rules.Surrender && h17 {
+			row[upcardIndex(ace)] = SurrenderSplit
+		}
+		table.pairs[rank] = row
+	}
+
+	if rules.NoHoleCard {
+		table.withoutHoleCard()
+	}
+
+	return table
+}
+
+var (
+	ten = cards.Card{Rank: cards.Ten}
+	ace = cards.Card{Rank: cards.Ace}
+)
+
+// withoutHoleCard changes the moves against a 10 or an ace which risk more chips: a dealer blackjack takes the
+// doubled and split bets, and the whole bet of a surrendered hand.
+func (table *Table) withoutHoleCard() {
+	safer := map[Move]Move{
+		Double:         Hit,
+		DoubleStand:    Stand,
+		Split:          Hit,
+		SurrenderSplit: Hit,
+	}
+	againstAce := map[Move]Move{
+		SurrenderHit:   Hit,
+		SurrenderStand: Stand,
+	}
+
+	rows := make([]*[upcards]Move, 0, len(table.hard)+len(table.soft)+len(table.pairs))
+	for i := range table.hard {
+		rows = append(rows, &table.hard[i])
+	}
+	for i := range table.soft {
+		rows = append(rows, &table.soft[i])
+	}
+	for rank := 2; rank < len(table.pairs); rank++ {
+		rows = append(rows, &table.pairs[rank])
+	}
+
+	for _, row := range rows {
+		for _, upcard := range []cards.Card{ten, ace} {
+			i := upcardIndex(upcard)
+			if move, ok := safer[row[i]]; ok {
+				row[i] = move
+			}
+		}
+		if move, ok := againstAce[row[upcardIndex(ace)]]; ok {
+			row[upcardIndex(ace)] = move
+		}
+	}
+
+	// Split aces still beat a ten, but not an ace
+	table.pairs[1][upcardIndex(ace)] = Hit
+}
+
+// Move returns the move of the table for the hand against the dealer's upcard. The pairs are only looked up if the
+// hand can be split, the hand being played by its total otherwise.
+func (table *Table) Move(hand *engine.Hand, upcard cards.Card, canSplit bool) Move {
+	i := upcardIndex(upcard)
+	switch {
+	case canSplit && hand.IsPair():
+		return table.pairs[min(hand.Cards[0].Rank, 10)][i]
+	case hand.Soft():
+		return table.soft[max(hand.Score, minSoft)][i]
+	}
+	return table.hard[min(max(hand.Score, minHard), 21)][i]
+}
+
+// Action returns the action of the basic strategy for the current player of the game: the insurance is always
+// declined, and the hand is played by the move of the table, falling back to the second choice of the move when the
+// first one isn't allowed.
+func (table *Table) Action(game *engine.Game) engine.Action {
+	if game.Phase() == engine.PhaseInsurance {
+		return engine.ActionDecline
+	}
+
+	hand := game.Players()[game.CurrentPlayer()].Hand()
+	upcard, _ := game.Upcard()
+
+	choices := map[Move][]engine.Action{
+		Hit:            {engine.ActionHit},
+		Stand:          {engine.ActionStand},
+		Double:         {engine.ActionDouble, engine.ActionHit},
+		DoubleStand:    {engine.ActionDouble, engine.ActionStand},
+		Split:          {engine.ActionSplit},
+		SurrenderHit:   {engine.ActionSurrender, engine.ActionHit},
+		SurrenderStand: {engine.ActionSurrender, engine.ActionStand},
+		SurrenderSplit: {engine.ActionSurrender, engine.ActionSplit},
+	}[table.Move(hand, upcard, game.CanTake(engine.ActionSplit))]
+
+	for _, action := range choices {
+		if game.CanTake(action) {
+			return action
+		}
+	}
+	return engine.ActionStand
+}
+
+// String renders the table as a strategy card: the hard totals from 8 to 17, the soft totals and the pairs against
+// each dealer upcard.
+func (table *Table) String() string {
+	var s strings.Builder
+
+	header := func(title string) {
+		fmt.Fprintf(&s, "%-6s", title)
+		for upcard := 2; upcard <= 11; upcard++ {
+			label := fmt.Sprint(upcard)
+			if upcard == 11 {
+				label = "A"
+			}
+			fmt.Fprintf(&s, "%-*s", columnWidth, label)
+		}
+		s.WriteString("\n")
+	}
+	row := func(label string, moves [upcards]Move) {
+		fmt.Fprintf(&s, "%-6s", label)
+		for _, move := range moves {
+			fmt.Fprintf(&s, "%-*s", columnWidth, move)
+		}
+		s.WriteString("\n")
+	}
+
+	header("Hard")
+	for total := 8; total <= 17; total++ {
+		row(fmt.Sprint(total), table.hard[total])
+	}
+
+	header("Soft")
+	for total := 13; total <= 20; total++ {
+		row(fmt.Sprintf("A,%d", total-11), table.soft[total])
+	}
+
+	header("Pairs")
+	row("A,A", table.pairs[1])
+	for rank := 10; rank >= 2; rank-- {
+		row(fmt.Sprintf("%d,%d", rank, rank), table.pairs[rank])
+	}
+
+	lines := strings.Split(strings.TrimRight(s.String(), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,175 @@
+package strategy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/app/blackjack/engine"
+	"github.com/Kaamkiya/gg/internal/app/cards"
+)
+
+// hand returns a hand of the cards of the ranks.
+func hand(ranks ...cards.Rank) *engine.Hand {
+	hand := &engine.Hand{}
+	for i, rank := range ranks {
+		hand.Cards = append(hand.Cards, cards.Card{Suit: cards.Suit(i % 4), Rank: rank})
+	}
+	hand.UpdateScore()
+	return hand
+}
+
+// Test the moves of the tables of the presets against the published basic strategy charts
+func TestGenerate(t *testing.T) {
+	vegas := engine.Presets[1]
+	downtown := engine.Presets[2]
+	european := engine.Presets[len(engine.Presets)-1]
+	noSurrender := vegas
+	noSurrender.Surrender = false
+	noDoubleAfterSplit := vegas
+	noDoubleAfterSplit.DoubleAfterSplit = false
+
+	tests := []struct {
+		name   string
+		rules  engine.Rules
+		hand   *engine.Hand
+		upcard cards.Rank
+		move   Move
+	}{
+		{"Hard 16 against a 10 surrenders", vegas, hand(10, 6), 10, SurrenderHit},
+		{"Hard 16 against a 10 hits without surrender", noSurrender, hand(10, 6), 10, Hit},
+		{"Hard 12 against a 3 hits", vegas, hand(10, 2), 3, Hit},
+		{"Hard 12 against a 4 stands", vegas, hand(10, 2), 4, Stand},
+		{"Hard 11 against an ace hits in a shoe", vegas, hand(6, 5), 1, Hit},
+		{"Hard 11 against an ace doubles when the dealer hits soft 17", downtown, hand(6, 5), 1, Double},
+		{"Hard 9 against a 2 doubles with few decks", downtown, hand(5, 4), 2, Double},
+		{"Hard 9 against a 2 hits in a shoe", vegas, hand(5, 4), 2, Hit},
+		{"Soft 18 against a 2 stands", vegas, hand(1, 7), 2, Stand},
+		{"Soft 18 against a 2 doubles when the dealer hits soft 17", downtown, hand(1, 7), 2, DoubleStand},
+		{"Soft 18 against a 9 hits", vegas, hand(1, 7), 9, Hit},
+		{"Soft 19 against a 6 doubles when the dealer hits soft 17", downtown, hand(1, 8), 6, DoubleStand},
+		{"Aces split", vegas, hand(1, 1), 1, Split},
+		{"Tens stand", vegas, hand(13, 12), 6, Stand},
+		{"Nines stand against a 7", vegas, hand(9, 9), 7, Stand},
+		{"Fours split against a 5 with double after split", vegas, hand(4, 4), 5, Split},
+		{"Fours hit against a 5 without double after split", noDoubleAfterSplit, hand(4, 4), 5, Hit},
+		{"Twos split against a 3 only with double after split", noDoubleAfterSplit, hand(2, 2), 3, Hit},
+		{"Eights surrender against an ace when the dealer hits soft 17", engine.Rules{Decks: 6, HitSoft17: true, Surrender: true}, hand(8, 8), 1, SurrenderSplit},
+		{"Eights hit against a 10 without a hole card", european, hand(8, 8), 10, Hit},
+		{"Aces split against a 10 without a hole card", european, hand(1, 1), 10, Split},
+		{"Hard 11 hits against a 10 without a hole card", european, hand(6, 5), 10, Hit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			move := Generate(tt.rules).Move(tt.hand, cards.Card{Rank: tt.upcard}, true)
+			if move != tt.move {
+				t.Errorf("Expected %s, got %s", tt.move, move)
+			}
+		})
+	}
+}
+
+// Test that the moves fall back to their second choice when the first one isn't allowed
+func TestAction(t *testing.T) {
+	table := Generate(engine.Presets[1])
+
+	// 10-6 against a 10 surrenders, but hits after a hit
+	state := "1 1 player_turn 0 10♣ 1000:10:0:0:0:0:0:0:%s;10;-;0;0"
+	game, err := engine.Load(strings.Replace(state, "%s", "10♥,6♠", 1), table.Rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if action := table.Action(game); action != engine.ActionSurrender {
+		t.Errorf("Expected 16 against a 10 to surrender, got %s", action)
+	}
+
+	game, _ = engine.Load(strings.Replace(state, "%s", "4♥,2♠,10♦", 1), table.Rules)
+	if action := table.Action(game); action != engine.ActionHit {
+		t.Errorf("Expected 16 of three cards against a 10 to hit, got %s", action)
+	}
+
+	// Soft 18 against a 6 doubles, but stands after a hit
+	state = strings.Replace(state, "10♣", "6♣", 1)
+	game, _ = engine.Load(strings.Replace(state, "%s", "A♥,2♠,5♦", 1), table.Rules)
+	if action := table.Action(game); action != engine.ActionStand {
+		t.Errorf("Expected soft 18 of three cards to stand, got %s", action)
+	}
+
+	game, _ = engine.Load("1 1 insurance 0 A♣ 1000:10:0:0:0:0:0:0:10♥,10♠;10;-;0;0", table.Rules)
+	if action := table.Action(game); action != engine.ActionDecline {
+		t.Errorf("Expected the insurance to be declined, got %s", action)
+	}
+}
+
+// Test the rendering of the table
+func TestTableString(t *testing.T) {
+	table := Generate(engine.Presets[1]).String()
+	lines := strings.Split(table, "\n")
+
+	if len(lines) != 3+10+8+10 || lines[0] != "Hard  2  3  4  5  6  7  8  9  10 A" {
+		t.Fatalf("Unexpected table:\n%s", table)
+	}
+
+	if lines[9] != "16    S  S  S  S  S  H  H  Rh Rh Rh" {
+		t.Errorf("Unexpected row of hard 16: %q", lines[9])
+	}
+}
+
+// Test the Hi-Lo running count of the cards seen and the true count of the decks left
+func TestCount(t *testing.T) {
+	shoe := engine.NewShoe(2, 0.75)
+	shoe.Shuffle()
+	count := NewCount(shoe)
+
+	dealt := func(rank cards.Rank) engine.Event {
+		return engine.Event{Kind: engine.Dealt, Card: cards.Card{Rank: rank}}
+	}
+	count.Observe([]engine.Event{
+		dealt(5), dealt(2), dealt(8),
+		{Kind: engine.Dealt, Card: cards.Card{Rank: 1}, FaceDown: true},
+		dealt(4), dealt(6), dealt(3),
+	})
+
+	if count.Running() != 5 || count.DecksLeft() != 2 || count.True() != 2 {
+		t.Errorf("Expected a running count of +5 and a true count of +2, got %+d and %+d", count.Running(), count.True())
+	}
+
+	// The hole card is counted once turned
+	count.Observe([]engine.Event{{Kind: engine.HoleCardTurned, Card: cards.Card{Rank: 1}}})
+	if count.Running() != 4 {
+		t.Errorf("Expected the hole card to be counted once turned, got %+d", count.Running())
+	}
+
+	for shoe.Len() > 20 {
+		shoe.Deal()
+	}
+	if count.DecksLeft() != 0.5 || count.True() != 8 {
+		t.Errorf("Expected half a deck left and a true count of +8, got %.1f decks and %+d", count.DecksLeft(), count.True())
+	}
+
+	count.Observe([]engine.Event{{Kind: engine.Shuffled}})
+	if count.Running() != 0 {
+		t.Errorf("Expected the count to start over after a shuffle, got %+d", count.Running())
+	}
+}
+
+// Test that the hit under 17 policy plays like the dealer
+func TestHitUnder17(t *testing.T) {
+	game, err := engine.Load("1 1 player_turn 0 6♣ 1000:10:0:0:0:0:0:0:10♥,6♠;10;-;0;0", engine.DefaultRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if action := (HitUnder17{}).Action(game); action != engine.ActionHit {
+		t.Errorf("Expected 16 to hit, got %s", action)
+	}
+
+	game, _ = engine.Load("1 1 player_turn 0 6♣ 1000:10:0:0:0:0:0:0:A♥,6♠;10;-;0;0", engine.DefaultRules)
+	if action := (HitUnder17{}).Action(game); action != engine.ActionStand {
+		t.Errorf("Expected soft 17 to stand, got %s", action)
+	}
+
+	game, _ = engine.Load("1 1 insurance 0 A♣ 1000:10:0:0:0:0:0:0:10♥,10♠;10;-;0;0", engine.DefaultRules)
+	if action := (HitUnder17{}).Action(game); action != engine.ActionDecline {
+		t.Errorf("Expected the insurance to be declined, got %s", action)
+	}
+}
@@ -0,0 +1,86 @@
+package blackjack
+
+import (
+	"fmt"
+
+	"github.com/Kaamkiya/gg/internal/app/blackjack/engine"
+	"github.com/Kaamkiya/gg/internal/app/cards"
+)
+
+// -------------------- STRUCT: trainer --------------------
+
+// deviation is a decision which wasn't the one of the basic strategy.
+type deviation struct {
+	situation string        // Hand against the dealer's upcard, e.g. "hard 16 vs 10".
+	played    engine.Action // Action the player took.
+	advised   engine.Action // Action of the basic strategy.
+}
+
+// trainer checks every decision of the players against the basic strategy.
+type trainer struct {
+	decisions  int         // Number of decisions taken.
+	deviations []deviation // Decisions which weren't the ones of the basic strategy.
+	feedback   string      // Correction of the last decision, empty if it was right.
+}
+
+// record counts the decision of the player in the situation.
+func (trainer *trainer) record(situation string, played, advised engine.Action) {
+	trainer.decisions++
+	trainer.feedback = ""
+	if played != advised {
+		trainer.deviations = append(trainer.deviations, deviation{situation, played, advised})
+		trainer.feedback = fmt.Sprintf("%s: basic strategy says %s, not %s", situation, actionText(advised), actionText(played))
+	}
+}
+
+// accuracy returns the percentage of decisions following the basic strategy.
+func (trainer *trainer) accuracy() int {
+	if trainer.decisions == 0 {
+		return 100
+	}
+	return 100 * (trainer.decisions - len(trainer.deviations)) / trainer.decisions
+}
+
+// report describes the accuracy of the session and lists the deviations from the basic strategy.
+func (trainer *trainer) report() string {
+	s := fmt.Sprintf("\nBasic strategy accuracy: %d%% (%d of %d decisions)", trainer.accuracy(),
+		trainer.decisions-len(trainer.deviations), trainer.decisions)
+	for _, deviation := range trainer.deviations {
+		s += fmt.Sprintf("\n  %s: %s instead of %s", deviation.situation, actionText(deviation.played), actionText(deviation.advised))
+	}
+	return s
+}
+
+// situation describes the decision of the current player: their hand against the dealer's upcard, or the insurance.
+func situation(game *engine.Game) string {
+	upcard, _ := game.Upcard()
+	dealer := fmt.Sprint(min(upcard.Rank, cards.Ten))
+	if upcard.Rank == cards.Ace {
+		dealer = "A"
+	}
+
+	hand := game.Players()[game.CurrentPlayer()].Hand()
+	switch {
+	case game.Phase() == engine.PhaseInsurance:
+		return "insurance vs " + dealer
+	case game.CanTake(engine.ActionSplit):
+		pair := fmt.Sprint(min(hand.Cards[0].Rank, cards.Ten))
+		if hand.Cards[0].Rank == cards.Ace {
+			pair = "A"
+		}
+		return fmt.Sprintf("pair of %ss vs %s", pair, dealer)
+	case hand.Soft():
+		return fmt.Sprintf("soft %d vs %s", hand.Score, dealer)
+	}
+	return fmt.Sprintf("hard %d vs %s", hand.Score, dealer)
+}
+
+// actionText returns what the prompt says the action does.
+func actionText(action engine.Action) string {
+	for _, actionKey := range actionKeys {
+		if actionKey.action == action {
+			return actionKey.text
+		}
+	}
+	return string(action)
+}
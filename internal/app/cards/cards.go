@@ -0,0 +1,205 @@
+// Package cards is the deck of playing cards shared by the card games: the suits and ranks of the cards, the jokers,
+// decks made of several 52-card decks shuffled from a seed, and the rendering of the cards in the terminal.
+package cards
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strconv"
+	"unicode/utf8"
+)
+
+// -------------------- ENUM: Suit --------------------
+
+// Suit of a card.
+type Suit int
+
+const (
+	// Heart is the suit of hearts (♥).
+	Heart Suit = iota
+	// Diamond is the suit of diamonds (♦).
+	Diamond
+	// Club is the suit of clubs (♣).
+	Club
+	// Spade is the suit of spades (♠).
+	Spade
+)
+
+// Suits are the four suits, in the order of a new deck.
+var Suits = []Suit{Heart, Diamond, Club, Spade}
+
+// String returns the symbol of the suit, e.g. "♥".
+func (suit Suit) String() string {
+	return [...]string{"♥", "♦", "♣", "♠"}[suit]
+}
+
+// Red tells if the suit is red: hearts and diamonds are red, clubs and spades black.
+func (suit Suit) Red() bool {
+	return suit == Heart || suit == Diamond
+}
+
+// -------------------- ENUM: Rank --------------------
+
+// Rank of a card, from the ace to the king, or a joker.
+type Rank int
+
+const (
+	Ace Rank = iota + 1
+	Two
+	Three
+	Four
+	Five
+	Six
+	Seven
+	Eight
+	Nine
+	Ten
+	Jack
+	Queen
+	King
+	Joker // Joker, which has no suit.
+)
+
+// String returns the index of the rank written in the corner of the cards, e.g. "A", "10" or "K", and "JK" for a
+// joker.
+func (rank Rank) String() string {
+	switch rank {
+	case Ace:
+		return "A"
+	case Jack:
+		return "J"
+	case Queen:
+		return "Q"
+	case King:
+		return "K"
+	case Joker:
+		return "JK"
+	}
+	return strconv.Itoa(int(rank))
+}
+
+// -------------------- STRUCT: Card --------------------
+
+// Card is a playing card. The suit of a joker is left to Heart.
+type Card struct {
+	Suit Suit // Suit of the card (Heart, Diamond, Club, Spade).
+	Rank Rank // Ace to King, or Joker.
+}
+
+// String returns the rank and the suit of the card, e.g. "A♥" or "10♠", and "JK" for a joker.
+func (card Card) String() string {
+	if card.Rank == Joker {
+		return card.Rank.String()
+	}
+	return card.Rank.String() + card.Suit.String()
+}
+
+// Parse reads a card written by Card.String, e.g. "A♥", "10♠" or "JK".
+func Parse(text string) (Card, error) {
+	if text == Joker.String() {
+		return Card{Rank: Joker}, nil
+	}
+
+	symbol, size := utf8.DecodeLastRuneInString(text)
+	index := text[:len(text)-size]
+
+	suit := slices.IndexFunc(Suits, func(suit Suit) bool {
+		return suit.String() == string(symbol)
+	})
+	rank := slices.IndexFunc(ranks(), func(rank Rank) bool {
+		return rank.String() == index
+	})
+
+	if suit < 0 || rank < 0 {
+		return Card{}, fmt.Errorf("invalid card %q", text)
+	}
+
+	return Card{Suit: Suits[suit], Rank: ranks()[rank]}, nil
+}
+
+// ranks returns the ranks of the cards of a suit, from the ace to the king.
+func ranks() []Rank {
+	ranks := make([]Rank, 0, King)
+	for rank := Ace; rank <= King; rank++ {
+		ranks = append(ranks, rank)
+	}
+	return ranks
+}
+
+// -------------------- STRUCT: Deck --------------------
+
+// ErrEmpty is returned when dealing from an empty deck.
+var ErrEmpty = errors.New("the deck is empty")
+
+// Deck holds the cards of one or more 52-card decks and of their jokers. The zero value is an empty single deck
+// without jokers, and a deck is empty until shuffled.
+type Deck struct {
+	cards  []Card     // Cards left in the deck, the top card last.
+	decks  int        // Number of 52-card decks.
+	jokers int        // Number of jokers of each deck.
+	rng    *rand.Rand // Random order of the cards, the shared source if nil.
+}
+
+// NewDeck creates an empty deck of the given number of 52-card decks, with the given number of jokers in each, to be
+// shuffled before dealing.
+func NewDeck(decks, jokers int) *Deck {
+	return NewSeededDeck(decks, jokers, rand.Int63())
+}
+
+// NewSeededDeck creates an empty deck like NewDeck which is always shuffled in the same orders for the same seed.
+func NewSeededDeck(decks, jokers int, seed int64) *Deck {
+	return &Deck{decks: decks, jokers: jokers, rng: rand.New(rand.NewSource(seed))}
+}
+
+// Decks returns the number of 52-card decks of the deck.
+func (deck *Deck) Decks() int {
+	return max(deck.decks, 1)
+}
+
+// Shuffle puts all the cards back in the deck and randomizes their order.
+func (deck *Deck) Shuffle() {
+	deck.cards = make([]Card, 0, (52+deck.jokers)*deck.Decks())
+	for i := 0; i < deck.Decks(); i++ {
+		for _, suit := range Suits {
+			for _, rank := range ranks() {
+				deck.cards = append(deck.cards, Card{Suit: suit, Rank: rank})
+			}
+		}
+		for j := 0; j < deck.jokers; j++ {
+			deck.cards = append(deck.cards, Card{Rank: Joker})
+		}
+	}
+
+	swap := func(i, j int) {
+		deck.cards[i], deck.cards[j] = deck.cards[j], deck.cards[i]
+	}
+	if deck.rng == nil {
+		rand.Shuffle(len(deck.cards), swap)
+	} else {
+		deck.rng.Shuffle(len(deck.cards), swap)
+	}
+}
+
+// Deal pops a card off the top of the deck, or returns ErrEmpty if the deck is empty.
+func (deck *Deck) Deal() (Card, error) {
+	if len(deck.cards) == 0 {
+		return Card{}, ErrEmpty
+	}
+	card := deck.cards[len(deck.cards)-1]
+	deck.cards = deck.cards[:len(deck.cards)-1]
+	return card, nil
+}
+
+// Len returns the number of cards left in the deck.
+func (deck *Deck) Len() int {
+	return len(deck.cards)
+}
+
+// Stack puts the cards on top of the deck, to be dealt in the given order. It sets up hands to practice and tests.
+func (deck *Deck) Stack(cards ...Card) {
+	cards = slices.Clone(cards)
+	slices.Reverse(cards)
+	deck.cards = append(deck.cards, cards...)
+}
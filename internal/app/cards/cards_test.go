@@ -0,0 +1,160 @@
+package cards
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// deal deals all the cards of the deck.
+func deal(deck *Deck) []Card {
+	var cards []Card
+	for deck.Len() > 0 {
+		card, _ := deck.Deal()
+		cards = append(cards, card)
+	}
+	return cards
+}
+
+// Test that a shuffled deck has the 52 cards once, and the jokers
+func TestDeckShuffle(t *testing.T) {
+	deck := &Deck{}
+	deck.Shuffle()
+
+	seen := make(map[Card]int)
+	for _, card := range deal(deck) {
+		seen[card]++
+	}
+
+	if len(seen) != 52 {
+		t.Errorf("Expected 52 different cards, got %d", len(seen))
+	}
+	for _, suit := range Suits {
+		for rank := Ace; rank <= King; rank++ {
+			if seen[Card{Suit: suit, Rank: rank}] != 1 {
+				t.Errorf("Expected one %s, got %d", Card{Suit: suit, Rank: rank}, seen[Card{Suit: suit, Rank: rank}])
+			}
+		}
+	}
+
+	// The decks of a shoe each have their jokers
+	deck = NewDeck(6, 2)
+	deck.Shuffle()
+	if deck.Len() != 6*54 || deck.Decks() != 6 {
+		t.Fatalf("Expected 324 cards in 6 decks, got %d cards in %d decks", deck.Len(), deck.Decks())
+	}
+
+	jokers := 0
+	for _, card := range deal(deck) {
+		if card.Rank == Joker {
+			jokers++
+		}
+	}
+	if jokers != 12 {
+		t.Errorf("Expected 12 jokers, got %d", jokers)
+	}
+
+	// Shuffling again puts the dealt cards back
+	deck.Shuffle()
+	if deck.Len() != 6*54 {
+		t.Errorf("Expected the cards to be put back, got %d cards", deck.Len())
+	}
+}
+
+// Test that the decks of the same seed are shuffled in the same orders
+func TestSeededDeck(t *testing.T) {
+	a, b, c := NewSeededDeck(1, 0, 42), NewSeededDeck(1, 0, 42), NewSeededDeck(1, 0, 7)
+
+	for i := 0; i < 3; i++ {
+		a.Shuffle()
+		b.Shuffle()
+		c.Shuffle()
+
+		cardsA, cardsB, cardsC := deal(a), deal(b), deal(c)
+		if !slices.Equal(cardsA, cardsB) {
+			t.Errorf("Shuffle %d: expected the same order for the same seed", i+1)
+		}
+		if slices.Equal(cardsA, cardsC) {
+			t.Errorf("Shuffle %d: expected another order for another seed", i+1)
+		}
+	}
+}
+
+// Test dealing the cards from the top and from an empty deck
+func TestDeal(t *testing.T) {
+	deck := &Deck{}
+	if _, err := deck.Deal(); !errors.Is(err, ErrEmpty) {
+		t.Errorf("Expected ErrEmpty from an empty deck, got %v", err)
+	}
+
+	deck.Stack(Card{Heart, Ace}, Card{Spade, King})
+	for _, expected := range []Card{{Heart, Ace}, {Spade, King}} {
+		if card, err := deck.Deal(); card != expected || err != nil {
+			t.Errorf("Expected %s to be dealt, got %s (%v)", expected, card, err)
+		}
+	}
+
+	deck.Shuffle()
+	for i := 0; i < 10; i++ {
+		deck.Deal()
+	}
+	if deck.Len() != 42 {
+		t.Errorf("Expected 42 cards after dealing 10, got %d", deck.Len())
+	}
+}
+
+// Test that the cards are written and parsed back
+func TestParse(t *testing.T) {
+	for _, text := range []string{"A♥", "10♠", "7♣", "Q♦", "K♠", "JK"} {
+		card, err := Parse(text)
+		if err != nil || card.String() != text {
+			t.Errorf("%s: parsed as %s (%v)", text, card, err)
+		}
+	}
+
+	for _, text := range []string{"", "A", "1♥", "11♠", "Z♣", "10x", "JK♥"} {
+		if _, err := Parse(text); err == nil {
+			t.Errorf("%s: expected an error", text)
+		}
+	}
+
+	if !Heart.Red() || !Diamond.Red() || Club.Red() || Spade.Red() {
+		t.Error("Expected hearts and diamonds to be the red suits")
+	}
+}
+
+// Test the ASCII art of the cards
+func TestRender(t *testing.T) {
+	for _, card := range []Card{{Heart, Ace}, {Spade, Ten}, {Club, Queen}, {Rank: Joker}} {
+		rendered := Render(card)
+		if lipgloss.Width(rendered) != Width || lipgloss.Height(rendered) != Height {
+			t.Errorf("%s: expected a card of %dx%d, got %dx%d:\n%s", card, Width, Height, lipgloss.Width(rendered), lipgloss.Height(rendered), rendered)
+		}
+	}
+
+	lines := strings.Split(Render(Card{Spade, Ten}), "\n")
+	if !strings.Contains(lines[1], "10") || strings.Count(Render(Card{Spade, Ten}), "♠") != 10 {
+		t.Errorf("Expected the rank in the corner and 10 spades, got:\n%s", strings.Join(lines, "\n"))
+	}
+
+	if !strings.Contains(Render(Card{Diamond, King}), "WWW") {
+		t.Errorf("Expected the crown of the king, got:\n%s", Render(Card{Diamond, King}))
+	}
+
+	top := RenderTop(Card{Heart, Ten})
+	if lipgloss.Width(top) != Width || lipgloss.Height(top) != 2 || !strings.Contains(top, "10♥") {
+		t.Errorf("Expected the top edge of the card, got:\n%s", top)
+	}
+
+	back := RenderBack()
+	if lipgloss.Width(back) != Width || lipgloss.Height(back) != Height || strings.ContainsAny(back, "♥♦♣♠") {
+		t.Errorf("Expected the back of a card, got:\n%s", back)
+	}
+
+	if row := RenderRow(Card{Heart, Two}, Card{Club, Three}); lipgloss.Width(row) != 2*Width {
+		t.Errorf("Expected the cards side by side, got:\n%s", row)
+	}
+}
@@ -0,0 +1,97 @@
+package cards
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// Width is the number of columns of a rendered card, its border included.
+	Width = 9
+	// Height is the number of lines of a rendered card, its border included.
+	Height = 7
+)
+
+var (
+	// faceStyle draws the border of the cards face up, in the color of their suit.
+	faceStyle = lipgloss.NewStyle().Border(lipgloss.RoundedBorder())
+	// backStyle draws the cards face down.
+	backStyle = faceStyle.Foreground(lipgloss.Color("12")) // Bright blue
+	// redStyle colors the hearts and the diamonds.
+	redStyle = faceStyle.Foreground(lipgloss.Color("9")) // Bright red
+)
+
+// pips are the layouts of the suit symbols in the middle of the number cards, an x standing for a symbol.
+var pips = map[Rank][3]string{
+	Ace:   {"       ", "   x   ", "       "},
+	Two:   {"   x   ", "       ", "   x   "},
+	Three: {"   x   ", "   x   ", "   x   "},
+	Four:  {" x   x ", "       ", " x   x "},
+	Five:  {" x   x ", "   x   ", " x   x "},
+	Six:   {" x   x ", " x   x ", " x   x "},
+	Seven: {" x   x ", " x x x ", " x   x "},
+	Eight: {" x x x ", " x   x ", " x x x "},
+	Nine:  {" x x x ", " x x x ", " x x x "},
+	Ten:   {" x x x ", "x x x x", " x x x "},
+}
+
+// faces are the ASCII art of the face cards and the joker, an x standing for the suit symbol.
+var faces = map[Rank][3]string{
+	Jack:  {`  /"\  `, `  |x|  `, `  \_/  `},
+	Queen: {`  %%%  `, ` (x x) `, `  )=(  `},
+	King:  {`  WWW  `, ` (o o) `, `  \x/  `},
+	Joker: {` ,/ \, `, `  o o  `, ` \_^_/ `},
+}
+
+// Render draws the card face up in a bordered box of Width by Height: its rank in the corners and the symbols of its
+// suit, or the ASCII art of a face card or a joker, in the middle. Hearts and diamonds are red.
+func Render(card Card) string {
+	art, ok := faces[card.Rank]
+	if !ok {
+		art = pips[card.Rank]
+	}
+
+	lines := []string{card.Rank.String()}
+	for _, line := range art {
+		if card.Rank != Joker {
+			line = strings.ReplaceAll(line, "x", card.Suit.String())
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, strings.Repeat(" ", Width-2-len(card.Rank.String()))+card.Rank.String())
+
+	return style(card).Width(Width - 2).Render(strings.Join(lines, "\n"))
+}
+
+// RenderTop draws the top edge of the card face up with its rank and suit, as seen when another card covers it in a
+// pile.
+func RenderTop(card Card) string {
+	return style(card).Width(Width - 2).BorderBottom(false).Render(card.String())
+}
+
+// style returns the style of the card face up: red for hearts and diamonds.
+func style(card Card) lipgloss.Style {
+	if card.Rank != Joker && card.Suit.Red() {
+		return redStyle
+	}
+	return faceStyle
+}
+
+// RenderBack draws a card face down, the same size as the cards face up.
+func RenderBack() string {
+	lines := make([]string, Height-2)
+	for i := range lines {
+		lines[i] = strings.Repeat("░", Width-2)
+	}
+	return backStyle.Render(strings.Join(lines, "\n"))
+}
+
+// RenderRow draws the cards face up side by side.
+func RenderRow(cards ...Card) string {
+	rendered := make([]string, len(cards))
+	for i, card := range cards {
+		rendered[i] = Render(card)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, rendered...)
+}
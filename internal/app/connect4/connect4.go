@@ -4,191 +4,343 @@ import (
 	"fmt"
 	"strconv"
 
+	"github.com/Kaamkiya/gg/internal/app/tictactoe/engine"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
 )
 
+// Strength is how much the AI searches for each move: the number of iterations
+// of MCTS or the search depth of negamax.
+type Strength struct {
+	Iterations int
+	Depth      int
+}
+
+var (
+	Easy   = Strength{200, 2}
+	Medium = Strength{1000, 6}
+	Hard   = Strength{5000, 12}
+)
+
+// NewAI creates an AI of the algorithm searching as much as the strength allows.
+func (s Strength) NewAI(algorithm engine.Algorithm) engine.AI {
+	depth := s.Iterations
+	if algorithm == engine.NegamaxAlgorithm {
+		depth = s.Depth
+	}
+
+	return engine.NewAI(algorithm, NewEngine(), depth)
+}
+
+// recordGame is the game of the records of connect 4, see engine.Record.
+const recordGame = "connect4"
+
+// ColumnNotation names a move by its column number, from 1 on the left.
+type ColumnNotation struct{}
+
+func (ColumnNotation) FormatMove(board *engine.Board, move int) string {
+	return strconv.Itoa(move + 1)
+}
+
+func (ColumnNotation) ParseMove(board *engine.Board, text string) (int, error) {
+	col, err := strconv.Atoi(text)
+	if err != nil || col < 1 || col > board.Cols {
+		return 0, fmt.Errorf("invalid column %q", text)
+	}
+
+	return col - 1, nil
+}
+
+// model plays x as engine.P1 and o as engine.P2. In vs AI mode the AI plays o.
+// The moves are recorded so that they can be taken back and exported.
 type model struct {
-	board [6][7]rune // [y][x]
-	turn  rune
+	board    *engine.Board
+	engine   *Engine
+	ai       engine.AI
+	turn     int
+	winner   int
+	gameover bool
+	record   engine.Record
+	saved    string
 
 	xStyle lipgloss.Style
 	oStyle lipgloss.Style
 }
 
-func initialModel() tea.Model {
-	board := [6][7]rune{}
-	for y := range board {
-		for x := range board[y] {
-			board[y][x] = ' '
-		}
-	}
+type aiMoveMsg struct{ column int }
+
+func initialModel(ai engine.AI) model {
+	board := NewBoard()
 
 	return model{
 		board:  board,
-		turn:   'x',
+		engine: NewEngine(),
+		ai:     ai,
+		turn:   engine.P1,
+		record: engine.NewRecord(recordGame, board, engine.P1),
 		xStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("2")),
 		oStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("9")),
 	}
 }
 
+// loadedModel creates a game from the position of the record.
+func loadedModel(ai engine.AI, record engine.Record) (model, error) {
+	m := initialModel(ai)
+
+	if record.Game != recordGame || record.Rows != rows || record.Cols != cols {
+		return m, fmt.Errorf("cannot load a %dx%d game of %s", record.Rows, record.Cols, record.Game)
+	}
+
+	err := m.load(record)
+	return m, err
+}
+
+// Init lets the AI play first in loaded games where it is its turn.
 func (m model) Init() tea.Cmd {
+	if m.isAITurn() {
+		return m.aiMove()
+	}
+
 	return nil
 }
 
+func (m model) isAITurn() bool {
+	return m.ai != nil && m.turn == engine.P2 && !m.gameover
+}
+
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case aiMoveMsg:
+		return m.play(msg.column)
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c", "q":
 			return m, tea.Quit
+		case "u":
+			m.undo()
+		case "e":
+			m.export()
 		case "1", "2", "3", "4", "5", "6", "7":
+			// The AI is thinking, wait for its move.
+			if m.isAITurn() || m.gameover {
+				return m, nil
+			}
+
 			/* Don't check for errors because there can't be one.
 			 * This only gets called if an integer was inputted.
 			 */
 			col, _ := strconv.Atoi(msg.String())
 			col-- // Go is 0 indexed, inputs are not.
 
-			// A piece can only go in that column if it's not full.
-			if m.board[0][col] == ' ' {
-				for y := len(m.board) - 1; y >= 0; y-- {
-					if m.board[y][col] == ' ' {
-						m.board[y][col] = m.turn
-
-						if m.turn == 'x' {
-							m.turn = 'o'
-						} else {
-							m.turn = 'x'
-						}
-
-						break
-					}
-				}
-			}
+			return m.play(col)
+		}
+	}
+
+	return m, nil
+}
+
+// play drops a piece of the current player in the column if it is not full,
+// then ends the game if it is over or lets the AI play if it is its turn.
+func (m model) play(col int) (tea.Model, tea.Cmd) {
+	if err := m.engine.PlayMove(m.board, m.turn, col); err != nil {
+		return m, nil
+	}
+	m.record.Moves = append(m.record.Moves, col)
+
+	if over, win := m.engine.CheckGameOver(m.board, col); over {
+		m.gameover = true
+		if win != 0 {
+			m.winner = m.turn
 		}
+
+		return m, nil
 	}
 
-	if m.CheckForWin() != ' ' {
-		return m, tea.Quit
+	m.turn = m.engine.GetOpponent(m.turn)
+
+	if m.isAITurn() {
+		return m, m.aiMove()
 	}
 
 	return m, nil
 }
 
+// undo takes back the last move. Against the AI, it also takes back the moves
+// of the AI since the last move of the player so that the player is to move
+// again.
+func (m *model) undo() {
+	if m.isAITurn() {
+		return
+	}
+
+	player := engine.P1
+	if m.ai == nil {
+		player = m.engine.GetOpponent(m.record.Turn())
+	}
+
+	// The moves of the record were legal, they can be played again
+	if err := m.load(m.record.TakeBack(player)); err != nil {
+		panic(err)
+	}
+}
+
+// load replays the moves of the record on an empty board.
+func (m *model) load(record engine.Record) error {
+	board, err := record.Replay(m.engine)
+	if err != nil {
+		return err
+	}
+
+	m.board = board
+	m.record = record
+	m.turn = record.Turn()
+	m.gameover = false
+	m.winner = engine.EMPTY
+
+	if n := len(record.Moves); n > 0 {
+		if over, win := m.engine.CheckGameOver(board, record.Moves[n-1]); over {
+			m.turn = m.engine.GetOpponent(m.turn)
+			m.gameover = true
+			if win != 0 {
+				m.winner = m.turn
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m *model) export() {
+	path, err := engine.SaveRecord(m.record, ColumnNotation{})
+	if err != nil {
+		m.saved = "game not saved: " + err.Error()
+		return
+	}
+
+	m.saved = "game saved to " + path
+}
+
+// aiMove searches the move of the AI on a copy of the board. The AI always
+// plays as engine.P1, so the pieces are swapped before the search.
+func (m model) aiMove() tea.Cmd {
+	board := m.board.Copy()
+	board.ChangePerspective()
+
+	return func() tea.Msg {
+		return aiMoveMsg{m.ai.Solve(board)}
+	}
+}
+
 func (m model) View() string {
 	s := "| 1 | 2 | 3 | 4 | 5 | 6 | 7 |\n"
 	s += "+---------------------------+\n"
 
-	for _, row := range m.board {
+	for row := 0; row < m.board.Rows; row++ {
 		s += "| "
-		for _, cell := range row {
-			style := m.oStyle
+		for col := 0; col < m.board.Cols; col++ {
+			cell, _ := m.board.GetCell(row*m.board.Cols + col)
 
-			if cell == 'x' {
-				style = m.xStyle
-			}
-
-			s += style.Render(string(cell)) + " | "
+			s += m.renderPlayer(cell) + " | "
 		}
 		s += "\n"
 	}
 
 	s += "+---------------------------+\n"
 
-	switch m.CheckForWin() {
-	case ' ':
-		s += fmt.Sprintf("\n%c's turn\n", m.turn)
-	case 't':
+	switch {
+	case !m.gameover:
+		s += fmt.Sprintf("\n%s's turn\n", m.renderPlayer(m.turn))
+	case m.winner == engine.EMPTY:
 		s += "\ntie!\n"
 	default:
-		s += fmt.Sprintf("\n%c wins!\n", m.CheckForWin())
+		s += fmt.Sprintf("\n%s wins!\n", m.renderPlayer(m.winner))
 	}
 
-	return s
-}
+	if len(m.record.Moves) > 0 {
+		s += "\n" + lipgloss.NewStyle().Width(29).Render(m.record.FormatMoves(ColumnNotation{})) + "\n"
+	}
 
-func (m model) CheckForWin() rune {
-	// Check for a win horizontally.
-	for y := 0; y < len(m.board[0]); y++ {
-		for x := 0; x < len(m.board)-3; x++ {
-			tile1 := m.board[x][y]
-			tile2 := m.board[x+1][y]
-			tile3 := m.board[x+2][y]
-			tile4 := m.board[x+3][y]
-			if tile1 == 'x' && tile2 == 'x' && tile3 == 'x' && tile4 == 'x' {
-				return 'x'
-			}
-			if tile1 == 'o' && tile2 == 'o' && tile3 == 'o' && tile4 == 'o' {
-				return 'o'
-			}
-		}
+	if m.saved != "" {
+		s += "\n" + m.saved + "\n"
 	}
 
-	// Check for a win vertically.
-	for y := 0; y < len(m.board[0])-3; y++ {
-		for x := 0; x < len(m.board); x++ {
-			tile1 := m.board[x][y]
-			tile2 := m.board[x][y+1]
-			tile3 := m.board[x][y+2]
-			tile4 := m.board[x][y+3]
-			if tile1 == 'x' && tile2 == 'x' && tile3 == 'x' && tile4 == 'x' {
-				return 'x'
-			}
-			if tile1 == 'o' && tile2 == 'o' && tile3 == 'o' && tile4 == 'o' {
-				return 'o'
-			}
-		}
+	return s + "\n1-7 to play, u to undo, e to export, q to quit\n"
+}
+
+func (m model) renderPlayer(player int) string {
+	switch player {
+	case engine.P1:
+		return m.xStyle.Render("x")
+	case engine.P2:
+		return m.oStyle.Render("o")
 	}
 
-	for y := 0; y < len(m.board[0])-3; y++ {
-		for x := 0; x < len(m.board)-3; x++ {
-			// Check left down diagonal.
-			tile1 := m.board[x][y]
-			tile2 := m.board[x+1][y+1]
-			tile3 := m.board[x+2][y+2]
-			tile4 := m.board[x+3][y+3]
-			if tile1 == 'x' && tile2 == 'x' && tile3 == 'x' && tile4 == 'x' {
-				return 'x'
-			}
-			if tile1 == 'o' && tile2 == 'o' && tile3 == 'o' && tile4 == 'o' {
-				return 'o'
-			}
+	return " "
+}
 
-			// Check right down diagonal.
-			tile1 = m.board[x+3][y]
-			tile2 = m.board[x+2][y+1]
-			tile3 = m.board[x+1][y+2]
-			tile4 = m.board[x][y+3]
-			if tile1 == 'x' && tile2 == 'x' && tile3 == 'x' && tile4 == 'x' {
-				return 'x'
-			}
-			if tile1 == 'o' && tile2 == 'o' && tile3 == 'o' && tile4 == 'o' {
-				return 'o'
-			}
-		}
+func Run() {
+	p := tea.NewProgram(selectModel(nil))
+
+	if _, err := p.Run(); err != nil {
+		panic(err)
 	}
+}
 
-	emptyCount := len(m.board) * len(m.board[0])
+func RunVsAi() {
+	algorithm := engine.NegamaxAlgorithm
+	strength := Medium
 
-	for _, row := range m.board {
-		for _, cell := range row {
-			if cell != ' ' {
-				emptyCount--
-			}
-		}
+	options := make([]huh.Option[engine.Algorithm], 0, len(engine.Algorithms))
+	for _, a := range engine.Algorithms {
+		options = append(options, huh.NewOption(a.String(), a))
 	}
 
-	if emptyCount <= 0 {
-		return 't'
+	err := huh.NewSelect[engine.Algorithm]().
+		Title("choose the AI:").
+		Options(options...).
+		Value(&algorithm).
+		Run()
+	if err != nil {
+		panic(err)
 	}
 
-	return ' '
-}
+	err = huh.NewSelect[Strength]().
+		Title("choose the AI strength:").
+		Options(
+			huh.NewOption("easy", Easy),
+			huh.NewOption("medium", Medium),
+			huh.NewOption("hard", Hard),
+		).
+		Value(&strength).
+		Run()
+	if err != nil {
+		panic(err)
+	}
 
-func Run() {
-	p := tea.NewProgram(initialModel())
+	p := tea.NewProgram(selectModel(strength.NewAI(algorithm)))
 
 	if _, err := p.Run(); err != nil {
 		panic(err)
 	}
 }
+
+// selectModel starts a new game or loads a saved one, against the AI or between
+// two players if it is nil.
+func selectModel(ai engine.AI) tea.Model {
+	record, err := engine.SelectRecord(recordGame, ColumnNotation{})
+	if err != nil {
+		panic(err)
+	}
+
+	if record == nil {
+		return initialModel(ai)
+	}
+
+	m, err := loadedModel(ai, *record)
+	if err != nil {
+		panic(err)
+	}
+
+	return m
+}
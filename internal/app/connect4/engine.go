@@ -0,0 +1,112 @@
+package connect4
+
+import (
+	"fmt"
+
+	"github.com/Kaamkiya/gg/internal/app/tictactoe/engine"
+)
+
+const (
+	rows      = 6
+	cols      = 7
+	winLength = 4
+)
+
+// Engine implements engine.GameEngine for Connect 4 so that the MCTS AI can
+// play it. Moves are column indices: a piece played in a column falls to the
+// lowest empty cell of it.
+type Engine struct{}
+
+func NewEngine() *Engine {
+	return &Engine{}
+}
+
+func NewBoard() *engine.Board {
+	return engine.NewRectBoard(rows, cols)
+}
+
+// GetLegalMoves returns the columns which are not full, from the center to the
+// sides because central columns take part in more lines and are searched first.
+func (e *Engine) GetLegalMoves(board *engine.Board) []int {
+	var moves []int
+	center := board.Cols / 2
+	for i := 0; i < board.Cols; i++ {
+		// Alternate right and left of the center: 3, 4, 2, 5, 1, 6, 0
+		col := center - i/2
+		if i%2 == 1 {
+			col = center + (i+1)/2
+		}
+
+		if board.Cells[col] == engine.EMPTY {
+			moves = append(moves, col)
+		}
+	}
+	return moves
+}
+
+// PlayMove drops a piece of the player in the column.
+func (e *Engine) PlayMove(board *engine.Board, player int, move int) error {
+	if move < 0 || move >= board.Cols {
+		return fmt.Errorf("invalid column: %d", move)
+	}
+
+	for row := board.Rows - 1; row >= 0; row-- {
+		index := row*board.Cols + move
+		if board.Cells[index] == engine.EMPTY {
+			return board.SetCell(index, player)
+		}
+	}
+
+	return fmt.Errorf("column %d is full", move+1)
+}
+
+func (e *Engine) GetOpponent(player int) int {
+	return -player
+}
+
+func (e *Engine) CheckGameOver(board *engine.Board, lastMove int) (bool, int) {
+	if lastMove == -1 {
+		return false, 0
+	}
+
+	if e.CheckWin(board, lastMove) {
+		absValue := engine.P1 * engine.P2 * -1
+		return true, absValue
+	}
+
+	if len(e.GetLegalMoves(board)) == 0 {
+		return true, 0
+	}
+
+	return false, 0
+}
+
+// Evaluate scores the lines of 4 which only one player can still complete, for
+// negamax searches stopping before the end of the game.
+func (e *Engine) Evaluate(board *engine.Board) int {
+	return engine.EvaluateLines(board, winLength)
+}
+
+// CheckWin checks if the last piece played in the column is part of 4 in a row.
+// The last piece played in a column is always the highest one.
+func (e *Engine) CheckWin(board *engine.Board, column int) bool {
+	index := topIndex(board, column)
+	if index == -1 {
+		return false
+	}
+
+	return engine.HasLine(board, index, winLength)
+}
+
+// topIndex returns the cell index of the highest piece in the column, or -1 if
+// the column is empty.
+func topIndex(board *engine.Board, column int) int {
+	for row := 0; row < board.Rows; row++ {
+		index := row*board.Cols + column
+		if board.Cells[index] != engine.EMPTY {
+			return index
+		}
+	}
+
+	return -1
+}
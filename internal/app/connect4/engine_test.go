@@ -0,0 +1,186 @@
+package connect4
+
+import (
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/app/tictactoe/engine"
+)
+
+// play drops pieces in the columns, alternating between the players.
+func play(t *testing.T, e *Engine, board *engine.Board, columns ...int) {
+	t.Helper()
+
+	player := engine.P1
+	for _, column := range columns {
+		if err := e.PlayMove(board, player, column); err != nil {
+			t.Fatal(err)
+		}
+		player = e.GetOpponent(player)
+	}
+}
+
+func TestEngine_PiecesFallToTheBottom(t *testing.T) {
+	e := NewEngine()
+	board := NewBoard()
+
+	play(t, e, board, 3, 3)
+
+	if board.Cells[5*cols+3] != engine.P1 || board.Cells[4*cols+3] != engine.P2 {
+		t.Fatal("Pieces should stack from the bottom of the column")
+	}
+}
+
+func TestEngine_FullColumnIsNotLegal(t *testing.T) {
+	e := NewEngine()
+	board := NewBoard()
+
+	play(t, e, board, 0, 0, 0, 0, 0, 0)
+
+	if err := e.PlayMove(board, engine.P1, 0); err == nil {
+		t.Fatal("Playing in a full column should fail")
+	}
+
+	for _, move := range e.GetLegalMoves(board) {
+		if move == 0 {
+			t.Fatal("A full column should not be a legal move")
+		}
+	}
+}
+
+func TestEngine_CheckGameOver(t *testing.T) {
+	testCases := []struct {
+		name    string
+		columns []int
+		over    bool
+	}{
+		{"horizontal", []int{0, 0, 1, 1, 2, 2, 3}, true},
+		{"vertical", []int{0, 1, 0, 1, 0, 1, 0}, true},
+		{"diagonal", []int{0, 1, 1, 2, 2, 3, 2, 3, 3, 6, 3}, true},
+		{"anti diagonal", []int{6, 5, 5, 4, 4, 3, 4, 3, 3, 0, 3}, true},
+		{"three in a row", []int{0, 0, 1, 1, 2, 2}, false},
+	}
+
+	for _, tc := range testCases {
+		e := NewEngine()
+		board := NewBoard()
+		play(t, e, board, tc.columns...)
+
+		over, _ := e.CheckGameOver(board, tc.columns[len(tc.columns)-1])
+		if over != tc.over {
+			t.Errorf("%s: expected game over to be %v", tc.name, tc.over)
+		}
+	}
+}
+
+func TestEngine_AITakesTheWinningColumn(t *testing.T) {
+	e := NewEngine()
+	board := NewBoard()
+
+	// P1 has three in a row at the bottom and plays next.
+	play(t, e, board, 1, 1, 2, 2, 3, 3)
+
+	if move := engine.NewMCTS(e, 1000).Solve(board); move != 0 && move != 4 {
+		t.Fatalf("Expected the AI to win in column 0 or 4 but got %d", move)
+	}
+}
+
+func TestEngine_AIBlocksTheOpponent(t *testing.T) {
+	e := NewEngine()
+	board := NewBoard()
+
+	// P2 threatens to win vertically in column 6 and P1 plays next.
+	play(t, e, board, 0, 6, 1, 6, 0, 6)
+
+	if move := engine.NewMCTS(e, 1000).Solve(board); move != 6 {
+		t.Fatalf("Expected the AI to block column 6 but got %d", move)
+	}
+}
+
+func TestEngine_NegamaxTakesTheWinningColumn(t *testing.T) {
+	e := NewEngine()
+	board := NewBoard()
+
+	play(t, e, board, 1, 1, 2, 2, 3, 3)
+
+	if move := Medium.NewAI(engine.NegamaxAlgorithm).Solve(board); move != 0 && move != 4 {
+		t.Fatalf("Expected negamax to win in column 0 or 4 but got %d", move)
+	}
+}
+
+func TestEngine_NegamaxAvoidsATrap(t *testing.T) {
+	e := NewEngine()
+	board := NewBoard()
+
+	// P2 has two open pieces at the bottom: unless P1 plays next to them, P2
+	// gets three in a row open on both sides and wins.
+	play(t, e, board, 6, 2, 6, 3)
+
+	switch move := Medium.NewAI(engine.NegamaxAlgorithm).Solve(board); move {
+	case 1, 4:
+	default:
+		t.Fatalf("Expected negamax to play next to the pieces of P2 but got %d", move)
+	}
+}
+
+// Benchmark the AIs against each other: the search of negamax beats the random
+// playouts of MCTS most of the time.
+func TestEngine_NegamaxBeatsMCTS(t *testing.T) {
+	e := NewEngine()
+	results := map[int]int{}
+
+	for range 5 {
+		mcts := Easy.NewAI(engine.MCTSAlgorithm)
+		negamax := Medium.NewAI(engine.NegamaxAlgorithm)
+
+		results[engine.PlayMatch(e, NewBoard(), negamax, mcts)]++
+		results[-engine.PlayMatch(e, NewBoard(), mcts, negamax)]++
+	}
+
+	t.Logf("negamax won %d games, mcts %d and %d were draws", results[engine.P1], results[engine.P2], results[0])
+
+	if results[engine.P1] <= 5 {
+		t.Errorf("Expected negamax to win most of the 10 games, won %d", results[engine.P1])
+	}
+}
+
+func TestColumnNotation(t *testing.T) {
+	board := NewBoard()
+	notation := ColumnNotation{}
+
+	for col := range cols {
+		parsed, err := notation.ParseMove(board, notation.FormatMove(board, col))
+		if err != nil || parsed != col {
+			t.Errorf("%d: parsed as %d (%v)", col, parsed, err)
+		}
+	}
+
+	for _, text := range []string{"0", "8", "a"} {
+		if _, err := notation.ParseMove(board, text); err == nil {
+			t.Errorf("%q: expected an error", text)
+		}
+	}
+}
+
+func TestModel_UndoAndLoad(t *testing.T) {
+	m := initialModel(nil)
+
+	// x wins in the first column.
+	for _, col := range []int{0, 1, 0, 1, 0, 1, 0} {
+		updated, _ := m.play(col)
+		m = updated.(model)
+	}
+
+	if !m.gameover || m.winner != engine.P1 {
+		t.Fatal("expected x to win")
+	}
+
+	loaded, err := loadedModel(nil, m.record)
+	if err != nil || !loaded.gameover || loaded.winner != engine.P1 {
+		t.Fatalf("expected the loaded game to be won by x (%v)", err)
+	}
+
+	m.undo()
+	if m.gameover || m.turn != engine.P1 || len(m.record.Moves) != 6 {
+		t.Errorf("expected x to play again, got %v", m.record.Moves)
+	}
+}
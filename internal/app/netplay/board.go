@@ -0,0 +1,301 @@
+package netplay
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Kaamkiya/gg/internal/app/connect4"
+	"github.com/Kaamkiya/gg/internal/app/tictactoe/engine"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// boardGame plays the games of the tictactoe engine. The first seat plays
+// engine.P1 and the second one engine.P2, and the players take turns to start
+// the games. The state is the first player and the moves in the notation of
+// the game, which the clients play again on their board:
+//
+//	P2 1. b2 a1 2. c3
+type boardGame struct {
+	name     string
+	rules    engine.GameEngine
+	notation engine.Notation
+	rows     int
+	cols     int
+	symbols  [2]string
+	// columns tells that the moves are columns, like in connect 4, and not
+	// cells.
+	columns bool
+
+	record engine.Record
+	board  *engine.Board
+	over   bool
+	winner engine.Player
+	cursor int
+}
+
+var cursorStyle = lipgloss.NewStyle().Reverse(true)
+
+func newVariantGame(name string, variant engine.Variant) *boardGame {
+	return newBoardGame(&boardGame{
+		name:     name,
+		rules:    engine.NewEngineWithWinLength(0, variant.WinLength),
+		notation: engine.CellNotation{},
+		rows:     variant.Rows,
+		cols:     variant.Cols,
+		symbols:  [2]string{"O", "X"},
+	})
+}
+
+func newConnect4Game() *boardGame {
+	board := connect4.NewBoard()
+
+	return newBoardGame(&boardGame{
+		name:     "connect4",
+		rules:    connect4.NewEngine(),
+		notation: connect4.ColumnNotation{},
+		rows:     board.Rows,
+		cols:     board.Cols,
+		symbols:  [2]string{"x", "o"},
+		columns:  true,
+	})
+}
+
+func newBoardGame(game *boardGame) *boardGame {
+	game.board = engine.NewRectBoard(game.rows, game.cols)
+	game.record = engine.NewRecord(game.name, game.board, engine.P1)
+	game.cursor = len(game.board.Cells) / 2
+	if game.columns {
+		game.cursor = game.cols / 2
+	}
+
+	return game
+}
+
+func (g *boardGame) Name() string {
+	return g.name
+}
+
+func (g *boardGame) Seats() int {
+	return 2
+}
+
+func seatPlayer(seat int) engine.Player {
+	if seat == 0 {
+		return engine.P1
+	}
+
+	return engine.P2
+}
+
+func (g *boardGame) Play(seat int, text string) error {
+	if g.over {
+		return errors.New("the game is over")
+	}
+
+	if seatPlayer(seat) != g.record.Turn() {
+		return errors.New("it is not your turn")
+	}
+
+	move, err := g.notation.ParseMove(g.board, text)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(g.rules.GetLegalMoves(g.board), move) {
+		return fmt.Errorf("%s cannot be played", text)
+	}
+
+	if err := g.rules.PlayMove(g.board, g.record.Turn(), move); err != nil {
+		return err
+	}
+
+	g.record.Moves = append(g.record.Moves, move)
+	g.checkGameOver()
+
+	return nil
+}
+
+func (g *boardGame) checkGameOver() {
+	g.over = false
+	g.winner = engine.EMPTY
+
+	n := len(g.record.Moves)
+	if n == 0 {
+		return
+	}
+
+	over, win := g.rules.CheckGameOver(g.board, g.record.Moves[n-1])
+	g.over = over
+	if win != 0 {
+		// The winner played the last move
+		g.winner = g.rules.GetOpponent(g.record.Turn())
+	}
+}
+
+func (g *boardGame) Over() bool {
+	return g.over
+}
+
+// Reset starts a new game, started by the player who did not start the last
+// one.
+func (g *boardGame) Reset() {
+	first := g.rules.GetOpponent(g.record.First)
+
+	g.board = engine.NewRectBoard(g.rows, g.cols)
+	g.record = engine.NewRecord(g.name, g.board, first)
+	g.over = false
+	g.winner = engine.EMPTY
+}
+
+func (g *boardGame) State() string {
+	first := "P1"
+	if g.record.First == engine.P2 {
+		first = "P2"
+	}
+
+	return strings.TrimSpace(first + " " + g.record.FormatMoves(g.notation))
+}
+
+func (g *boardGame) Load(state string) error {
+	fields := strings.Fields(state)
+	if len(fields) == 0 {
+		return errors.New("empty state")
+	}
+
+	record := engine.NewRecord(g.name, engine.NewRectBoard(g.rows, g.cols), engine.P1)
+
+	switch fields[0] {
+	case "P1":
+	case "P2":
+		record.First = engine.P2
+	default:
+		return fmt.Errorf("invalid first player %q", fields[0])
+	}
+
+	for _, field := range fields[1:] {
+		// Skip the move numbers
+		if strings.HasSuffix(field, ".") {
+			continue
+		}
+
+		move, err := g.notation.ParseMove(g.board, field)
+		if err != nil {
+			return err
+		}
+		record.Moves = append(record.Moves, move)
+	}
+
+	board, err := record.Replay(g.rules)
+	if err != nil {
+		return err
+	}
+
+	g.board = board
+	g.record = record
+	g.checkGameOver()
+
+	return nil
+}
+
+func (g *boardGame) View(seat int) string {
+	s := ""
+
+	if g.columns {
+		for col := range g.cols {
+			number := strconv.Itoa(col + 1)
+			if col == g.cursor {
+				number = cursorStyle.Render(number)
+			}
+			s += " " + number
+		}
+		s += "\n"
+	}
+
+	for row := range g.rows {
+		for col := range g.cols {
+			index := row*g.cols + col
+			cell := "·"
+			switch g.board.Cells[index] {
+			case engine.P1:
+				cell = g.symbols[0]
+			case engine.P2:
+				cell = g.symbols[1]
+			}
+
+			if !g.columns && index == g.cursor && !g.over {
+				cell = cursorStyle.Render(cell)
+			}
+			s += " " + cell
+		}
+		s += "\n"
+	}
+
+	switch {
+	case g.over && g.winner == engine.EMPTY:
+		s += "\ndraw!"
+	case g.over:
+		s += fmt.Sprintf("\n%s wins!", g.playerSymbol(g.winner))
+	case seat >= 0 && seatPlayer(seat) == g.record.Turn():
+		s += fmt.Sprintf("\nyour turn, you play %s", g.playerSymbol(g.record.Turn()))
+	default:
+		s += fmt.Sprintf("\n%s's turn", g.playerSymbol(g.record.Turn()))
+	}
+
+	if len(g.record.Moves) > 0 {
+		s += "\n" + lipgloss.NewStyle().Width(40).Render(g.record.FormatMoves(g.notation))
+	}
+
+	return s
+}
+
+func (g *boardGame) playerSymbol(player engine.Player) string {
+	if player == engine.P1 {
+		return g.symbols[0]
+	}
+
+	return g.symbols[1]
+}
+
+// Key moves the cursor with the arrows and plays its cell or column with enter.
+// Number keys play the columns, or the cells of boards of at most 9 cells.
+func (g *boardGame) Key(key string) (string, bool) {
+	switch key {
+	case "up", "k":
+		g.moveCursor(-1, 0)
+	case "down", "j":
+		g.moveCursor(1, 0)
+	case "left", "h":
+		g.moveCursor(0, -1)
+	case "right", "l":
+		g.moveCursor(0, 1)
+	case "enter", " ":
+		return g.notation.FormatMove(g.board, g.cursor), true
+	default:
+		n, err := strconv.Atoi(key)
+		if err != nil || n < 1 {
+			return "", false
+		}
+
+		if g.columns && n <= g.cols || !g.columns && n <= len(g.board.Cells) && len(g.board.Cells) <= 9 {
+			return g.notation.FormatMove(g.board, n-1), true
+		}
+	}
+
+	return "", false
+}
+
+// moveCursor moves the cursor on the cells, or on the columns.
+func (g *boardGame) moveCursor(dRow, dCol int) {
+	if g.columns {
+		g.cursor = min(max(g.cursor+dCol, 0), g.cols-1)
+		return
+	}
+
+	row, col, _ := g.board.GetRowCol(g.cursor)
+	if index, err := g.board.GetIndex(row+dRow, col+dCol); err == nil {
+		g.cursor = index
+	}
+}
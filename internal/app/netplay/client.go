@@ -0,0 +1,298 @@
+package netplay
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// reconnectDelay is the time between two attempts to join the game again
+	// after the connection was lost.
+	reconnectDelay = time.Second
+	// reconnectAttempts is the number of attempts before giving up.
+	reconnectAttempts = 30
+)
+
+// Client is the connection of a player or a spectator to the host. Its game is
+// a copy of the game of the host, updated with the states it sends.
+type Client struct {
+	addr     string
+	spectate bool
+	conn     net.Conn
+	game     Game
+	seat     int
+	token    string
+	messages chan tea.Msg
+}
+
+// Dial joins the game of the host at the address, as a player or a spectator.
+func Dial(addr string, spectate bool) (*Client, error) {
+	return dial(addr, spectate, "")
+}
+
+// dial joins the game with the token of a seat, if it has one.
+func dial(addr string, spectate bool, token string) (*Client, error) {
+	c := &Client{addr: addr, spectate: spectate, token: token, messages: make(chan tea.Msg)}
+
+	if err := c.accept(connect(addr, spectate, token)); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// connect opens a connection to the host and joins the game, with the token of
+// the seat of the client if it already had one. It does not change the client,
+// which is only updated by accept, so that it can run in a command while the
+// model reads the client.
+func connect(addr string, spectate bool, token string) reconnectMsg {
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		return reconnectMsg{err: err}
+	}
+
+	scanner := bufio.NewScanner(conn)
+	if !scanner.Scan() || scanner.Text() != protocolHeader {
+		conn.Close()
+		return reconnectMsg{err: fmt.Errorf("%s does not host a game of gg", addr)}
+	}
+
+	join := rolePlayer
+	if spectate {
+		join = roleSpectator
+	} else if token != "" {
+		join += " " + token
+	}
+
+	if err := writeMessage(conn, kindJoin, "%s", join); err != nil {
+		conn.Close()
+		return reconnectMsg{err: err}
+	}
+
+	if !scanner.Scan() {
+		conn.Close()
+		return reconnectMsg{err: errors.New("the host closed the connection")}
+	}
+
+	welcome := parseMessage(scanner.Text())
+	if welcome.kind != kindWelcome {
+		conn.Close()
+		return reconnectMsg{err: fmt.Errorf("the host refused to let us join: %s", welcome.text)}
+	}
+
+	return reconnectMsg{conn: conn, scanner: scanner, welcome: welcome.text}
+}
+
+// accept makes the connection opened by connect the one of the client, and
+// starts reading the messages of the host.
+func (c *Client) accept(msg reconnectMsg) error {
+	if msg.err != nil {
+		return msg.err
+	}
+
+	if err := c.welcome(msg.welcome); err != nil {
+		msg.conn.Close()
+		return err
+	}
+
+	c.conn = msg.conn
+	go c.read(msg.scanner)
+
+	return nil
+}
+
+// welcome reads the game, the seat and the token given by the host.
+func (c *Client) welcome(text string) error {
+	fields := strings.Fields(text)
+	if len(fields) != 3 {
+		return fmt.Errorf("invalid welcome %q", text)
+	}
+
+	seat, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return fmt.Errorf("invalid seat in welcome %q", text)
+	}
+
+	if c.game == nil {
+		c.game, err = newGame(fields[0])
+		if err != nil {
+			return err
+		}
+	} else if c.game.Name() != fields[0] {
+		return fmt.Errorf("the host now plays %s", fields[0])
+	}
+
+	c.seat = seat
+	c.token = fields[2]
+
+	return nil
+}
+
+// read passes the messages of the host to the client until the connection is
+// lost.
+func (c *Client) read(scanner *bufio.Scanner) {
+	for scanner.Scan() {
+		c.messages <- hostMsg(parseMessage(scanner.Text()))
+	}
+
+	c.messages <- disconnectedMsg{}
+}
+
+func (c *Client) send(kind string, text string) {
+	// A lost connection is noticed by read
+	writeMessage(c.conn, kind, "%s", text)
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+type hostMsg message
+type disconnectedMsg struct{}
+
+// reconnectMsg is the connection opened again to the host, or why it could
+// not be.
+type reconnectMsg struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+	welcome string
+	err     error
+}
+
+// clientModel shows the game of the client and sends the moves of the player
+// to the host.
+type clientModel struct {
+	client    *Client
+	status    string
+	err       string
+	attempts  int
+	lost      bool
+	infoStyle lipgloss.Style
+	errStyle  lipgloss.Style
+}
+
+func newClientModel(client *Client) clientModel {
+	return clientModel{
+		client:    client,
+		infoStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("#7E9CD8")),
+		errStyle:  lipgloss.NewStyle().Foreground(lipgloss.Color("#E63D3D")),
+	}
+}
+
+func (m clientModel) Init() tea.Cmd {
+	return m.waitForHost()
+}
+
+func (m clientModel) waitForHost() tea.Cmd {
+	messages := m.client.messages
+
+	return func() tea.Msg {
+		return <-messages
+	}
+}
+
+func (m clientModel) reconnect() tea.Cmd {
+	addr, spectate, token := m.client.addr, m.client.spectate, m.client.token
+
+	return tea.Tick(reconnectDelay, func(time.Time) tea.Msg {
+		return connect(addr, spectate, token)
+	})
+}
+
+func (m clientModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case hostMsg:
+		return m.handleHost(message(msg))
+
+	case disconnectedMsg:
+		m.lost = true
+		m.status = "connection lost, joining again..."
+		return m, m.reconnect()
+
+	case reconnectMsg:
+		err := m.client.accept(msg)
+		if err == nil {
+			m.lost = false
+			m.attempts = 0
+			m.err = ""
+			return m, m.waitForHost()
+		}
+
+		m.attempts += 1
+		if m.attempts >= reconnectAttempts {
+			m.err = "could not join the game again: " + err.Error()
+			return m, tea.Quit
+		}
+
+		return m, m.reconnect()
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q":
+			return m, tea.Quit
+		}
+
+		if m.client.seat < 0 || m.lost {
+			return m, nil
+		}
+
+		if msg.String() == "n" && m.client.game.Over() {
+			m.client.send(kindNew, "")
+			return m, nil
+		}
+
+		if move, ok := m.client.game.Key(msg.String()); ok {
+			m.err = ""
+			m.client.send(kindMove, move)
+		}
+	}
+
+	return m, nil
+}
+
+func (m clientModel) handleHost(msg message) (tea.Model, tea.Cmd) {
+	switch msg.kind {
+	case kindState:
+		if err := m.client.game.Load(msg.text); err != nil {
+			m.err = "invalid state from the host: " + err.Error()
+		}
+	case kindInfo:
+		m.status = msg.text
+	case kindError:
+		m.err = msg.text
+	}
+
+	return m, m.waitForHost()
+}
+
+func (m clientModel) View() string {
+	s := m.client.game.View(m.client.seat) + "\n\n"
+
+	if m.client.seat < 0 {
+		s += m.infoStyle.Render("you are watching the game") + "\n"
+	} else {
+		s += m.infoStyle.Render(fmt.Sprintf("you are player %d", m.client.seat+1)) + "\n"
+	}
+
+	if m.status != "" {
+		s += m.infoStyle.Render(m.status) + "\n"
+	}
+
+	if m.err != "" {
+		s += m.errStyle.Render(m.err) + "\n"
+	}
+
+	if m.client.game.Over() && m.client.seat >= 0 {
+		return s + m.infoStyle.Render("n for a new game, q to quit")
+	}
+
+	return s + m.infoStyle.Render("q to quit")
+}
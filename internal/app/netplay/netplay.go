@@ -0,0 +1,160 @@
+// Package netplay plays the turn-based games between players on different
+// computers. The host holds the game and checks the moves the players send
+// over TCP, then sends the new state of the game to the players and the
+// spectators, whose copy of the game only shows it.
+package netplay
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/Kaamkiya/gg/internal/app/blackjack"
+	"github.com/Kaamkiya/gg/internal/app/tictactoe/engine"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Game is a turn-based game played over the network. The host applies the
+// moves of the players to its game and sends its state to the clients, which
+// load it into their own copy to show it.
+type Game interface {
+	Name() string
+	// Seats is the number of players.
+	Seats() int
+	// Play plays the move of the player of the seat, numbered from 0. It fails
+	// if the move is illegal or if it is not the turn of the player.
+	Play(seat int, move string) error
+	// Over tells if the game is over, a new one can then be started with Reset.
+	Over() bool
+	Reset()
+	// State encodes the game in one line, without the secrets of the host like
+	// the order of the cards of a deck.
+	State() string
+	Load(state string) error
+	// View shows the game to the player of the seat, or to a spectator for a
+	// seat of -1.
+	View(seat int) string
+	// Key converts a key pressed by a player to a move, and returns false for
+	// keys which do not play.
+	Key(key string) (string, bool)
+}
+
+var games = []func() Game{
+	func() Game { return newVariantGame("tictactoe", engine.TicTacToe) },
+	func() Game { return newVariantGame("4x4", engine.FourByFour) },
+	func() Game { return newVariantGame("gomoku", engine.Gomoku) },
+	func() Game { return newConnect4Game() },
+	func() Game { return blackjack.NewNetGame() },
+}
+
+func newGame(name string) (Game, error) {
+	for _, newGame := range games {
+		if game := newGame(); game.Name() == name {
+			return game, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown game %q, expected one of %s", name, strings.Join(gameNames(), ", "))
+}
+
+func gameNames() []string {
+	names := []string{}
+	for _, newGame := range games {
+		names = append(names, newGame().Name())
+	}
+
+	return names
+}
+
+// Host runs the host command: gg host <game> --port <port>. It serves the game
+// and lets the player on the host play it as the first player.
+func Host(args []string) error {
+	flags := flag.NewFlagSet("host", flag.ContinueOnError)
+	port := flags.Int("port", 7777, "TCP port to listen on")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: gg host <game> [flags]")
+		fmt.Fprintln(flags.Output(), "games:", strings.Join(gameNames(), ", "))
+		flags.PrintDefaults()
+	}
+
+	// The game comes first but the flag package stops at the first argument.
+	name := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if name == "" {
+		name = flags.Arg(0)
+	}
+
+	game, err := newGame(name)
+	if err != nil {
+		return err
+	}
+
+	// The first seat is kept for the player on the host before anyone joins
+	server := NewServer(game)
+	token := server.reserve(0)
+
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(*port))
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	go server.Serve(listener)
+
+	return play("localhost:"+strconv.Itoa(*port), false, token)
+}
+
+// Join runs the join command: gg join <host:port> [--spectate].
+func Join(args []string) error {
+	flags := flag.NewFlagSet("join", flag.ContinueOnError)
+	spectate := flags.Bool("spectate", false, "watch the game without playing")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: gg join <host:port> [flags]")
+		flags.PrintDefaults()
+	}
+
+	// The address comes first but the flag package stops at the first argument.
+	addr := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		addr, args = args[0], args[1:]
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if addr == "" {
+		addr = flags.Arg(0)
+	}
+
+	if addr == "" {
+		flags.Usage()
+		return fmt.Errorf("missing the address of the host")
+	}
+
+	return play(addr, *spectate, "")
+}
+
+// play joins the game as a player or a spectator, with the token of a seat if
+// it was reserved, and shows it until the player quits.
+func play(addr string, spectate bool, token string) error {
+	client, err := dial(addr, spectate, token)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	p := tea.NewProgram(newClientModel(client))
+
+	_, err = p.Run()
+	return err
+}
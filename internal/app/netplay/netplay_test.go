@@ -0,0 +1,268 @@
+package netplay
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/app/tictactoe/engine"
+)
+
+// testClient speaks the protocol over a raw connection.
+type testClient struct {
+	t       *testing.T
+	conn    net.Conn
+	scanner *bufio.Scanner
+}
+
+func serve(t *testing.T, game Game) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go NewServer(game).Serve(listener)
+
+	return listener.Addr().String()
+}
+
+// join connects to the host and sends the join message, the host greeting is
+// checked and skipped.
+func join(t *testing.T, addr string, role string) *testClient {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	c := &testClient{t, conn, bufio.NewScanner(conn)}
+	if line := c.read(); line != protocolHeader {
+		t.Fatalf("expected the protocol header, got %q", line)
+	}
+
+	writeMessage(conn, kindJoin, "%s", role)
+
+	return c
+}
+
+func (c *testClient) read() string {
+	c.t.Helper()
+
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if !c.scanner.Scan() {
+		c.t.Fatalf("connection closed: %v", c.scanner.Err())
+	}
+
+	return c.scanner.Text()
+}
+
+// expect reads messages until one of the kind, skipping the others, and returns
+// its text.
+func (c *testClient) expect(kind string) string {
+	c.t.Helper()
+
+	for {
+		msg := parseMessage(c.read())
+		if msg.kind == kindError && kind != kindError {
+			c.t.Fatalf("expected %s, got an error: %s", kind, msg.text)
+		}
+
+		if msg.kind == kind {
+			return msg.text
+		}
+	}
+}
+
+func (c *testClient) send(kind string, text string) {
+	writeMessage(c.conn, kind, "%s", text)
+}
+
+func TestServer_PlaysTheMovesOfThePlayers(t *testing.T) {
+	addr := serve(t, newConnect4Game())
+
+	p1 := join(t, addr, rolePlayer)
+	welcome := strings.Fields(p1.expect(kindWelcome))
+	if welcome[0] != "connect4" || welcome[1] != "0" {
+		t.Fatalf("expected the first seat of connect4, got %v", welcome)
+	}
+
+	// The players wait for each other.
+	p1.send(kindMove, "4")
+	if text := p1.expect(kindError); !strings.Contains(text, "waiting") {
+		t.Errorf("expected to wait for the second player, got %q", text)
+	}
+
+	p2 := join(t, addr, rolePlayer)
+	p2.expect(kindWelcome)
+	p2.expect(kindState)
+
+	spectator := join(t, addr, roleSpectator)
+	if welcome := spectator.expect(kindWelcome); welcome != "connect4 -1 -" {
+		t.Errorf("expected to watch the game, got %q", welcome)
+	}
+	spectator.expect(kindState)
+
+	p2.send(kindMove, "4")
+	if text := p2.expect(kindError); text != "it is not your turn" {
+		t.Errorf("expected to wait for the first player, got %q", text)
+	}
+
+	p1.send(kindMove, "9")
+	p1.expect(kindError)
+
+	p1.send(kindMove, "4")
+	for _, c := range []*testClient{p1, p2, spectator} {
+		if state := c.expect(kindState); state != "P1 1. 4" {
+			t.Errorf("expected the move to be played, got %q", state)
+		}
+	}
+
+	spectator.send(kindMove, "3")
+	if text := spectator.expect(kindError); text != "spectators cannot play" {
+		t.Errorf("expected the spectator not to play, got %q", text)
+	}
+
+	if text := join(t, addr, rolePlayer).expect(kindError); !strings.Contains(text, "full") {
+		t.Errorf("expected the game to be full, got %q", text)
+	}
+}
+
+func TestServer_PlayersJoinAgainWithTheirToken(t *testing.T) {
+	addr := serve(t, newVariantGame("tictactoe", engine.TicTacToe))
+
+	p1 := join(t, addr, rolePlayer)
+	token := strings.Fields(p1.expect(kindWelcome))[2]
+
+	p2 := join(t, addr, rolePlayer)
+	p2.expect(kindWelcome)
+
+	p1.send(kindMove, "b2")
+	p2.expect(kindState)
+	p2.expect(kindState)
+
+	p1.conn.Close()
+	if info := p2.expect(kindInfo); !strings.Contains(info, "player 1 left") {
+		t.Errorf("expected the first player to leave, got %q", info)
+	}
+
+	if text := join(t, addr, rolePlayer+" unknown").expect(kindError); !strings.Contains(text, "token") {
+		t.Errorf("expected an unknown token to be refused, got %q", text)
+	}
+
+	p1 = join(t, addr, rolePlayer+" "+token)
+	if welcome := p1.expect(kindWelcome); welcome != "tictactoe 0 "+token {
+		t.Errorf("expected the first seat back, got %q", welcome)
+	}
+
+	if state := p1.expect(kindState); state != "P1 1. b2" {
+		t.Errorf("expected the game to go on, got %q", state)
+	}
+
+	p2.send(kindMove, "a1")
+	if state := p1.expect(kindState); state != "P1 1. b2 a1" {
+		t.Errorf("expected the move of the second player, got %q", state)
+	}
+}
+
+func TestServer_ReservedSeatIsKeptForTheHost(t *testing.T) {
+	server := NewServer(newConnect4Game())
+	token := server.reserve(0)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go server.Serve(listener)
+	addr := listener.Addr().String()
+
+	if welcome := join(t, addr, rolePlayer).expect(kindWelcome); !strings.HasPrefix(welcome, "connect4 1 ") {
+		t.Errorf("expected the second seat for a remote player, got %q", welcome)
+	}
+
+	if welcome := join(t, addr, rolePlayer+" "+token).expect(kindWelcome); welcome != "connect4 0 "+token {
+		t.Errorf("expected the reserved seat for the host, got %q", welcome)
+	}
+}
+
+func TestClient_Dial(t *testing.T) {
+	addr := serve(t, newConnect4Game())
+
+	client, err := Dial(addr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if client.game.Name() != "connect4" || client.seat != 0 || client.token == "" {
+		t.Errorf("expected the first seat of connect4, got seat %d of %s", client.seat, client.game.Name())
+	}
+
+	if _, err := Dial(addr, true); err != nil {
+		t.Errorf("expected to watch the game, got %v", err)
+	}
+}
+
+func TestBoardGame_StateIsLoaded(t *testing.T) {
+	host := newVariantGame("tictactoe", engine.TicTacToe)
+	for i, move := range []string{"a1", "b1", "a2", "b2", "a3"} {
+		if err := host.Play(i%2, move); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !host.Over() || host.winner != engine.P1 {
+		t.Fatal("expected the first player to win")
+	}
+
+	client := newVariantGame("tictactoe", engine.TicTacToe)
+	if err := client.Load(host.State()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !client.Over() || client.State() != host.State() {
+		t.Errorf("expected %q, got %q", host.State(), client.State())
+	}
+
+	// The second player starts the next game.
+	host.Reset()
+	if host.State() != "P2" || host.Play(0, "b2") == nil {
+		t.Errorf("expected the second player to start, got %q", host.State())
+	}
+
+	for _, state := range []string{"", "P3", "P1 1. a1 a1", "P1 1. z9"} {
+		if err := client.Load(state); err == nil {
+			t.Errorf("%q: expected an error", state)
+		}
+	}
+}
+
+func TestBoardGame_Key(t *testing.T) {
+	game := newConnect4Game()
+
+	game.Key("left")
+	if move, ok := game.Key("enter"); !ok || move != "3" {
+		t.Errorf("expected the cursor to play the third column, got %q", move)
+	}
+
+	if move, ok := game.Key("7"); !ok || move != "7" {
+		t.Errorf("expected the seventh column, got %q", move)
+	}
+
+	if _, ok := game.Key("8"); ok {
+		t.Error("expected no eighth column")
+	}
+
+	game = newVariantGame("tictactoe", engine.TicTacToe)
+	if move, ok := game.Key("9"); !ok || move != "c3" {
+		t.Errorf("expected the last cell, got %q", move)
+	}
+}
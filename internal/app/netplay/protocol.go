@@ -0,0 +1,81 @@
+package netplay
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// protocolHeader is the first line the host sends and holds the protocol version
+const protocolHeader = "gg-netplay 1"
+
+// The protocol is made of lines of text, each holding a message: its kind and
+// its text, separated by a space. A session goes like this, > being sent by
+// the client and < by the host:
+//
+//	< gg-netplay 1
+//	> JOIN player
+//	< WELCOME connect4 1 5f2c9a0e7d41b836
+//	< STATE P1 1. 4
+//	< INFO waiting for the players to join
+//	> MOVE 3
+//	< STATE P1 1. 4 3
+//
+// A player who lost the connection joins again with the token of the welcome
+// message, JOIN player 5f2c9a0e7d41b836, to get their seat back.
+const (
+	// kindJoin is sent first by the clients, with the role: player, followed by
+	// the token of a seat to get it back, or spectator.
+	kindJoin = "JOIN"
+	// kindWelcome gives the game, the seat of the client, -1 for spectators,
+	// and the token of the seat.
+	kindWelcome = "WELCOME"
+	// kindMove plays a move for the player.
+	kindMove = "MOVE"
+	// kindNew starts a new game once the game is over.
+	kindNew = "NEW"
+	// kindState is the state of the game after a move.
+	kindState = "STATE"
+	// kindInfo tells the clients what happens, like players leaving.
+	kindInfo = "INFO"
+	// kindError tells a client that its message was refused.
+	kindError = "ERROR"
+)
+
+const (
+	rolePlayer    = "player"
+	roleSpectator = "spectator"
+)
+
+type message struct {
+	kind string
+	text string
+}
+
+func parseMessage(line string) message {
+	kind, text, _ := strings.Cut(strings.TrimRight(line, "\r"), " ")
+	return message{kind, text}
+}
+
+func (m message) String() string {
+	if m.text == "" {
+		return m.kind
+	}
+
+	return m.kind + " " + m.text
+}
+
+// formatMessage returns the line of the message, without its line break.
+func formatMessage(kind string, format string, args ...any) string {
+	text := fmt.Sprintf(format, args...)
+
+	// A new line would start another message
+	text = strings.ReplaceAll(text, "\n", " ")
+
+	return message{kind, text}.String()
+}
+
+func writeMessage(w io.Writer, kind string, format string, args ...any) error {
+	_, err := fmt.Fprintln(w, formatMessage(kind, format, args...))
+	return err
+}
@@ -0,0 +1,290 @@
+package netplay
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// writeTimeout drops the clients which do not read their messages.
+	writeTimeout = 5 * time.Second
+	// outboxSize is the number of messages waiting to be written to a client,
+	// which is dropped once it falls that far behind.
+	outboxSize = 64
+)
+
+// Server holds the game and the seats of its players. Moves are only accepted
+// once every seat was taken, and the seat of a player who left waits for them
+// to join again with its token.
+type Server struct {
+	mu      sync.Mutex
+	game    Game
+	seats   []seat
+	clients map[*client]bool
+}
+
+type seat struct {
+	token  string
+	client *client
+}
+
+// client is a connection to a player or a spectator. Its messages are queued
+// and written by its own goroutine, so that a slow client does not hold the
+// lock of the server.
+type client struct {
+	conn   net.Conn
+	seat   int
+	outbox chan string
+}
+
+func NewServer(game Game) *Server {
+	return &Server{
+		game:    game,
+		seats:   make([]seat, game.Seats()),
+		clients: map[*client]bool{},
+	}
+}
+
+// reserve keeps the seat for the player joining with the returned token, like
+// the player on the host, before anyone else can take it.
+func (s *Server) reserve(index int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seats[index].token = newToken()
+
+	return s.seats[index].token
+}
+
+// Serve handles the connections of the listener until it is closed.
+func (s *Server) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+
+			return err
+		}
+
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	if _, err := fmt.Fprintln(conn, protocolHeader); err != nil {
+		return
+	}
+
+	scanner := bufio.NewScanner(conn)
+	if !scanner.Scan() {
+		return
+	}
+
+	c, err := s.join(conn, parseMessage(scanner.Text()))
+	if err != nil {
+		writeMessage(conn, kindError, "%s", err)
+		return
+	}
+
+	// Nothing is sent to the client once it left
+	defer close(c.outbox)
+	defer s.leave(c)
+
+	go c.write()
+
+	for scanner.Scan() {
+		s.handleMessage(c, parseMessage(scanner.Text()))
+	}
+}
+
+// join gives the client a seat, the seat of the token if it has one, or lets
+// it watch the game.
+func (s *Server) join(conn net.Conn, msg message) (*client, error) {
+	if msg.kind != kindJoin {
+		return nil, fmt.Errorf("expected %s, got %s", kindJoin, msg.kind)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	role, token, _ := strings.Cut(msg.text, " ")
+	c := &client{conn: conn, seat: -1, outbox: make(chan string, outboxSize)}
+
+	switch role {
+	case roleSpectator:
+	case rolePlayer:
+		index, err := s.findSeat(token)
+		if err != nil {
+			return nil, err
+		}
+
+		seat := &s.seats[index]
+		if seat.client != nil {
+			// The player joined again before their old connection timed out
+			delete(s.clients, seat.client)
+			seat.client.conn.Close()
+		}
+
+		if seat.token == "" {
+			seat.token = newToken()
+		}
+
+		seat.client = c
+		c.seat = index
+	default:
+		return nil, fmt.Errorf("unknown role %q, expected %s or %s", role, rolePlayer, roleSpectator)
+	}
+
+	s.clients[c] = true
+
+	token = "-"
+	if c.seat >= 0 {
+		token = s.seats[c.seat].token
+	}
+
+	s.send(c, kindWelcome, "%s %d %s", s.game.Name(), c.seat, token)
+	s.send(c, kindState, "%s", s.game.State())
+
+	s.broadcastWaiting()
+
+	return c, nil
+}
+
+// findSeat returns the seat of the token, or the first seat nobody took if the
+// token is empty.
+func (s *Server) findSeat(token string) (int, error) {
+	for i, seat := range s.seats {
+		if token != "" && seat.token == token {
+			return i, nil
+		}
+	}
+
+	if token != "" {
+		return 0, errors.New("unknown token, the seat was not found")
+	}
+
+	for i, seat := range s.seats {
+		if seat.token == "" {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("the game is full, join as a %s to watch it", roleSpectator)
+}
+
+// leave frees the seat of the client for a new connection of the player.
+func (s *Server) leave(c *client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.clients[c] {
+		return
+	}
+	delete(s.clients, c)
+
+	if c.seat >= 0 && s.seats[c.seat].client == c {
+		s.seats[c.seat].client = nil
+		s.broadcast(kindInfo, "player %d left, waiting for them to join again", c.seat+1)
+	}
+}
+
+func (s *Server) handleMessage(c *client, msg message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch msg.kind {
+	case kindMove, kindNew:
+		if c.seat < 0 {
+			s.send(c, kindError, "spectators cannot play")
+			return
+		}
+
+		if !s.isFull() {
+			s.send(c, kindError, "waiting for the players to join")
+			return
+		}
+
+		if msg.kind == kindNew {
+			if !s.game.Over() {
+				s.send(c, kindError, "the game is not over")
+				return
+			}
+
+			s.game.Reset()
+		} else if err := s.game.Play(c.seat, msg.text); err != nil {
+			s.send(c, kindError, "%s", err)
+			return
+		}
+
+		s.broadcast(kindState, "%s", s.game.State())
+	default:
+		s.send(c, kindError, "unknown message %s", msg.kind)
+	}
+}
+
+func (s *Server) isFull() bool {
+	for _, seat := range s.seats {
+		if seat.client == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// broadcastWaiting tells the clients whether the game can be played. s.mu must
+// be held.
+func (s *Server) broadcastWaiting() {
+	if s.isFull() {
+		s.broadcast(kindInfo, "all the players joined")
+	} else {
+		s.broadcast(kindInfo, "waiting for the players to join")
+	}
+}
+
+// send queues the message for the client, and closes its connection if too
+// many are waiting already. s.mu must be held.
+func (s *Server) send(c *client, kind string, format string, args ...any) {
+	select {
+	case c.outbox <- formatMessage(kind, format, args...):
+	default:
+		c.conn.Close()
+	}
+}
+
+// broadcast sends the message to every client. s.mu must be held.
+func (s *Server) broadcast(kind string, format string, args ...any) {
+	for c := range s.clients {
+		s.send(c, kind, format, args...)
+	}
+}
+
+// write writes the messages queued for the client until it leaves, and closes
+// its connection if it does not read them in time.
+func (c *client) write() {
+	for line := range c.outbox {
+		c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+
+		if _, err := fmt.Fprintln(c.conn, line); err != nil {
+			c.conn.Close()
+		}
+	}
+}
+
+func newToken() string {
+	bytes := make([]byte, 8)
+	rand.Read(bytes)
+
+	return hex.EncodeToString(bytes)
+}
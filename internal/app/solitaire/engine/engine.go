@@ -0,0 +1,379 @@
+// Package engine implements the rules of Klondike solitaire without any user interface. The cards are dealt from a
+// seeded deck, so that a deal can be played again, and every move returns an error when the rules don't allow it. The
+// moves can be undone one by one.
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/Kaamkiya/gg/internal/app/cards"
+)
+
+const (
+	Foundations = 4 // Piles built up by suit from the ace to the king, one for each suit.
+	Columns     = 7 // Piles of the tableau, built down in alternating colors.
+)
+
+// -------------------- ENUM: PileKind --------------------
+
+// PileKind is where the cards of a pile are on the table.
+type PileKind int
+
+const (
+	// Stock holds the cards left to draw, face down.
+	Stock PileKind = iota
+	// Waste holds the cards drawn from the stock, the top one playable.
+	Waste
+	// Foundation holds the cards of a suit from the ace up.
+	Foundation
+	// Tableau is a column of cards, face down under the cards face up built down in alternating colors.
+	Tableau
+)
+
+// String returns the name of the kind of pile, e.g. "tableau".
+func (kind PileKind) String() string {
+	return [...]string{"stock", "waste", "foundation", "tableau"}[kind]
+}
+
+// Pile is a pile of the table: the stock, the waste, the foundation of the suit of the index or the column of the
+// tableau of the index.
+type Pile struct {
+	Kind  PileKind
+	Index int
+}
+
+// String describes the pile, e.g. "foundation ♥" or "tableau 3", counting the columns from 1.
+func (pile Pile) String() string {
+	switch {
+	case pile.Kind == Foundation && pile.valid():
+		return fmt.Sprintf("%s %s", pile.Kind, cards.Suits[pile.Index])
+	case pile.Kind == Foundation, pile.Kind == Tableau:
+		return fmt.Sprintf("%s %d", pile.Kind, pile.Index+1)
+	}
+	return pile.Kind.String()
+}
+
+// valid tells if the pile is on the table: the index of a foundation or a column is one of a pile of its kind.
+func (pile Pile) valid() bool {
+	switch pile.Kind {
+	case Stock, Waste:
+		return true
+	case Foundation:
+		return pile.Index >= 0 && pile.Index < Foundations
+	case Tableau:
+		return pile.Index >= 0 && pile.Index < Columns
+	}
+	return false
+}
+
+// ErrIllegal is returned for the moves the rules don't allow.
+var ErrIllegal = errors.New("illegal move")
+
+// -------------------- STRUCT: Game --------------------
+
+// state is what a move changes, saved before each move to undo it.
+type state struct {
+	stock       []cards.Card              // Cards left to draw, the top card last.
+	waste       []cards.Card              // Cards drawn, the top card last.
+	foundations [Foundations][]cards.Card // Foundation of each suit, the top card last.
+	tableau     [Columns][]cards.Card     // Cards of each column, the top card last.
+	hidden      [Columns]int              // Number of cards face down at the bottom of each column.
+	moves       int                       // Number of moves played.
+}
+
+// clone returns a copy of the state which doesn't share its piles.
+func (s state) clone() state {
+	s.stock = slices.Clone(s.stock)
+	s.waste = slices.Clone(s.waste)
+	for i := range s.foundations {
+		s.foundations[i] = slices.Clone(s.foundations[i])
+	}
+	for i := range s.tableau {
+		s.tableau[i] = slices.Clone(s.tableau[i])
+	}
+	return s
+}
+
+// Options of a game.
+type Options struct {
+	Draw     int  // Number of cards drawn from the stock at once: 1 or 3.
+	AutoMove bool // The cards which can't be needed on the tableau anymore go onto the foundations after each move.
+}
+
+// Game is a game of Klondike solitaire.
+type Game struct {
+	state
+	seed    int64   // Seed of the deck of the deal.
+	options Options // Options of the game.
+	history []state // States before each move, to undo them.
+}
+
+// New deals a game from a deck shuffled with the seed. The columns of the tableau get 1 to 7 cards, the top card
+// face up, and the other 24 cards are left in the stock.
+func New(seed int64, options Options) *Game {
+	deck := cards.NewSeededDeck(1, 0, seed)
+	deck.Shuffle()
+
+	if options.Draw != 3 {
+		options.Draw = 1
+	}
+	game := &Game{seed: seed, options: options}
+	for i := 0; i < Columns; i++ {
+		for j := i; j < Columns; j++ {
+			card, _ := deck.Deal()
+			game.tableau[j] = append(game.tableau[j], card)
+		}
+		game.hidden[i] = i
+	}
+	for deck.Len() > 0 {
+		card, _ := deck.Deal()
+		game.stock = append(game.stock, card)
+	}
+	return game
+}
+
+// Seed returns the seed of the deal.
+func (game *Game) Seed() int64 {
+	return game.seed
+}
+
+// Options returns the options of the game.
+func (game *Game) Options() Options {
+	return game.options
+}
+
+// Moves returns the number of moves played, the undone ones excluded.
+func (game *Game) Moves() int {
+	return game.moves
+}
+
+// Stock returns the number of cards left in the stock.
+func (game *Game) Stock() int {
+	return len(game.stock)
+}
+
+// Waste returns the cards drawn from the stock, the top card last.
+func (game *Game) Waste() []cards.Card {
+	return game.waste
+}
+
+// Foundation returns the cards of the foundation of the suit of the index, the top card last.
+func (game *Game) Foundation(index int) []cards.Card {
+	return game.foundations[index]
+}
+
+// Column returns the cards of the column of the tableau, the top card last, and the number of them face down.
+func (game *Game) Column(index int) ([]cards.Card, int) {
+	return game.tableau[index], game.hidden[index]
+}
+
+// Won tells if all the cards are on the foundations.
+func (game *Game) Won() bool {
+	for _, foundation := range game.foundations {
+		if len(foundation) < int(cards.King) {
+			return false
+		}
+	}
+	return true
+}
+
+// DrawStock turns the next cards of the stock onto the waste, or turns the waste back into the stock once the stock
+// is empty.
+func (game *Game) DrawStock() error {
+	if len(game.stock) == 0 && len(game.waste) == 0 {
+		return fmt.Errorf("%w: the stock and the waste are empty", ErrIllegal)
+	}
+
+	game.save()
+	game.drawStock()
+	game.autoMove()
+	return nil
+}
+
+// drawStock draws from the stock, or turns the waste back into the stock.
+func (game *Game) drawStock() {
+	if len(game.stock) == 0 {
+		game.stock = game.waste
+		slices.Reverse(game.stock)
+		game.waste = nil
+		return
+	}
+	for i := 0; i < game.options.Draw && len(game.stock) > 0; i++ {
+		game.waste = append(game.waste, game.stock[len(game.stock)-1])
+		game.stock = game.stock[:len(game.stock)-1]
+	}
+}
+
+// CanMove tells if the count cards on top of the pile can be moved onto the other pile, both being on the table. Only
+// the top card of the waste and of a foundation can be moved, and any number of cards face up of a column. A foundation
+// takes the next card of its suit, and a column takes a card one rank lower and of the other color than its top card,
+// or a king when empty.
+func (game *Game) CanMove(from Pile, count int, to Pile) bool {
+	moved := game.top(from, count)
+	if moved == nil || from == to || !to.valid() {
+		return false
+	}
+	if from.Kind == Tableau && count > len(game.tableau[from.Index])-game.hidden[from.Index] {
+		return false
+	}
+	if from.Kind != Tableau && count != 1 {
+		return false
+	}
+
+	card := moved[0]
+	switch to.Kind {
+	case Foundation:
+		foundation := game.foundations[to.Index]
+		return count == 1 && card.Suit == cards.Suits[to.Index] && int(card.Rank) == len(foundation)+1
+	case Tableau:
+		column := game.tableau[to.Index]
+		if len(column) == 0 {
+			return card.Rank == cards.King
+		}
+		top := column[len(column)-1]
+		return card.Rank == top.Rank-1 && card.Suit.Red() != top.Suit.Red()
+	}
+	return false
+}
+
+// Move moves the count cards on top of the pile onto the other pile, and turns the card left on top of a column face
+// up.
+func (game *Game) Move(from Pile, count int, to Pile) error {
+	if !game.CanMove(from, count, to) {
+		if moved := game.top(from, count); count == 1 && moved != nil {
+			return fmt.Errorf("%w: %s from the %s to the %s", ErrIllegal, moved[0], from, to)
+		}
+		return fmt.Errorf("%w: %d cards from the %s to the %s", ErrIllegal, count, from, to)
+	}
+
+	game.save()
+	game.move(from, count, to)
+	game.autoMove()
+	return nil
+}
+
+// move moves the cards without checking the rules.
+func (game *Game) move(from Pile, count int, to Pile) {
+	moved := slices.Clone(game.top(from, count))
+	*game.pile(from) = (*game.pile(from))[:len(*game.pile(from))-count]
+	*game.pile(to) = append(*game.pile(to), moved...)
+	if from.Kind == Tableau {
+		game.hidden[from.Index] = min(game.hidden[from.Index], max(len(game.tableau[from.Index])-1, 0))
+	}
+}
+
+// ToFoundation moves the top card of the pile onto the foundation of its suit.
+func (game *Game) ToFoundation(from Pile) error {
+	moved := game.top(from, 1)
+	if moved == nil {
+		return fmt.Errorf("%w: the %s is empty", ErrIllegal, from)
+	}
+	return game.Move(from, 1, Pile{Foundation, int(moved[0].Suit)})
+}
+
+// AutoMove moves every card of the waste and the tableau which can go onto a foundation, as one move, and returns
+// how many were moved.
+func (game *Game) AutoMove() int {
+	before := game.state.clone()
+	moved := game.moveUp(true)
+	if moved > 0 {
+		game.history = append(game.history, before)
+		game.moves++
+	}
+	return moved
+}
+
+// autoMove moves the cards which can't be needed on the tableau anymore onto the foundations as part of the move
+// played, if the options say so.
+func (game *Game) autoMove() {
+	if game.options.AutoMove {
+		game.moveUp(false)
+	}
+}
+
+// moveUp moves the cards of the waste and the tableau onto the foundations until none can go, all of them or only
+// the safe ones, and returns how many were moved.
+func (game *Game) moveUp(all bool) int {
+	moved := 0
+	for found := true; found; {
+		found = false
+		for _, from := range game.playable() {
+			card := game.top(from, 1)
+			if card == nil || !all && !game.safe(card[0]) {
+				continue
+			}
+			if to := (Pile{Foundation, int(card[0].Suit)}); game.CanMove(from, 1, to) {
+				game.move(from, 1, to)
+				moved++
+				found = true
+			}
+		}
+	}
+	return moved
+}
+
+// Undo takes back the last move, and tells if there was one.
+func (game *Game) Undo() bool {
+	if len(game.history) == 0 {
+		return false
+	}
+	game.state = game.history[len(game.history)-1]
+	game.history = game.history[:len(game.history)-1]
+	return true
+}
+
+// save saves the state before a move and counts the move.
+func (game *Game) save() {
+	game.history = append(game.history, game.state.clone())
+	game.moves++
+}
+
+// pile returns the cards of the pile.
+func (game *Game) pile(pile Pile) *[]cards.Card {
+	switch pile.Kind {
+	case Stock:
+		return &game.stock
+	case Waste:
+		return &game.waste
+	case Foundation:
+		return &game.foundations[pile.Index]
+	}
+	return &game.tableau[pile.Index]
+}
+
+// top returns the count cards on top of the pile, nil if it has fewer or the stock is asked for.
+func (game *Game) top(pile Pile, count int) []cards.Card {
+	if pile.Kind == Stock || !pile.valid() {
+		return nil
+	}
+	stack := *game.pile(pile)
+	if count < 1 || count > len(stack) {
+		return nil
+	}
+	return stack[len(stack)-count:]
+}
+
+// playable returns the piles whose top card can be played: the waste and the columns.
+func (game *Game) playable() []Pile {
+	piles := []Pile{{Kind: Waste}}
+	for i := 0; i < Columns; i++ {
+		piles = append(piles, Pile{Tableau, i})
+	}
+	return piles
+}
+
+// safe tells if the card can't be needed on the tableau anymore: the twos and below, or the cards whose lower cards
+// of the other color are all on the foundations.
+func (game *Game) safe(card cards.Card) bool {
+	if card.Rank <= cards.Two {
+		return true
+	}
+	for i, suit := range cards.Suits {
+		if suit.Red() != card.Suit.Red() && len(game.foundations[i]) < int(card.Rank)-1 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,235 @@
+package engine
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/app/cards"
+)
+
+// parse returns the cards written like "A♥" or "10♠".
+func parse(t *testing.T, texts ...string) []cards.Card {
+	t.Helper()
+	var parsed []cards.Card
+	for _, text := range texts {
+		card, err := cards.Parse(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed = append(parsed, card)
+	}
+	return parsed
+}
+
+// suit returns the cards of the suit from the ace up to the rank.
+func suit(s cards.Suit, upTo cards.Rank) []cards.Card {
+	var stack []cards.Card
+	for rank := cards.Ace; rank <= upTo; rank++ {
+		stack = append(stack, cards.Card{Suit: s, Rank: rank})
+	}
+	return stack
+}
+
+// Test the layout of a deal and that a seed always deals the same cards
+func TestNew(t *testing.T) {
+	game := New(42, Options{Draw: 3})
+
+	seen := map[cards.Card]bool{}
+	for i := 0; i < Columns; i++ {
+		column, hidden := game.Column(i)
+		if len(column) != i+1 || hidden != i {
+			t.Errorf("Expected %d cards in column %d, %d face down, got %d and %d", i+1, i+1, i, len(column), hidden)
+		}
+		for _, card := range column {
+			seen[card] = true
+		}
+	}
+	for _, card := range game.stock {
+		seen[card] = true
+	}
+	if game.Stock() != 24 || len(seen) != 52 {
+		t.Errorf("Expected 24 cards in the stock and 52 different cards, got %d and %d", game.Stock(), len(seen))
+	}
+
+	if again := New(42, Options{Draw: 3}); !slices.Equal(again.stock, game.stock) || again.Options().Draw != 3 {
+		t.Error("Expected the same seed to deal the same cards")
+	}
+	if New(42, Options{Draw: 2}).Options().Draw != 1 {
+		t.Error("Expected to draw one card unless three are asked for")
+	}
+}
+
+// Test the moves the rules allow
+func TestCanMove(t *testing.T) {
+	game := &Game{options: Options{Draw: 1}}
+	game.waste = parse(t, "A♥")
+	game.tableau[0] = parse(t, "5♣", "9♠", "8♥", "7♣")
+	game.hidden[0] = 1
+	game.tableau[1] = parse(t, "10♦")
+	game.tableau[2] = parse(t, "8♦")
+	game.tableau[3] = parse(t, "K♥", "2♥")
+	game.hidden[3] = 1
+
+	tests := []struct {
+		name     string
+		from     Pile
+		count    int
+		to       Pile
+		expected bool
+	}{
+		{"An ace goes onto its foundation", Pile{Kind: Waste}, 1, Pile{Foundation, 0}, true},
+		{"An ace doesn't go onto another foundation", Pile{Kind: Waste}, 1, Pile{Foundation, 1}, false},
+		{"A two doesn't go onto an empty foundation", Pile{Tableau, 3}, 1, Pile{Foundation, 0}, false},
+		{"A run goes onto a card one rank higher of the other color", Pile{Tableau, 0}, 3, Pile{Tableau, 1}, true},
+		{"A run doesn't go onto a card of the same color", Pile{Tableau, 0}, 2, Pile{Tableau, 2}, false},
+		{"A card goes onto a card one rank higher of the other color", Pile{Tableau, 0}, 1, Pile{Tableau, 2}, true},
+		{"Cards face down don't move", Pile{Tableau, 0}, 4, Pile{Tableau, 4}, false},
+		{"Only a king goes onto an empty column", Pile{Tableau, 1}, 1, Pile{Tableau, 4}, false},
+		{"The waste moves a single card", Pile{Kind: Waste}, 2, Pile{Tableau, 4}, false},
+		{"The stock doesn't move", Pile{Kind: Stock}, 1, Pile{Tableau, 4}, false},
+		{"A pile doesn't move onto itself", Pile{Tableau, 0}, 1, Pile{Tableau, 0}, false},
+		{"A card doesn't move onto a foundation off the table", Pile{Kind: Waste}, 1, Pile{Foundation, Foundations}, false},
+		{"A card doesn't move onto a column off the table", Pile{Tableau, 0}, 1, Pile{Tableau, -1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if game.CanMove(tt.from, tt.count, tt.to) != tt.expected {
+				t.Errorf("Expected moving %d cards from the %s to the %s to be %t", tt.count, tt.from, tt.to, tt.expected)
+			}
+		})
+	}
+
+	if err := game.Move(Pile{Tableau, 1}, 1, Pile{Tableau, 4}); !errors.Is(err, ErrIllegal) {
+		t.Errorf("Expected an illegal move, got %v", err)
+	}
+	if err := game.Move(Pile{Kind: Waste}, 1, Pile{Foundation, 9}); !errors.Is(err, ErrIllegal) {
+		t.Errorf("Expected a move off the table to be illegal, got %v", err)
+	}
+}
+
+// Test that a move turns the card left on top face up, and that undo takes it back
+func TestMoveUndo(t *testing.T) {
+	game := &Game{options: Options{Draw: 1}}
+	game.tableau[0] = parse(t, "5♣", "9♠", "8♥", "7♣")
+	game.hidden[0] = 1
+	game.tableau[1] = parse(t, "10♦")
+
+	if err := game.Move(Pile{Tableau, 0}, 3, Pile{Tableau, 1}); err != nil {
+		t.Fatal(err)
+	}
+	column, hidden := game.Column(0)
+	if len(column) != 1 || hidden != 0 || len(game.tableau[1]) != 4 || game.Moves() != 1 {
+		t.Errorf("Expected the run moved and the 5♣ turned face up, got %v with %d face down", column, hidden)
+	}
+
+	if !game.Undo() || len(game.tableau[0]) != 4 || game.hidden[0] != 1 || len(game.tableau[1]) != 1 || game.Moves() != 0 {
+		t.Errorf("Expected the move to be undone, got %v with %d face down", game.tableau[0], game.hidden[0])
+	}
+	if game.Undo() {
+		t.Error("Expected nothing left to undo")
+	}
+}
+
+// Test drawing three cards at once and turning the waste back into the stock
+func TestDrawStock(t *testing.T) {
+	game := &Game{options: Options{Draw: 3}}
+	game.stock = parse(t, "2♠", "3♦", "4♣", "5♥", "6♠")
+
+	game.DrawStock()
+	if !slices.Equal(game.Waste(), parse(t, "6♠", "5♥", "4♣")) || game.Stock() != 2 {
+		t.Errorf("Expected three cards drawn, got %v", game.Waste())
+	}
+	game.DrawStock()
+	if len(game.Waste()) != 5 || game.Stock() != 0 {
+		t.Errorf("Expected the last two cards drawn, got %v", game.Waste())
+	}
+
+	game.DrawStock()
+	if !slices.Equal(game.stock, parse(t, "2♠", "3♦", "4♣", "5♥", "6♠")) || len(game.Waste()) != 0 {
+		t.Errorf("Expected the waste to go back into the stock in order, got %v", game.stock)
+	}
+
+	game.stock = nil
+	if err := game.DrawStock(); !errors.Is(err, ErrIllegal) {
+		t.Errorf("Expected nothing to draw, got %v", err)
+	}
+}
+
+// Test that the automatic moves only take the cards which can't be needed anymore, and moving them all at once
+func TestAutoMove(t *testing.T) {
+	game := &Game{options: Options{Draw: 1, AutoMove: true}}
+	game.foundations[2] = suit(cards.Club, cards.Two)
+	game.tableau[0] = parse(t, "3♥", "A♥")
+	game.tableau[1] = parse(t, "2♥")
+	game.tableau[2] = parse(t, "K♠", "3♣")
+	game.tableau[3] = parse(t, "4♦")
+	game.stock = parse(t, "A♦")
+
+	// A♦ and A♥, 2♥ follow, but not 3♥ while the 2♠ isn't up, nor 3♣ while the twos of the red suits aren't up
+	game.DrawStock()
+	if len(game.foundations[0]) != 2 || len(game.foundations[1]) != 1 || len(game.foundations[2]) != 2 {
+		t.Errorf("Expected A♦, A♥ and 2♥ to move up, got %v, %v and %v", game.foundations[0], game.foundations[1], game.foundations[2])
+	}
+
+	if moved := game.AutoMove(); moved != 2 || len(game.foundations[0]) != 3 || len(game.foundations[2]) != 3 {
+		t.Errorf("Expected 3♥ and 3♣ to move up, got %d cards", moved)
+	}
+	if game.Moves() != 2 || !game.Undo() || len(game.foundations[0]) != 2 {
+		t.Error("Expected moving all the cards up to be a single move")
+	}
+}
+
+// Test that the game is won once all the cards are on the foundations
+func TestWon(t *testing.T) {
+	game := &Game{options: Options{Draw: 1}}
+	for i, s := range cards.Suits {
+		game.foundations[i] = suit(s, cards.King)
+	}
+	game.foundations[3] = game.foundations[3][:12]
+	game.tableau[0] = parse(t, "K♠")
+	if game.Won() {
+		t.Fatal("Expected a card left to play")
+	}
+
+	if err := game.ToFoundation(Pile{Tableau, 0}); err != nil || !game.Won() {
+		t.Errorf("Expected the game to be won, got %v", err)
+	}
+}
+
+// Test that the solver finds the deals which can be won and those which can't
+func TestSolve(t *testing.T) {
+	game := &Game{options: Options{Draw: 1}}
+	game.foundations[1] = suit(cards.Diamond, cards.King)
+	game.foundations[2] = suit(cards.Club, cards.King)
+	game.foundations[3] = suit(cards.Spade, cards.Nine)
+	game.tableau[0] = []cards.Card{{Suit: cards.Heart, Rank: cards.Ace}, {Suit: cards.Heart, Rank: cards.Two}}
+	game.hidden[0] = 1
+	game.tableau[1] = suit(cards.Heart, cards.King)[2:]
+	slices.Reverse(game.tableau[1])
+	game.hidden[1] = 10
+	game.stock = parse(t, "J♠", "Q♠", "10♠", "K♠")
+
+	// 2♥ covers A♥, and goes onto the 3♠ which isn't there anymore
+	if game.Solve(SolveBudget) {
+		t.Error("Expected the ace under the two to make the deal impossible")
+	}
+
+	// 2♥ goes onto the 3♠ which was left on the tableau, from where 10♠ to K♠ are drawn
+	game.foundations[3] = suit(cards.Spade, cards.Two)
+	game.tableau[2] = suit(cards.Spade, cards.Nine)[2:]
+	slices.Reverse(game.tableau[2])
+	game.hidden[2] = 6
+	if !game.Solve(SolveBudget) {
+		t.Error("Expected the deal to be solved")
+	}
+	if game.Moves() != 0 || len(game.tableau[0]) != 2 {
+		t.Error("Expected the solver to leave the game as it was")
+	}
+
+	solvable, solved := NewSolvable(1, Options{Draw: 3})
+	if !solved || solvable.Seed() < 1 || !solvable.Solve(SolveBudget) {
+		t.Errorf("Expected a deal which can be won, got deal %d", solvable.Seed())
+	}
+}
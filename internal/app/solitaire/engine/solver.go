@@ -0,0 +1,175 @@
+package engine
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/Kaamkiya/gg/internal/app/cards"
+)
+
+const (
+	// SolveBudget is the number of positions the solver explores before giving up on a deal.
+	SolveBudget = 50000
+	// maxDeals is the number of deals NewSolvable tries.
+	maxDeals = 100
+)
+
+// candidate is a move the solver tries: count cards from a pile to another, or drawing from the stock.
+type candidate struct {
+	from, to Pile
+	count    int
+	draw     bool
+}
+
+// solver searches the moves of a game depth first, never exploring a position twice.
+type solver struct {
+	visited map[string]bool // Positions explored.
+	budget  int             // Positions left to explore.
+}
+
+// NewSolvable deals the first game from the seed or the next ones which the solver finds a solution for, and tells
+// if it found one. It returns the last deal tried, which may not be winnable, if it finds none in maxDeals deals.
+func NewSolvable(seed int64, options Options) (*Game, bool) {
+	for i := 0; ; i++ {
+		game := New(seed+int64(i), options)
+		if game.Solve(SolveBudget) {
+			return game, true
+		}
+		if i == maxDeals-1 {
+			return game, false
+		}
+	}
+}
+
+// Solve searches for a way to put all the cards on the foundations, exploring at most budget positions, and tells
+// if it found one. The game isn't changed. Not finding a way within the budget doesn't mean there is none.
+func (game *Game) Solve(budget int) bool {
+	solver := &solver{visited: make(map[string]bool), budget: budget}
+	return solver.solve(&Game{state: game.state.clone(), options: game.options})
+}
+
+// solve tells if the game can be won, the cards which can't be needed anymore going onto the foundations after each
+// move.
+func (solver *solver) solve(game *Game) bool {
+	game.moveUp(false)
+	if game.Won() || game.open() {
+		return true
+	}
+
+	key := game.key()
+	if solver.visited[key] || solver.budget <= 0 {
+		return false
+	}
+	solver.visited[key] = true
+	solver.budget--
+
+	for _, move := range game.candidates() {
+		next := &Game{state: game.state.clone(), options: game.options}
+		if move.draw {
+			next.drawStock()
+		} else {
+			next.move(move.from, move.count, move.to)
+		}
+		if solver.solve(next) {
+			return true
+		}
+	}
+	return false
+}
+
+// open tells if all the cards are face up on the tableau or on the foundations, so that the game is won by moving
+// them up one by one.
+func (game *Game) open() bool {
+	if len(game.stock) > 0 || len(game.waste) > 0 {
+		return false
+	}
+	for _, hidden := range game.hidden {
+		if hidden > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// candidates returns the moves worth trying, the most promising first: onto the foundations, then the moves of the
+// tableau turning a card face up, emptying a column or freeing a card for a foundation, then the moves from the
+// waste, and drawing from the stock last.
+func (game *Game) candidates() []candidate {
+	var moves []candidate
+	for _, from := range game.playable() {
+		if card := game.top(from, 1); card != nil {
+			if to := (Pile{Foundation, int(card[0].Suit)}); game.CanMove(from, 1, to) {
+				moves = append(moves, candidate{from: from, to: to, count: 1})
+			}
+		}
+	}
+
+	for i := 0; i < Columns; i++ {
+		from := Pile{Tableau, i}
+		column, hidden := game.tableau[i], game.hidden[i]
+		for count := 1; count <= len(column)-hidden; count++ {
+			whole := count == len(column)-hidden
+			useful := whole && (hidden > 0 || column[hidden].Rank != cards.King)
+			if !whole {
+				left := column[len(column)-count-1]
+				useful = len(game.foundations[left.Suit]) == int(left.Rank)-1
+			}
+			if useful {
+				moves = append(moves, game.tableauMoves(from, count)...)
+			}
+		}
+	}
+
+	moves = append(moves, game.tableauMoves(Pile{Kind: Waste}, 1)...)
+	if len(game.stock) > 0 || len(game.waste) > 0 {
+		moves = append(moves, candidate{draw: true})
+	}
+	return moves
+}
+
+// tableauMoves returns the moves of the count cards of the pile onto the columns, trying a single empty column.
+func (game *Game) tableauMoves(from Pile, count int) []candidate {
+	var moves []candidate
+	empty := false
+	for i := 0; i < Columns; i++ {
+		to := Pile{Tableau, i}
+		if len(game.tableau[i]) == 0 {
+			if empty {
+				continue
+			}
+			empty = true
+		}
+		if game.CanMove(from, count, to) {
+			moves = append(moves, candidate{from: from, to: to, count: count})
+		}
+	}
+	return moves
+}
+
+// key encodes the position for the solver, the columns in any order being the same position.
+func (game *Game) key() string {
+	encode := func(b *strings.Builder, stack []cards.Card) {
+		for _, card := range stack {
+			b.WriteByte(byte(int(card.Suit)*16 + int(card.Rank)))
+		}
+		b.WriteByte('|')
+	}
+
+	columns := make([]string, Columns)
+	for i, column := range game.tableau {
+		var b strings.Builder
+		b.WriteByte(byte(game.hidden[i]))
+		encode(&b, column)
+		columns[i] = b.String()
+	}
+	slices.Sort(columns)
+
+	var b strings.Builder
+	encode(&b, game.stock)
+	encode(&b, game.waste)
+	for _, foundation := range game.foundations {
+		b.WriteByte(byte(len(foundation)))
+	}
+	b.WriteString(strings.Join(columns, ""))
+	return b.String()
+}
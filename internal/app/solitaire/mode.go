@@ -0,0 +1,74 @@
+package solitaire
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/huh"
+)
+
+// -------------------- ENUM: dealKind --------------------
+
+// dealKind is how the cards are dealt.
+type dealKind int
+
+const (
+	dealRandom   dealKind = iota // Any shuffle, some of which can't be won.
+	dealSolvable                 // A shuffle the solver found a solution for.
+)
+
+// dealKinds are the deals to choose from.
+var dealKinds = []dealKind{dealRandom, dealSolvable}
+
+func (d dealKind) String() string {
+	if d == dealSolvable {
+		return "solvable"
+	}
+	return "random"
+}
+
+func (d dealKind) description() string {
+	if d == dealSolvable {
+		return "only the deals the solver can win, when it finds one"
+	}
+	return "any deal, some can't be won"
+}
+
+// selectDeal asks for the kind of deals to play.
+func selectDeal() dealKind {
+	choice := dealRandom
+
+	options := make([]huh.Option[dealKind], 0, len(dealKinds))
+	for _, d := range dealKinds {
+		options = append(options, huh.NewOption(fmt.Sprintf("%s - %s", d, d.description()), d))
+	}
+
+	err := huh.NewSelect[dealKind]().
+		Title("choose the deals:").
+		Options(options...).
+		Value(&choice).
+		Run()
+	if err != nil {
+		panic(err)
+	}
+
+	return choice
+}
+
+// selectDraw asks for the number of cards drawn from the stock at once.
+func selectDraw() int {
+	choice := 1
+
+	err := huh.NewSelect[int]().
+		Title("choose the draw:").
+		Options(
+			huh.NewOption("draw 1 - turn the stock one card at a time", 1),
+			huh.NewOption("draw 3 - turn the stock three cards at a time, only the top one playable", 3),
+		).
+		Value(&choice).
+		Run()
+	if err != nil {
+		panic(err)
+	}
+
+	return choice
+}
@@ -0,0 +1,353 @@
+// Package solitaire is a game of Klondike solitaire played with the cursor: the cards are picked up from a pile and
+// dropped onto another, the rules being those of the engine package.
+package solitaire
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/Kaamkiya/gg/internal/app/cards"
+	"github.com/Kaamkiya/gg/internal/app/solitaire/engine"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// help lists the keys under the table.
+const help = "arrows/hjkl move, enter/space pick up or drop, d draw, f to foundation, a all to foundations, u undo, " +
+	"n new deal, esc cancel, q quit"
+
+// -------------------- UI --------------------
+
+// model represents the Bubbletea UI model for the Klondike game.
+type model struct {
+	game        *engine.Game   // Game state and logic.
+	solvable    bool           // The new deals are ones the solver can win.
+	unsolved    bool           // The solver found no solution for the deal, which may not be winnable.
+	dealing     bool           // A new deal is being dealt.
+	cursor      engine.Pile    // Pile under the cursor.
+	depth       int            // Number of cards under the cursor from the top of a column, 1 for the top card only.
+	selected    engine.Pile    // Pile the cards were picked up from.
+	count       int            // Number of cards picked up, 0 when none are.
+	message     string         // Why the last move wasn't played.
+	headerStyle lipgloss.Style // Style for headers and prompts.
+	tableStyle  lipgloss.Style // Style for the game table.
+	emptyStyle  lipgloss.Style // Style for the places of the empty piles.
+}
+
+// dealtMsg is a new deal, and whether the solver was asked for one it can win and found none.
+type dealtMsg struct {
+	game     *engine.Game
+	unsolved bool
+}
+
+// deal deals a game with the options from a random seed, one the solver can win if asked for.
+func deal(options engine.Options, solvable bool) dealtMsg {
+	if solvable {
+		game, solved := engine.NewSolvable(rand.Int63(), options)
+		return dealtMsg{game, !solved}
+	}
+	return dealtMsg{game: engine.New(rand.Int63(), options)}
+}
+
+// dealCmd deals a game outside of Update, as the solver may search many deals for one it can win.
+func dealCmd(options engine.Options, solvable bool) tea.Cmd {
+	return func() tea.Msg {
+		return deal(options, solvable)
+	}
+}
+
+// newModel creates a new Bubbletea model playing the game.
+func newModel(game *engine.Game, solvable bool) tea.Model {
+	return model{
+		game:        game,
+		solvable:    solvable,
+		cursor:      engine.Pile{Kind: engine.Stock},
+		depth:       1,
+		headerStyle: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("10")), // Bold green text for headers
+		tableStyle:  lipgloss.NewStyle().Border(lipgloss.NormalBorder()).BorderTop(true).BorderBottom(true).Width(72),
+		emptyStyle: lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("8")).
+			Foreground(lipgloss.Color("8")).Width(cards.Width-2).Height(cards.Height-2).Align(lipgloss.Center, lipgloss.Center), // Gray
+	}
+}
+
+// Init initializes the model and returns a clear screen command.
+func (m model) Init() tea.Cmd {
+	return tea.ClearScreen
+}
+
+// Update handles the keys: the cursor moves between the piles and along the cards face up of a column, and the cards
+// under it are picked up then dropped onto another pile. The moves the rules don't allow are explained under the
+// table. A new deal is dealt by a command, and only quitting is possible until it is on the table.
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if dealt, ok := msg.(dealtMsg); ok {
+		m.game, m.unsolved, m.dealing = dealt.game, dealt.unsolved, false
+		m.cursor, m.depth, m.count = engine.Pile{Kind: engine.Stock}, 1, 0
+		return m, tea.ClearScreen
+	}
+
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	m.message = ""
+	switch key.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	}
+	// The cards are left alone until the new deal is on the table
+	if m.dealing {
+		return m, nil
+	}
+
+	switch key.String() {
+	case "left", "h":
+		m.moveCursor(-1)
+	case "right", "l":
+		m.moveCursor(1)
+	case "up", "k":
+		if m.cursor.Kind == engine.Tableau && m.depth < m.faceUp(m.cursor.Index) {
+			m.depth++
+		} else if m.cursor.Kind == engine.Tableau {
+			m.cursor, m.depth = topPile(m.cursor.Index), 1
+		}
+	case "down", "j":
+		if m.cursor.Kind != engine.Tableau {
+			m.cursor, m.depth = engine.Pile{Kind: engine.Tableau, Index: slot(m.cursor)}, 1
+		} else if m.depth > 1 {
+			m.depth--
+		}
+	case "enter", " ":
+		m.pick()
+	case "d":
+		m.count = 0
+		m.play(m.game.DrawStock())
+	case "f":
+		m.count = 0
+		m.play(m.game.ToFoundation(m.cursor))
+	case "a":
+		m.count = 0
+		if m.game.AutoMove() == 0 {
+			m.message = "No card can go onto the foundations"
+		}
+	case "u":
+		m.count = 0
+		if !m.game.Undo() {
+			m.message = "Nothing to undo"
+		}
+	case "n":
+		m.dealing, m.count = true, 0
+		return m, dealCmd(m.game.Options(), m.solvable)
+	case "esc":
+		m.count = 0
+	}
+
+	// The cursor stays on the cards face up of a column changed by the move
+	if m.cursor.Kind == engine.Tableau {
+		m.depth = max(1, min(m.depth, m.faceUp(m.cursor.Index)))
+	}
+	return m, nil
+}
+
+// moveCursor moves the cursor to the pile on the left or the right of the row, skipping the empty place between the
+// waste and the foundations.
+func (m *model) moveCursor(step int) {
+	column := max(0, min(slot(m.cursor)+step, engine.Columns-1))
+	if m.cursor.Kind == engine.Tableau {
+		m.cursor, m.depth = engine.Pile{Kind: engine.Tableau, Index: column}, 1
+		return
+	}
+	if column == 2 {
+		column += step
+	}
+	m.cursor = topPile(column)
+}
+
+// pick picks up the cards under the cursor, or drops the cards picked up onto the pile under the cursor, or puts them
+// back if they came from there. On the stock, it draws instead.
+func (m *model) pick() {
+	switch {
+	case m.cursor.Kind == engine.Stock:
+		m.count = 0
+		m.play(m.game.DrawStock())
+	case m.count > 0:
+		from, count := m.selected, m.count
+		m.count = 0
+		if from != m.cursor {
+			m.play(m.game.Move(from, count, m.cursor))
+		}
+	case len(m.stack(m.cursor)) > 0:
+		m.selected, m.count = m.cursor, 1
+		if m.cursor.Kind == engine.Tableau {
+			m.count = m.depth
+		}
+	}
+}
+
+// play explains why the move wasn't played, if it wasn't.
+func (m *model) play(err error) {
+	if errors.Is(err, engine.ErrIllegal) {
+		m.message = strings.ToUpper(err.Error()[:1]) + err.Error()[1:]
+	}
+}
+
+// faceUp returns the number of cards face up of the column.
+func (m model) faceUp(index int) int {
+	column, hidden := m.game.Column(index)
+	return len(column) - hidden
+}
+
+// stack returns the cards of the pile, nil for the stock.
+func (m model) stack(pile engine.Pile) []cards.Card {
+	switch pile.Kind {
+	case engine.Waste:
+		return m.game.Waste()
+	case engine.Foundation:
+		return m.game.Foundation(pile.Index)
+	case engine.Tableau:
+		column, _ := m.game.Column(pile.Index)
+		return column
+	}
+	return nil
+}
+
+// slot returns the column of the table the pile is in: the stock, the waste and the foundations are above the first,
+// second and last four columns of the tableau.
+func slot(pile engine.Pile) int {
+	switch pile.Kind {
+	case engine.Stock:
+		return 0
+	case engine.Waste:
+		return 1
+	case engine.Foundation:
+		return 3 + pile.Index
+	}
+	return pile.Index
+}
+
+// topPile returns the pile of the top row above the column of the tableau, the waste above the third one.
+func topPile(column int) engine.Pile {
+	switch {
+	case column == 0:
+		return engine.Pile{Kind: engine.Stock}
+	case column <= 2:
+		return engine.Pile{Kind: engine.Waste}
+	}
+	return engine.Pile{Kind: engine.Foundation, Index: column - 3}
+}
+
+// View renders the table: the stock, the waste and the foundations above the columns of the tableau, the cards face
+// up of the columns overlapping. A > marks the card under the cursor and a * the cards picked up.
+func (m model) View() string {
+	options := m.game.Options()
+	header := m.headerStyle.Render(fmt.Sprintf("Klondike (draw %d) - Deal #%d - Stock %d - Moves %d",
+		options.Draw, m.game.Seed(), m.game.Stock(), m.game.Moves()))
+
+	top := []string{m.viewPile(engine.Pile{Kind: engine.Stock}), m.viewPile(engine.Pile{Kind: engine.Waste}),
+		strings.Repeat(" ", cards.Width+1)}
+	for i := 0; i < engine.Foundations; i++ {
+		top = append(top, m.viewPile(engine.Pile{Kind: engine.Foundation, Index: i}))
+	}
+	var tableau []string
+	for i := 0; i < engine.Columns; i++ {
+		tableau = append(tableau, m.viewPile(engine.Pile{Kind: engine.Tableau, Index: i}))
+	}
+
+	s := m.tableStyle.Render(lipgloss.JoinVertical(lipgloss.Left, header,
+		lipgloss.JoinHorizontal(lipgloss.Top, top...), "", lipgloss.JoinHorizontal(lipgloss.Top, tableau...)))
+
+	if m.dealing {
+		return s + "\nDealing..."
+	}
+	if m.unsolved {
+		s += "\nNo deal the solver can win was found, this one may not be winnable"
+	}
+	if m.game.Won() {
+		s += m.headerStyle.Render(fmt.Sprintf("\nYou won in %d moves!", m.game.Moves()))
+		return s + "\nPress n for a new deal or q to quit"
+	}
+	if m.message != "" {
+		s += "\n" + m.message
+	}
+	return s + "\n" + help
+}
+
+// viewPile renders the pile in a gutter marking the card under the cursor and the cards picked up. A column shows a
+// row of the back of its cards face down and the top of its covered cards face up, the other piles their top card.
+func (m model) viewPile(pile engine.Pile) string {
+	stack := m.stack(pile)
+	hidden := 0
+	if pile.Kind == engine.Tableau {
+		_, hidden = m.game.Column(pile.Index)
+	} else if len(stack) > 0 {
+		stack, hidden = stack[len(stack)-1:], 0
+	}
+
+	var blocks []string
+	switch {
+	case pile.Kind == engine.Stock && m.game.Stock() > 0:
+		blocks = append(blocks, cards.RenderBack())
+	case pile.Kind == engine.Foundation && len(stack) == 0:
+		blocks = append(blocks, m.emptyStyle.Render(cards.Suits[pile.Index].String()))
+	case len(stack) == 0:
+		blocks = append(blocks, m.emptyStyle.Render(""))
+	}
+	for i, card := range stack {
+		switch {
+		case i < hidden:
+			// A row of the back of the card, under its top edge for the first one
+			back := strings.Split(cards.RenderBack(), "\n")
+			if i == 0 {
+				blocks = append(blocks, back[0]+"\n"+back[1])
+			} else {
+				blocks = append(blocks, back[1])
+			}
+		case i < len(stack)-1:
+			blocks = append(blocks, cards.RenderTop(card))
+		default:
+			blocks = append(blocks, cards.Render(card))
+		}
+	}
+
+	// The cursor is on the top card, or deeper in a column, and the cards picked up are on top
+	cursor, selected := len(blocks)-1, 0
+	if pile.Kind == engine.Tableau {
+		cursor = len(blocks) - m.depth
+	}
+	if m.count > 0 && m.selected == pile {
+		selected = m.count
+	}
+
+	var gutter []string
+	for i, block := range blocks {
+		marks := make([]string, strings.Count(block, "\n")+1)
+		for j := range marks {
+			marks[j] = " "
+		}
+		switch {
+		case m.cursor == pile && i == cursor:
+			marks[min(1, len(marks)-1)] = ">"
+		case i >= len(blocks)-selected:
+			marks[min(1, len(marks)-1)] = "*"
+		}
+		gutter = append(gutter, marks...)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, strings.Join(gutter, "\n"), strings.Join(blocks, "\n"))
+}
+
+// Run asks for the draw and the deals, then starts the Bubbletea program to run the Klondike game with its UI.
+func Run() {
+	options := engine.Options{Draw: selectDraw(), AutoMove: true}
+	solvable := selectDeal() == dealSolvable
+
+	dealt := deal(options, solvable)
+	m := newModel(dealt.game, solvable).(model)
+	m.unsolved = dealt.unsolved
+
+	program := tea.NewProgram(m)
+	if _, err := program.Run(); err != nil {
+		panic(err) // Panic on program run error
+	}
+}
@@ -0,0 +1,124 @@
+package solitaire
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/app/solitaire/engine"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// press sends the keys to the model.
+func press(m model, keys ...tea.KeyMsg) model {
+	for _, key := range keys {
+		updatedModel, _ := m.Update(key)
+		m = updatedModel.(model)
+	}
+	return m
+}
+
+// runes is the key message of a letter key.
+func runes(key string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+// Test moving the cursor between the piles of the two rows
+func TestCursor(t *testing.T) {
+	m := newModel(engine.New(1, engine.Options{Draw: 1}), false).(model)
+
+	m = press(m, runes("l"), runes("l"))
+	if m.cursor != (engine.Pile{Kind: engine.Foundation}) {
+		t.Errorf("Expected the cursor to skip to the first foundation, got the %s", m.cursor)
+	}
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyDown}, runes("h"), runes("h"))
+	if m.cursor != (engine.Pile{Kind: engine.Tableau, Index: 1}) {
+		t.Errorf("Expected the cursor on the second column, got the %s", m.cursor)
+	}
+
+	// The second column has a single card face up, so up goes to the top row
+	m = press(m, runes("l"), tea.KeyMsg{Type: tea.KeyUp})
+	if m.cursor != (engine.Pile{Kind: engine.Waste}) {
+		t.Errorf("Expected the cursor on the waste, got the %s", m.cursor)
+	}
+}
+
+// Test picking up a card and dropping it onto a column which takes it, and undoing the move
+func TestPickDrop(t *testing.T) {
+	// Find a deal with a card of the tableau going onto another column
+	for seed := int64(0); ; seed++ {
+		game := engine.New(seed, engine.Options{Draw: 1})
+		for from := 0; from < engine.Columns; from++ {
+			for to := 0; to < engine.Columns; to++ {
+				if !game.CanMove(engine.Pile{Kind: engine.Tableau, Index: from}, 1, engine.Pile{Kind: engine.Tableau, Index: to}) {
+					continue
+				}
+
+				m := newModel(game, false).(model)
+				m.cursor = engine.Pile{Kind: engine.Tableau, Index: from}
+				m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+				m.cursor = engine.Pile{Kind: engine.Tableau, Index: to}
+				if m.count != 1 || !strings.Contains(m.View(), "*") {
+					t.Fatalf("Expected the card to be picked up, got %d cards", m.count)
+				}
+
+				m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+				if column, hidden := game.Column(from); m.count != 0 || game.Moves() != 1 || hidden != max(from-1, 0) || len(column) != from {
+					t.Fatalf("Expected the card to be moved and the card under it turned face up, got %d moves", game.Moves())
+				}
+
+				m = press(m, runes("u"))
+				if column, _ := game.Column(from); game.Moves() != 0 || len(column) != from+1 {
+					t.Errorf("Expected the move to be undone, got %d moves", game.Moves())
+				}
+				return
+			}
+		}
+	}
+}
+
+// Test that an illegal move is explained, and that enter draws on the stock
+func TestIllegalMove(t *testing.T) {
+	game := engine.New(1, engine.Options{Draw: 3})
+	m := newModel(game, false).(model)
+
+	m = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if game.Stock() != 21 || len(game.Waste()) != 3 {
+		t.Fatalf("Expected three cards drawn, got %d left in the stock", game.Stock())
+	}
+
+	// The 6♣ of the first column doesn't go onto the empty foundation
+	m.cursor = engine.Pile{Kind: engine.Tableau}
+	column, _ := game.Column(0)
+	m = press(m, runes("f"))
+	if column[0].String() != "6♣" || !strings.Contains(m.View(), "Illegal move: 6♣ from the tableau 1 to the foundation ♣") {
+		t.Errorf("Expected the illegal move to be explained, got:\n%s", m.View())
+	}
+
+	if m = press(m, runes("x")); m.message != "" {
+		t.Errorf("Expected the explanation to go away with the next key, got %q", m.message)
+	}
+}
+
+// Test that a new deal is dealt by a command, the keys waiting for it
+func TestNewDeal(t *testing.T) {
+	game := engine.New(1, engine.Options{Draw: 3})
+	m := newModel(game, true).(model)
+
+	updatedModel, cmd := m.Update(runes("n"))
+	m = updatedModel.(model)
+	if !m.dealing || cmd == nil || !strings.Contains(m.View(), "Dealing...") {
+		t.Fatalf("Expected the deal to be dealt by a command, got:\n%s", m.View())
+	}
+
+	if m = press(m, runes("d")); game.Stock() != 24 {
+		t.Error("Expected no draw while dealing")
+	}
+
+	dealt := cmd().(dealtMsg)
+	updatedModel, _ = m.Update(dealt)
+	m = updatedModel.(model)
+	if m.dealing || m.game != dealt.game || m.game.Options().Draw != 3 || !m.game.Solve(engine.SolveBudget) {
+		t.Errorf("Expected a new deal which can be won, got deal %d", m.game.Seed())
+	}
+}
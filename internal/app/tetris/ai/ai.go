@@ -0,0 +1,237 @@
+// Package ai plays Tetris by trying every rotation and column the active piece,
+// and the piece it would play instead by holding it, can reach and picking the
+// placement which leaves the best board according to a weighted heuristic.
+package ai
+
+import (
+	"github.com/Kaamkiya/gg/internal/app/tetris/color"
+	"github.com/Kaamkiya/gg/internal/app/tetris/engine"
+	"github.com/Kaamkiya/gg/internal/app/tetris/shape"
+)
+
+// Weights of the heuristic. Each feature of the board is multiplied by its
+// weight and the sum is the evaluation of the board, the higher the better.
+//   - AggregateHeight is the sum of the column heights.
+//   - Lines is the number of lines cleared by the placement.
+//   - Holes is the number of empty boxes with a filled box above them.
+//   - Bumpiness is the sum of the height differences of adjacent columns.
+type Weights struct {
+	AggregateHeight float64
+	Lines           float64
+	Holes           float64
+	Bumpiness       float64
+}
+
+// DefaultWeights were found by Yiyuan Lee with a genetic algorithm, see
+// https://codemyroad.wordpress.com/2013/04/14/tetris-ai-the-near-perfect-player/
+var DefaultWeights = Weights{
+	AggregateHeight: -0.510066,
+	Lines:           0.760666,
+	Holes:           -0.35663,
+	Bumpiness:       -0.184483,
+}
+
+// Placement is where the active piece ends up: rotated right Rotations times
+// and moved Shift columns, to the left if negative, before being dropped. When
+// Hold is true, the active piece is held first and the placement is the one of
+// the piece played instead.
+type Placement struct {
+	Rotations  int
+	Shift      int
+	Evaluation float64
+	Hold       bool
+}
+
+// Inputs returns the engine inputs that lead the active piece to the placement.
+// The piece is dropped twice so that it is locked immediately.
+func (p Placement) Inputs() []engine.Input {
+	inputs := make([]engine.Input, 0, p.Rotations+abs(p.Shift)+3)
+
+	if p.Hold {
+		inputs = append(inputs, engine.Hold)
+	}
+
+	for range p.Rotations {
+		inputs = append(inputs, engine.RotateRight)
+	}
+
+	move := engine.MoveRight
+	if p.Shift < 0 {
+		move = engine.MoveLeft
+	}
+
+	for range abs(p.Shift) {
+		inputs = append(inputs, move)
+	}
+
+	return append(inputs, engine.Drop, engine.Drop)
+}
+
+type Player struct {
+	weights Weights
+}
+
+func New(weights Weights) *Player {
+	return &Player{weights}
+}
+
+// Plan returns the inputs leading the active piece of the game to its best
+// placement, or nil if there is no active piece. When the piece can be held,
+// the best placement of the piece played instead is considered too: the held
+// piece, or the next one if none is held yet.
+func (p *Player) Plan(game *engine.Engine) []engine.Input {
+	piece, ok := game.Piece()
+	if !ok {
+		return nil
+	}
+
+	var placement Placement
+	alternative, held := game.Held()
+	if queue := game.Queue(); !held && len(queue) != 0 {
+		alternative, held = queue[0], true
+	}
+
+	if held && game.CanHold() {
+		placement, ok = p.BestHoldPlacement(game.Board(), piece, alternative)
+	} else {
+		placement, ok = p.BestPlacement(game.Board(), piece)
+	}
+
+	if !ok {
+		return []engine.Input{engine.Drop, engine.Drop}
+	}
+
+	return placement.Inputs()
+}
+
+// BestPlacement searches every placement of the piece reachable by rotating
+// it in place then moving it sideways, the same way Placement.Inputs plays it.
+// It returns false if the piece cannot be placed at all.
+func (p *Player) BestPlacement(board engine.Board, piece shape.Shape) (Placement, bool) {
+	best := Placement{}
+	found := false
+
+	rotated := piece
+	for rotations := range 4 {
+		if rotations > 0 {
+			rotated = rotated.RotateRight()
+			if !board.Fits(rotated) {
+				break
+			}
+		}
+
+		for _, direction := range []int{-1, 1} {
+			moved := rotated
+			shift := 0
+
+			for board.Fits(moved) {
+				// The unmoved piece is evaluated once, when going left.
+				if shift != 0 || direction < 0 {
+					evaluation := p.evaluatePlacement(board, moved)
+
+					if !found || evaluation > best.Evaluation {
+						best = Placement{Rotations: rotations, Shift: shift, Evaluation: evaluation}
+						found = true
+					}
+				}
+
+				if direction < 0 {
+					moved = moved.MoveLeft()
+				} else {
+					moved = moved.MoveRight()
+				}
+				shift += direction
+			}
+		}
+	}
+
+	return best, found
+}
+
+// BestHoldPlacement compares the best placement of the piece with the one of
+// the alternative piece played by holding it. Ties keep the piece.
+func (p *Player) BestHoldPlacement(board engine.Board, piece, alternative shape.Shape) (Placement, bool) {
+	best, found := p.BestPlacement(board, piece)
+
+	if held, ok := p.BestPlacement(board, alternative); ok && (!found || held.Evaluation > best.Evaluation) {
+		held.Hold = true
+		return held, true
+	}
+
+	return best, found
+}
+
+// evaluatePlacement drops the piece, locks it on a copy of the board and
+// evaluates the result.
+func (p *Player) evaluatePlacement(board engine.Board, piece shape.Shape) float64 {
+	for board.Fits(piece.MoveDown()) {
+		piece = piece.MoveDown()
+	}
+
+	board.Place(piece)
+
+	_, posY := piece.GetPosition()
+	completedLines := board.CompletedLines(posY, posY+piece.GetHeight()-1)
+	board.RemoveLines(completedLines)
+
+	return p.Evaluate(board, len(completedLines))
+}
+
+// Evaluate returns the weighted sum of the board features.
+func (p *Player) Evaluate(board engine.Board, linesCleared int) float64 {
+	heights := columnHeights(board)
+
+	aggregateHeight := 0
+	bumpiness := 0
+	for i, height := range heights {
+		aggregateHeight += height
+		if i > 0 {
+			bumpiness += abs(height - heights[i-1])
+		}
+	}
+
+	return p.weights.AggregateHeight*float64(aggregateHeight) +
+		p.weights.Lines*float64(linesCleared) +
+		p.weights.Holes*float64(countHoles(board)) +
+		p.weights.Bumpiness*float64(bumpiness)
+}
+
+func columnHeights(board engine.Board) [engine.Width]int {
+	heights := [engine.Width]int{}
+
+	for j := range engine.Width {
+		for i := range engine.Height {
+			if board[i][j] != color.None {
+				heights[j] = engine.Height - i
+				break
+			}
+		}
+	}
+
+	return heights
+}
+
+func countHoles(board engine.Board) int {
+	holes := 0
+
+	for j := range engine.Width {
+		covered := false
+		for i := range engine.Height {
+			if board[i][j] != color.None {
+				covered = true
+			} else if covered {
+				holes++
+			}
+		}
+	}
+
+	return holes
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
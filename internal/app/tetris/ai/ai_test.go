@@ -0,0 +1,100 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/app/tetris/color"
+	"github.com/Kaamkiya/gg/internal/app/tetris/engine"
+	"github.com/Kaamkiya/gg/internal/app/tetris/shape"
+)
+
+func TestHolesAndHeightsAreCounted(t *testing.T) {
+	board := engine.Board{}
+	board[engine.Height-3][0] = color.Blue
+	board[engine.Height-1][1] = color.Blue
+
+	heights := columnHeights(board)
+	if heights[0] != 3 || heights[1] != 1 || heights[2] != 0 {
+		t.Fatalf("Unexpected column heights %v", heights)
+	}
+
+	if holes := countHoles(board); holes != 2 {
+		t.Fatalf("Expected 2 holes but counted %d", holes)
+	}
+}
+
+func TestPlacementCompletingALineIsPreferred(t *testing.T) {
+	game := engine.New(3)
+	game.Step(engine.Gravity)
+	piece, _ := game.Piece()
+
+	// Leave a gap in the bottom line exactly where the piece lands unmoved.
+	landed := piece
+	board := engine.Board{}
+	for board.Fits(landed.MoveDown()) {
+		landed = landed.MoveDown()
+	}
+	landedBoard := engine.Board{}
+	landedBoard.Place(landed)
+	for j := range engine.Width {
+		if landedBoard[engine.Height-1][j] == color.None {
+			board[engine.Height-1][j] = color.Blue
+		}
+	}
+
+	player := New(DefaultWeights)
+	placement, ok := player.BestPlacement(board, piece)
+	if !ok {
+		t.Fatal("The piece should fit on the board")
+	}
+
+	if placement.Rotations != 0 || placement.Shift != 0 {
+		t.Fatalf("Expected the piece to fill the gap but got %+v", placement)
+	}
+}
+
+func TestBenchmarkClearsLines(t *testing.T) {
+	result := New(DefaultWeights).Benchmark(5, 1, 200)
+
+	if result.Games != 5 {
+		t.Fatal("Every game should be played")
+	}
+
+	if result.AverageLines < 50 {
+		t.Fatalf("The AI should clear lines consistently but averaged %.1f lines", result.AverageLines)
+	}
+}
+
+func TestHoldingIsPreferredWhenTheOtherPieceFitsBetter(t *testing.T) {
+	// Find an I piece and another piece from the first pieces of a game.
+	game := engine.New(1)
+	var straight, other *shape.Shape
+	for straight == nil || other == nil {
+		game.Step(engine.Gravity)
+		piece, _ := game.Piece()
+		if piece.GetColor() == color.Teal {
+			straight = &piece
+		} else {
+			other = &piece
+		}
+		game.Step(engine.Drop)
+		game.Step(engine.Drop)
+	}
+
+	// Only the I piece clears the four bottom lines, standing in the first column.
+	board := engine.Board{}
+	for i := engine.Height - 4; i < engine.Height; i++ {
+		for j := 1; j < engine.Width; j++ {
+			board[i][j] = color.Blue
+		}
+	}
+
+	player := New(DefaultWeights)
+	if placement, _ := player.BestHoldPlacement(board, *other, *straight); !placement.Hold {
+		t.Fatalf("Expected to hold the piece and play the I piece but got %+v", placement)
+	}
+
+	if placement, _ := player.BestHoldPlacement(board, *straight, *other); placement.Hold {
+		t.Fatalf("Expected to play the I piece but got %+v", placement)
+	}
+}
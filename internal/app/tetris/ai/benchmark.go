@@ -0,0 +1,60 @@
+package ai
+
+import (
+	"github.com/Kaamkiya/gg/internal/app/tetris/engine"
+)
+
+// BenchmarkResult summarizes the games played by Benchmark. Games that did not
+// top out were stopped after the maximum number of pieces.
+type BenchmarkResult struct {
+	Games        int
+	ToppedOut    int
+	AverageLines float64
+	MinLines     int
+	MaxLines     int
+	AverageScore float64
+}
+
+// Play plays the game until it is over or maxPieces pieces were placed.
+// Pieces are placed right after spawning, without any gravity in between.
+func (p *Player) Play(game *engine.Engine, maxPieces int) {
+	for !game.IsOver() && game.Pieces() < maxPieces {
+		game.Step(engine.Gravity)
+
+		for _, input := range p.Plan(game) {
+			game.Step(input)
+		}
+	}
+}
+
+// Benchmark plays the given number of games, seeded from seed onwards so
+// that runs with the same arguments are comparable.
+func (p *Player) Benchmark(games int, seed int64, maxPieces int) BenchmarkResult {
+	result := BenchmarkResult{Games: games}
+	totalLines := 0
+	totalScore := uint(0)
+
+	for i := range games {
+		game := engine.New(seed + int64(i))
+		p.Play(game, maxPieces)
+
+		if game.IsOver() {
+			result.ToppedOut++
+		}
+
+		if i == 0 || game.Lines() < result.MinLines {
+			result.MinLines = game.Lines()
+		}
+		result.MaxLines = max(result.MaxLines, game.Lines())
+
+		totalLines += game.Lines()
+		totalScore += game.Score()
+	}
+
+	if games > 0 {
+		result.AverageLines = float64(totalLines) / float64(games)
+		result.AverageScore = float64(totalScore) / float64(games)
+	}
+
+	return result
+}
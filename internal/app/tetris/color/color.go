@@ -14,6 +14,7 @@ const (
 	Purple
 	Magenta
 	Beige
+	Gray
 )
 
 var defaultStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#f9f6f2"))
@@ -28,4 +29,5 @@ var Colors = map[Color]lipgloss.Style{
 	Purple:  defaultStyle.Background(lipgloss.Color("#9047A3")),
 	Magenta: defaultStyle.Background(lipgloss.Color("#CA1F7B")),
 	Beige:   defaultStyle.Background(lipgloss.Color("#FFFDD0")),
+	Gray:    defaultStyle.Background(lipgloss.Color("#717C7C")),
 }
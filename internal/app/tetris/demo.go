@@ -0,0 +1,102 @@
+package tetris
+
+import (
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/app/tetris/ai"
+	"github.com/Kaamkiya/gg/internal/app/tetris/engine"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// aiMoveInterval is the time between two inputs of the AI
+	aiMoveInterval time.Duration = 80 * time.Millisecond
+	// demoRestartDelay is how long the results stay up before a new demo game starts
+	demoRestartDelay time.Duration = 5 * time.Second
+)
+
+type aiMoveTick struct{}
+
+type demoRestartTick struct{}
+
+// demoPlayer lets the AI play as an attract mode. The game runs with its usual
+// gravity while the AI plays one input every aiMoveInterval, following the plan
+// it made for the active piece. A new game starts a few seconds after topping out.
+type demoPlayer struct {
+	game      *gameState
+	player    *ai.Player
+	plan      []engine.Input
+	planPiece int
+}
+
+func newDemoPlayer() *demoPlayer {
+	return &demoPlayer{player: ai.New(ai.DefaultWeights)}
+}
+
+func (d *demoPlayer) Init() tea.Cmd {
+	game := newGameState(modeClassic, time.Now().UnixNano(), wallClock)
+	d.game = &game
+	d.plan = nil
+	d.planPiece = 0
+
+	return tea.Batch(d.game.Init(), nextAiMoveTick())
+}
+
+func nextAiMoveTick() tea.Cmd {
+	return tea.Tick(aiMoveInterval, func(time.Time) tea.Msg {
+		return aiMoveTick{}
+	})
+}
+
+func (d *demoPlayer) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" || msg.String() == "q" || msg.String() == "Q" {
+			return d, tea.Quit
+		}
+	case gameProgressTick, lineAnimationTick:
+		_, cmd := d.game.Update(msg)
+		return d, cmd
+	case aiMoveTick:
+		if d.game.progress.isFinished() {
+			return d, tea.Tick(demoRestartDelay, func(time.Time) tea.Msg {
+				return demoRestartTick{}
+			})
+		}
+
+		return d, tea.Batch(d.playNextInput(), nextAiMoveTick())
+	case demoRestartTick:
+		return d, d.Init()
+	}
+
+	return d, nil
+}
+
+// playNextInput plans the placement of a newly spawned piece and plays the
+// next input of the plan. Nothing is played during the line clearing animation.
+func (d *demoPlayer) playNextInput() tea.Cmd {
+	if d.game.lineAnimation != nil {
+		return nil
+	}
+
+	if d.planPiece != d.game.engine.Pieces() {
+		d.plan = d.player.Plan(d.game.engine)
+		d.planPiece = d.game.engine.Pieces()
+	}
+
+	if len(d.plan) == 0 {
+		return nil
+	}
+
+	input := d.plan[0]
+	d.plan = d.plan[1:]
+
+	return d.game.handleInput(input, d.game.clock())
+}
+
+func (d *demoPlayer) View() string {
+	statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#2692E8"))
+
+	return d.game.View() + "\n" + statusStyle.Render("AI DEMO") + "  q/ctl+c to quit\n"
+}
@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"slices"
+
+	"github.com/Kaamkiya/gg/internal/app/tetris/color"
+	"github.com/Kaamkiya/gg/internal/app/tetris/shape"
+)
+
+const (
+	// Height is the game area height counted in Tetris squares
+	Height = 20
+	// Width is the game area width counted in Tetris squares
+	Width = 10
+)
+
+// Board represents the Tetris game area. Each box contains a Color, where
+// color.None means that the box is empty.
+type Board [Height][Width]color.Color
+
+// Fits checks if a shape can be placed on the board by checking:
+//   - If the shape is inside the board
+//   - If the shape does not overlap with any occupied box.
+func (b *Board) Fits(s shape.Shape) bool {
+	shapeGrid := s.GetGrid()
+	posX, posY := s.GetPosition()
+
+	if posX < 0 || posY < 0 {
+		return false
+	}
+
+	if posX+len(shapeGrid[0]) > Width || posY+len(shapeGrid) > Height {
+		return false
+	}
+
+	for i := range shapeGrid {
+		for j := range shapeGrid[i] {
+			if shapeGrid[i][j] && b[posY+i][posX+j] != color.None {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Place fills the boxes covered by the shape with its color.
+func (b *Board) Place(s shape.Shape) {
+	shapeGrid := s.GetGrid()
+	posX, posY := s.GetPosition()
+
+	for i := range shapeGrid {
+		for j := range shapeGrid[i] {
+			if shapeGrid[i][j] {
+				b[posY+i][posX+j] = s.GetColor()
+			}
+		}
+	}
+}
+
+// CompletedLines returns the completed lines between from and to, bottom first.
+func (b *Board) CompletedLines(from, to int) []int {
+	completedLines := make([]int, 0, 4)
+	for i := min(to, Height-1); i >= max(from, 0); i-- {
+		if b.IsLineCompleted(i) {
+			completedLines = append(completedLines, i)
+		}
+	}
+
+	return completedLines
+}
+
+// RemoveLines removes the given lines and drops the lines above them.
+func (b *Board) RemoveLines(lines []int) {
+	if len(lines) == 0 {
+		return
+	}
+
+	completedLines := slices.Clone(lines)
+	slices.Sort(completedLines)
+	slices.Reverse(completedLines)
+
+	// lines are removed with a single pass from bottom to top.The completedLines array
+	// is sorted in descending order and the first completed line is replaced by the one
+	// above it. If another completed line is encountered during replacing, the distanceToCopyFrom
+	// is increased to start copying from two places above and so on. The distanceToCopyFrom variable
+	// specifies both the lines to skip when replacing and the index of the next completed line in the
+	// completedLines array.
+	distanceToCopyFrom := 1
+
+	for i := completedLines[0]; i >= 0; i-- {
+		for distanceToCopyFrom < len(completedLines) && completedLines[distanceToCopyFrom] == i-distanceToCopyFrom {
+			distanceToCopyFrom++
+		}
+
+		if i-distanceToCopyFrom < 0 {
+			b[i] = [Width]color.Color{}
+			continue
+		}
+
+		b[i] = b[i-distanceToCopyFrom]
+	}
+}
+
+func (b *Board) IsLineCompleted(line int) bool {
+	for i := range Width {
+		if b[line][i] == color.None {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (b *Board) IsLineEmpty(line int) bool {
+	for i := range Width {
+		if b[line][i] != color.None {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,401 @@
+// Package engine implements the rules of Tetris without any notion of time or
+// user interface. The game only progresses when Step is called, either with
+// Gravity to make the piece fall one line or with a player action, which makes
+// it deterministic for a given seed and sequence of inputs.
+package engine
+
+import (
+	"github.com/Kaamkiya/gg/internal/app/tetris/color"
+	"github.com/Kaamkiya/gg/internal/app/tetris/shape"
+)
+
+// Input is what makes the game progress in a Step.
+type Input int
+
+const (
+	// Gravity spawns a new piece if needed, otherwise drops the piece one line
+	// or locks it if it cannot fall anymore.
+	Gravity Input = iota
+	MoveLeft
+	MoveRight
+	RotateLeft
+	RotateRight
+	// Drop moves the piece to the bottom without locking it, so that it can
+	// still be arranged until the next Gravity steps lock it. Dropping a piece
+	// which is already at the bottom locks it immediately.
+	Drop
+	// Hold puts the active piece aside and plays the piece held before instead,
+	// or the next piece if none was held. It is allowed once per piece.
+	Hold
+)
+
+// EventKind describes what happened during a Step.
+type EventKind int
+
+const (
+	Spawned EventKind = iota
+	Moved
+	Rotated
+	Dropped
+	Locked
+	LinesCleared
+	ToppedOut
+	GarbageReceived
+	Held
+)
+
+// Event is emitted by Step. Lines holds the cleared line indices, bottom first,
+// for LinesCleared events and is empty otherwise.
+type Event struct {
+	Kind  EventKind
+	Lines []int
+}
+
+const (
+	dropFinished dropStatus = iota
+	dropIniated
+	dropInProgress
+)
+
+type dropStatus int
+
+// Engine contains the game state.
+//   - board holds the locked pieces, the active piece is not part of it.
+//   - piece is the active piece, nil between a lock and the next spawn.
+//   - spawned is the active piece as it spawned, which is what holding it keeps.
+//   - held is the piece put aside, nil until the first hold.
+//   - holdUsed is true once the active piece has been swapped with the held one.
+//   - queue holds the pieces that will be spawned next.
+//   - randomizer is used to find which shape is going to be queued next.
+//   - garbage holds the gap column of each garbage line waiting to be added.
+type Engine struct {
+	board      Board
+	piece      *shape.Shape
+	spawned    shape.Shape
+	held       *shape.Shape
+	holdUsed   bool
+	queue      []shape.Shape
+	randomizer *shape.Randomizer
+	score      uint
+	lines      int
+	pieces     int
+	difficulty difficulty
+	dropStatus dropStatus
+	isOver     bool
+	garbage    []int
+}
+
+// New creates a game where the pieces are picked with a randomizer created
+// from seed, so the same seed always produces the same pieces.
+func New(seed int64) *Engine {
+	return &Engine{
+		randomizer: shape.NewSeededRandomizer(seed),
+		difficulty: newDifficulty(),
+	}
+}
+
+// Board returns the locked pieces.
+func (e *Engine) Board() Board {
+	return e.board
+}
+
+// Cells returns the board with the active piece drawn on it.
+func (e *Engine) Cells() Board {
+	cells := e.board
+	if e.piece != nil {
+		cells.Place(*e.piece)
+	}
+
+	return cells
+}
+
+// Piece returns the active piece and false if there is none.
+func (e *Engine) Piece() (shape.Shape, bool) {
+	if e.piece == nil {
+		return shape.Shape{}, false
+	}
+
+	return *e.piece, true
+}
+
+// Held returns the piece put aside, as it spawned, and false if none is.
+func (e *Engine) Held() (shape.Shape, bool) {
+	if e.held == nil {
+		return shape.Shape{}, false
+	}
+
+	return *e.held, true
+}
+
+// CanHold returns true if the active piece can be put aside.
+func (e *Engine) CanHold() bool {
+	return e.piece != nil && !e.holdUsed
+}
+
+// Queue returns the pieces that will be spawned next, in order.
+func (e *Engine) Queue() []shape.Shape {
+	return append([]shape.Shape(nil), e.queue...)
+}
+
+func (e *Engine) Score() uint {
+	return e.score
+}
+
+// Lines returns the number of lines cleared since the start of the game.
+func (e *Engine) Lines() int {
+	return e.lines
+}
+
+// Pieces returns the number of pieces spawned since the start of the game,
+// which also identifies the active piece.
+func (e *Engine) Pieces() int {
+	return e.pieces
+}
+
+// Level is the factor that increases scoring. Interfaces should also make the
+// gravity faster as it increases.
+func (e *Engine) Level() float32 {
+	return e.difficulty.level
+}
+
+func (e *Engine) IsOver() bool {
+	return e.isOver
+}
+
+// Step progresses the game with the input and returns what happened.
+func (e *Engine) Step(input Input) []Event {
+	if e.isOver {
+		return nil
+	}
+
+	switch input {
+	case Gravity:
+		return e.gravity()
+	case MoveLeft:
+		return e.transform(shape.Shape.MoveLeft, Moved)
+	case MoveRight:
+		return e.transform(shape.Shape.MoveRight, Moved)
+	case RotateLeft:
+		return e.transform(shape.Shape.RotateLeft, Rotated)
+	case RotateRight:
+		return e.transform(shape.Shape.RotateRight, Rotated)
+	case Drop:
+		return e.drop()
+	case Hold:
+		return e.hold()
+	}
+
+	return nil
+}
+
+// gravity simulates the active piece dropping a line. The basic flow is:
+//  1. Create new shapes if needed
+//  2. Drop the current shape one line
+//  3. Lock the shape and clear the completed lines if it cannot drop
+func (e *Engine) gravity() []Event {
+	if len(e.queue) == 0 {
+		e.queue = append(e.queue, e.newShape())
+	}
+
+	if e.piece == nil {
+		return e.spawn()
+	}
+
+	// Give the player a full step to arrange a dropped piece.
+	if e.dropStatus == dropIniated {
+		e.dropStatus = dropInProgress
+		return nil
+	}
+
+	if e.transform(shape.Shape.MoveDown, Moved) != nil {
+		e.addStillLivingScore()
+		return []Event{{Kind: Moved}}
+	}
+
+	return e.lock()
+}
+
+// spawn adds the pending garbage then makes the first queued piece the active
+// one. The game is over if the garbage pushes pieces out of the board or if the
+// piece overlaps with the locked pieces.
+func (e *Engine) spawn() []Event {
+	events := []Event{}
+
+	if len(e.garbage) != 0 {
+		events = append(events, Event{Kind: GarbageReceived})
+
+		if !e.addGarbage() {
+			e.isOver = true
+			return append(events, Event{Kind: ToppedOut})
+		}
+	}
+
+	piece := e.queue[0]
+	e.queue = append(e.queue[1:], e.newShape())
+
+	if !e.board.Fits(piece) {
+		e.isOver = true
+		return append(events, Event{Kind: ToppedOut})
+	}
+
+	e.piece = &piece
+	e.spawned = piece
+	e.pieces++
+
+	return append(events, Event{Kind: Spawned})
+}
+
+// lock adds the active piece to the board and clears the lines it completed.
+// The game is over if a piece locks at the top without clearing any line.
+func (e *Engine) lock() []Event {
+	e.adjustDifficulty()
+
+	_, posY := e.piece.GetPosition()
+	e.board.Place(*e.piece)
+	completedLines := e.board.CompletedLines(posY, posY+e.piece.GetHeight()-1)
+
+	e.piece = nil
+	e.holdUsed = false
+	e.dropStatus = dropFinished
+	events := []Event{{Kind: Locked}}
+
+	if len(completedLines) != 0 {
+		e.board.RemoveLines(completedLines)
+		e.lines += len(completedLines)
+		e.addLineScore(len(completedLines))
+
+		return append(events, Event{Kind: LinesCleared, Lines: completedLines})
+	}
+
+	if posY == 0 {
+		e.isOver = true
+		return append(events, Event{Kind: ToppedOut})
+	}
+
+	e.addStillLivingScore()
+
+	return events
+}
+
+// drop moves immediately the piece to the bottom but the drop is not finished yet.
+// Players will have a step to arrange the piece before it is locked.
+// If players drop again they force a drop which locks the piece immediately.
+func (e *Engine) drop() []Event {
+	if e.piece == nil {
+		return nil
+	}
+
+	pieceMoved := false
+	for e.transform(shape.Shape.MoveDown, Moved) != nil {
+		pieceMoved = true
+		e.addLivingDangerouslyScore()
+	}
+
+	if pieceMoved {
+		e.dropStatus = dropIniated
+		return []Event{{Kind: Dropped}}
+	}
+
+	e.dropStatus = dropFinished
+
+	return e.gravity()
+}
+
+// hold swaps the active piece with the held one, which comes back as it
+// spawned. When no piece is held, the next piece spawns instead. The game is
+// over if the piece coming back overlaps with the locked pieces.
+func (e *Engine) hold() []Event {
+	if !e.CanHold() {
+		return nil
+	}
+
+	spawned := e.spawned
+	held := e.held
+	e.held = &spawned
+	e.holdUsed = true
+	e.dropStatus = dropFinished
+	events := []Event{{Kind: Held}}
+
+	if held == nil {
+		e.piece = nil
+		return append(events, e.spawn()...)
+	}
+
+	if !e.board.Fits(*held) {
+		e.isOver = true
+		return append(events, Event{Kind: ToppedOut})
+	}
+
+	e.piece = held
+	e.spawned = *held
+
+	return events
+}
+
+// transform applies the transformation to the active piece if the result fits
+// on the board and returns an event of the given kind, or nil if it does not fit.
+func (e *Engine) transform(transformation func(shape.Shape) shape.Shape, kind EventKind) []Event {
+	if e.piece == nil {
+		return nil
+	}
+
+	newShape := transformation(*e.piece)
+	if !e.board.Fits(newShape) {
+		return nil
+	}
+
+	e.piece = &newShape
+
+	return []Event{{Kind: kind}}
+}
+
+// AddGarbage queues lines of garbage which are pushed under the board when the
+// next piece spawns. Every box of the garbage lines is filled except the one in
+// the gap column.
+func (e *Engine) AddGarbage(lines, gap int) {
+	for range lines {
+		e.garbage = append(e.garbage, gap)
+	}
+}
+
+// PendingGarbage returns the number of garbage lines waiting to be added.
+func (e *Engine) PendingGarbage() int {
+	return len(e.garbage)
+}
+
+// addGarbage pushes the board up and fills the bottom lines with the pending
+// garbage. It returns false if locked pieces were pushed out of the board.
+func (e *Engine) addGarbage() bool {
+	lines := min(len(e.garbage), Height)
+	fits := true
+
+	for i := range lines {
+		if !e.board.IsLineEmpty(i) {
+			fits = false
+		}
+	}
+
+	for i := range Height - lines {
+		e.board[i] = e.board[i+lines]
+	}
+
+	for i, gap := range e.garbage[len(e.garbage)-lines:] {
+		line := Height - lines + i
+		for j := range Width {
+			e.board[line][j] = color.Gray
+			if j == gap {
+				e.board[line][j] = color.None
+			}
+		}
+	}
+
+	e.garbage = nil
+
+	return fits
+}
+
+func (e *Engine) newShape() shape.Shape {
+	middleX := (Width / 2) - 1
+
+	return shape.CreateNew(middleX, 0, e.randomizer)
+}
@@ -0,0 +1,220 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/app/tetris/color"
+)
+
+func TestASingleLineIsRemoved(t *testing.T) {
+	board := Board{}
+
+	for i := range Width {
+		board[Height-1][i] = color.Blue
+	}
+
+	lines := board.CompletedLines(19, 19)
+	board.RemoveLines(lines)
+
+	if !board.IsLineEmpty(19) {
+		t.Fatal("Completed single line not removed")
+	}
+}
+
+func TestMultipleLinesAreRemoved(t *testing.T) {
+	board := Board{}
+
+	for i := range Width {
+		board[Height-1][i] = color.Blue
+		board[Height-3][i] = color.Blue
+		board[Height-4][i] = color.Blue
+	}
+
+	board[Height-2][0] = color.Blue
+	board[Height-5][0] = color.Blue
+
+	lines := board.CompletedLines(16, 19)
+	board.RemoveLines(lines)
+
+	if board[Height-1][0] != color.Blue || board[Height-1][1] != color.None {
+		t.Fatal("Second to last line didn't drop when last line was completed")
+	}
+
+	if board[Height-2][0] != color.Blue || board[Height-2][1] != color.None {
+		t.Fatal("Fifth to last line didn't drop when third to last line was completed")
+	}
+
+	if !board.IsLineEmpty(Height - 3) {
+		t.Fatal("Lines didn't move correctly when lines where completed")
+	}
+}
+
+// dropPiece spawns a piece and drops it to the bottom of the board.
+func dropPiece(e *Engine) []Event {
+	events := e.Step(Gravity)
+	events = append(events, e.Step(Drop)...)
+
+	return append(events, e.Step(Drop)...)
+}
+
+func TestLockedPieceClearsCompletedLines(t *testing.T) {
+	e := New(1)
+
+	// Fill the bottom line except where the piece is going to land.
+	e.Step(Gravity)
+	piece, _ := e.Piece()
+	for e.board.Fits(piece.MoveDown()) {
+		piece = piece.MoveDown()
+	}
+	grid := piece.GetGrid()
+	posX, _ := piece.GetPosition()
+	for i := range Width {
+		if i < posX || i >= posX+len(grid[0]) || !grid[len(grid)-1][i-posX] {
+			e.board[Height-1][i] = color.Blue
+		}
+	}
+
+	e.Step(Drop)
+	events := e.Step(Drop)
+
+	if len(events) != 2 || events[0].Kind != Locked || events[1].Kind != LinesCleared {
+		t.Fatalf("Expected the piece to lock and clear a line but got %v", events)
+	}
+
+	if e.Lines() != 1 {
+		t.Fatal("Cleared line not counted")
+	}
+
+	if !e.board.IsLineEmpty(0) || e.board.IsLineCompleted(Height-1) {
+		t.Fatal("Cleared line not removed from the board")
+	}
+}
+
+func TestScoringIsMultipliedByTheLevel(t *testing.T) {
+	e := New(1)
+
+	e.addLineScore(4)
+	if e.Score() != 800 {
+		t.Fatal("Four lines should score 800 points at the first level")
+	}
+
+	for range initialDifficulyCountDown {
+		e.adjustDifficulty()
+	}
+
+	e.addLineScore(1)
+	if e.Score() != 800+110 {
+		t.Fatalf("A single line should score 110 points at the second level, got %d", e.Score()-800)
+	}
+}
+
+func TestGameIsOverWhenPiecesReachTheTop(t *testing.T) {
+	e := New(1)
+
+	for range Height {
+		events := dropPiece(e)
+		if e.IsOver() {
+			if events[len(events)-1].Kind != ToppedOut {
+				t.Fatal("The last event of a game should be ToppedOut")
+			}
+
+			if e.Step(Gravity) != nil {
+				t.Fatal("The game should not progress after topping out")
+			}
+
+			return
+		}
+	}
+
+	t.Fatal("Stacking pieces in the middle should top out")
+}
+
+func TestSameSeedAndInputsProduceTheSameGame(t *testing.T) {
+	first := New(7)
+	second := New(7)
+	inputs := []Input{Gravity, MoveLeft, Gravity, RotateRight, Gravity, MoveRight, MoveRight, Drop, Gravity, Gravity}
+
+	for range 20 {
+		for _, input := range inputs {
+			first.Step(input)
+			second.Step(input)
+		}
+	}
+
+	if first.Cells() != second.Cells() || first.Score() != second.Score() {
+		t.Fatal("Games with the same seed and inputs should be identical")
+	}
+}
+
+func TestGarbageIsAddedWhenTheNextPieceSpawns(t *testing.T) {
+	e := New(1)
+	e.board[Height-1][0] = color.Blue
+
+	e.AddGarbage(2, 3)
+	if e.PendingGarbage() != 2 || !e.board.IsLineEmpty(Height-2) {
+		t.Fatal("Garbage should wait for the next piece")
+	}
+
+	events := e.Step(Gravity)
+	if events[0].Kind != GarbageReceived || events[1].Kind != Spawned {
+		t.Fatalf("Expected the garbage to be received before the piece spawned but got %v", events)
+	}
+
+	if e.board[Height-3][0] != color.Blue {
+		t.Fatal("Garbage should push the board up")
+	}
+
+	for _, line := range []int{Height - 1, Height - 2} {
+		for j := range Width {
+			if (j == 3) != (e.board[line][j] == color.None) {
+				t.Fatal("Garbage lines should only have a gap in the gap column")
+			}
+		}
+	}
+}
+
+func TestGarbagePushingPiecesOutOfTheBoardTopsOut(t *testing.T) {
+	e := New(1)
+	e.board[0][0] = color.Blue
+
+	e.AddGarbage(1, 0)
+	events := e.Step(Gravity)
+
+	if !e.IsOver() || events[len(events)-1].Kind != ToppedOut {
+		t.Fatal("Pushing pieces out of the board should top out")
+	}
+}
+
+func TestHoldSwapsTheActivePieceWithTheHeldOne(t *testing.T) {
+	e := New(1)
+	e.Step(Gravity)
+	first, _ := e.Piece()
+	next := e.Queue()[0]
+
+	e.Step(MoveLeft)
+	events := e.Step(Hold)
+	if len(events) != 2 || events[0].Kind != Held || events[1].Kind != Spawned {
+		t.Fatalf("Expected the next piece to spawn when nothing is held but got %v", events)
+	}
+
+	held, ok := e.Held()
+	piece, _ := e.Piece()
+	if !ok || held.GetColor() != first.GetColor() || piece.GetColor() != next.GetColor() {
+		t.Fatal("The active piece should be held and the next one played")
+	}
+
+	if x, _ := held.GetPosition(); x != (Width/2)-1 {
+		t.Fatal("The held piece should be kept as it spawned")
+	}
+
+	if e.CanHold() || e.Step(Hold) != nil {
+		t.Fatal("A piece should only be held once until it is locked")
+	}
+
+	dropPiece(e)
+	e.Step(Gravity)
+	e.Step(Hold)
+	if piece, _ := e.Piece(); piece.GetColor() != first.GetColor() {
+		t.Fatal("Holding again should bring the held piece back")
+	}
+}
@@ -0,0 +1,56 @@
+package engine
+
+const (
+	// initialDifficulyCountDown is the number of pieces that trigger a difficulty increase
+	initialDifficulyCountDown = 10
+	// initialDifficulyLevel is the factor that increases scoring. Increased by 0.1 on difficulty increase.
+	initialDifficulyLevel = 1.0
+)
+
+type difficulty struct {
+	countdown int
+	level     float32
+}
+
+func newDifficulty() difficulty {
+	return difficulty{
+		initialDifficulyCountDown,
+		initialDifficulyLevel,
+	}
+}
+
+func (e *Engine) adjustDifficulty() {
+	if e.difficulty.countdown <= 1 {
+		e.difficulty.countdown = initialDifficulyCountDown
+		e.difficulty.level += 0.1
+
+		return
+	}
+
+	e.difficulty.countdown--
+}
+
+func (e *Engine) addLineScore(completedLinesNum int) {
+	switch completedLinesNum {
+	case 4:
+		e.scorePoints(800)
+	case 3:
+		e.scorePoints(500)
+	case 2:
+		e.scorePoints(300)
+	default:
+		e.scorePoints(100)
+	}
+}
+
+func (e *Engine) addStillLivingScore() {
+	e.scorePoints(1)
+}
+
+func (e *Engine) addLivingDangerouslyScore() {
+	e.scorePoints(2)
+}
+
+func (e *Engine) scorePoints(points uint) {
+	e.score += uint(float32(points) * e.difficulty.level)
+}
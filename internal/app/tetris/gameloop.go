@@ -1,11 +1,13 @@
 package tetris
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Kaamkiya/gg/internal/app/tetris/color"
+	"github.com/Kaamkiya/gg/internal/app/tetris/engine"
 	"github.com/Kaamkiya/gg/internal/app/tetris/shape"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -13,27 +15,55 @@ import (
 
 type gameProgressTick struct{}
 
-func initialModel() gameState {
+// singlePlayerControls is the keybinding help shown in the sidebar
+var singlePlayerControls = []string{
+	"  hjl/←↓→ to move    ",
+	"  z,x to rotate      ",
+	"  c to hold          ",
+	"  q/ctl+c to quit    ",
+	"  p to pause         ",
+}
+
+// initialModel creates a game with a random seed which is recorded so it can be
+// replayed once finished.
+func initialModel(mode gameMode) gameState {
+	seed := time.Now().UnixNano()
+	gs := newGameState(mode, seed, wallClock)
+	gs.recorder = newReplayRecorder(mode, seed)
+
+	return gs
+}
+
+// newGameState creates a game where the shapes are picked from a randomizer
+// created with seed, and the time is read from clock.
+func newGameState(mode gameMode, seed int64, clock func() time.Time) gameState {
 	return gameState{
+		engine.New(seed),
+		color.Colors,
+		singlePlayerControls,
+		false,
+		false,
+		newGameProgress(mode),
 		nil,
+		clock,
 		nil,
-		newGameboard(color.Colors),
-		shape.NewRandomizer(),
-		0,
-		&difficulty{
-			initialDifficulyCountDown,
-			initialDifficulyLevel,
-			initialGameProgressTickDelay,
-		},
-		false,
-		pieceDrop{
-			dropFinished,
-			false,
-		},
 	}
 }
 
+// wallClock is the clock of live games. It is truncated to milliseconds which
+// is the precision of replays, so that a replayed game sees the same times.
+func wallClock() time.Time {
+	return time.Now().Truncate(time.Millisecond)
+}
+
 func (gs *gameState) Init() tea.Cmd {
+	now := gs.clock()
+	gs.progress.startClock(now)
+
+	if gs.recorder != nil {
+		gs.recorder.start = now
+	}
+
 	return func() tea.Msg {
 		return gameProgressTick{}
 	}
@@ -44,56 +74,93 @@ func (gs *gameState) Init() tea.Cmd {
 //   - Line complete: gameProgressTick -> handleGameProgress -> lineAnimationTick
 //   - Line animation ongoing: lineAnimationTick -> handleLineAnimation -> lineAnimationTick
 //   - Line animation finished: lineAnimationTick -> handleLineAnimation -> gameProgressTick
+//   - Game finished: gameProgressTick -> handleGameProgress -> results screen
+//
+// Every message is recorded before being handled so the game can be replayed.
+// The clock is read once per message, so that the game sees the same time as
+// the replay, which handles the message at the recorded time.
 func (gs *gameState) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	now := gs.clock()
+	if gs.recorder != nil {
+		gs.recorder.record(now, msg)
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		if msg.String() == "ctrl+c" || msg.String() == "q" || msg.String() == "Q" {
-			return gs, tea.Quit
-		} else if !gs.isPaused {
-			switch msg.String() {
-			case "h", "H", "left":
-				gs.handleLeft()
-			case "l", "L", "right":
-				gs.handleRight()
-			case "j", "J", "down":
-				return gs, gs.handleDrop()
-			case "z", "Z":
-				gs.handleLeftRotate()
-			case "x", "X":
-				gs.handleRightRotate()
-			case "p", "P":
-				gs.isPaused = true
-				return gs, nil
-			}
-		} else {
-			if msg.String() == "p" || msg.String() == "P" {
-				gs.isPaused = false
-				return gs, tea.Tick(gs.currentDifficulty.gameProgressTickDelay, func(time.Time) tea.Msg { return gameProgressTick{} })
-			}
-		}
+		return gs, gs.handleKey(msg.String(), now)
 	case gameProgressTick:
-		if gs.isPaused {
-			return gs, nil
-		}
-
-		// Skip a tick as droping a force scheduled a new one
-		if gs.pieceDrop.dropForced {
-			gs.pieceDrop.dropForced = false
-			return gs, nil
-		}
-		return gs, gs.handleGameProgressTick()
+		return gs, gs.handleGameProgressMsg(now)
 	case lineAnimationTick:
-		return gs, gs.handleLineAnimationTick(msg)
+		return gs, gs.handleLineAnimationTick()
 	}
 
 	return gs, nil
 }
 
+func (gs *gameState) handleKey(key string, now time.Time) tea.Cmd {
+	if key == "ctrl+c" || key == "q" || key == "Q" {
+		if gs.recorder != nil {
+			gs.recorder.save()
+		}
+		return tea.Quit
+	} else if gs.progress.isFinished() {
+		return nil
+	} else if !gs.isPaused {
+		switch key {
+		case "h", "H", "left":
+			gs.handleLeft()
+		case "l", "L", "right":
+			gs.handleRight()
+		case "j", "J", "down":
+			return gs.handleDrop(now)
+		case "z", "Z":
+			gs.handleLeftRotate()
+		case "x", "X":
+			gs.handleRightRotate()
+		case "c", "C":
+			return gs.handleHold(now)
+		case "p", "P":
+			gs.isPaused = true
+			gs.progress.stopClock(now)
+		}
+	} else if key == "p" || key == "P" {
+		gs.isPaused = false
+		gs.progress.startClock(now)
+		return gs.nextGameProgressTick()
+	}
+
+	return nil
+}
+
+// handleGameProgressMsg filters out the ticks that must not progress the game
+// before handing them to handleGameProgressTick.
+func (gs *gameState) handleGameProgressMsg(now time.Time) tea.Cmd {
+	if gs.isPaused || gs.progress.isFinished() {
+		return nil
+	}
+
+	// Skip a tick as droping a force scheduled a new one
+	if gs.dropForced {
+		gs.dropForced = false
+		return nil
+	}
+
+	return gs.handleGameProgressTick(now)
+}
+
 // View method creates the view by generating the play area and the sidebar. Although the Tetris board size is
 // defined by Height and Width, the play area is larger. Each Tetris box is 4 characters wide and 2 characters tall
 // so the total play area size is 2 * Height * 4 * Width characters. On each line of the play area, a sidebar
 // line is appended.
 func (gs *gameState) View() string {
+	if gs.progress.isFinished() {
+		return buildResultsScreen(gs)
+	}
+
+	return buildPlayArea(gs)
+}
+
+func buildPlayArea(gs *gameState) string {
 	boardBuilder := strings.Builder{}
 	boardBuilder.Grow((height+2)*(width+2)*8 + 22*14)
 
@@ -138,12 +205,17 @@ func (gs *gameState) View() string {
 func buildGameGrid(gs *gameState) [height * 2]string {
 	gridLines := [height * 2]string{}
 
+	grid := gs.engine.Cells()
+	if gs.lineAnimation != nil {
+		grid = gs.lineAnimation.grid
+	}
+
 	for i := range height {
 		lineBuilder := strings.Builder{}
 		lineBuilder.Grow(width * 4)
 
 		for j := range width {
-			nextChar := gs.gameBoard.Colors[gs.gameBoard.Grid[i][j]].Render("    ")
+			nextChar := gs.colors[grid[i][j]].Render("    ")
 			lineBuilder.WriteString(nextChar)
 		}
 
@@ -155,45 +227,137 @@ func buildGameGrid(gs *gameState) [height * 2]string {
 	return gridLines
 }
 
-func buildSidebar(gs *gameState) [14]string {
-	sidebarLines := [14]string{}
+func buildSidebar(gs *gameState) [25]string {
+	sidebarLines := [25]string{}
 	sidebarLines[0] = "      Next Shape      "
 	sidebarLines[1] = "                      "
 
-	if gs.nextShape != nil {
-		grid := gs.nextShape.GetGrid()
-
-		for i := range 4 {
-			if i >= len(grid) {
-				sidebarLines[i+2] = "                      "
-			} else {
-				lineBuilder := strings.Builder{}
-				spaceLength := (22 - len(grid[i])) / 2
-				lineBuilder.WriteString(strings.Repeat(" ", spaceLength))
-
-				for j := range grid[i] {
-					if grid[i][j] {
-						lineBuilder.WriteString(gs.gameBoard.Colors[gs.nextShape.GetColor()].Render(" "))
-					} else {
-						lineBuilder.WriteString(" ")
-					}
-				}
-				lineBuilder.WriteString(strings.Repeat(" ", spaceLength))
-
-				sidebarLines[i+2] = lineBuilder.String()
-			}
-		}
+	if queue := gs.engine.Queue(); len(queue) != 0 {
+		preview := buildShapePreview(gs, queue[0])
+		copy(sidebarLines[2:], preview[:])
 	}
 
-	scoreStr := strconv.FormatUint(uint64(gs.score), 10)
+	scoreStr := strconv.FormatUint(uint64(gs.engine.Score()), 10)
 	sidebarLines[6] = "                      "
 	sidebarLines[7] = "   Your score is      "
 	sidebarLines[8] = strings.Repeat(" ", 22-len(scoreStr)) + scoreStr
 	sidebarLines[9] = "                      "
-	sidebarLines[10] = "  hjl/←↓→ to move    "
-	sidebarLines[11] = "  z,x to rotate      "
-	sidebarLines[12] = "  q/ctl+c to quit    "
-	sidebarLines[13] = "  p to pause         "
+	sidebarLines[10] = fmt.Sprintf("   %-19s", "Mode: "+gs.progress.mode.String())
+	sidebarLines[11] = fmt.Sprintf("   %-19s", "Lines: "+strconv.Itoa(gs.engine.Lines())+lineGoalSuffix(gs.progress.mode))
+	sidebarLines[12] = fmt.Sprintf("   %-19s", "Time: "+formatDuration(sidebarTime(gs.progress, gs.clock())))
+	sidebarLines[13] = "                      "
+	copy(sidebarLines[14:], gs.controls)
+	sidebarLines[19] = "      Hold Shape      "
+	sidebarLines[20] = "                      "
+
+	if held, ok := gs.engine.Held(); ok {
+		preview := buildShapePreview(gs, held)
+		copy(sidebarLines[21:], preview[:])
+	}
 
 	return sidebarLines
 }
+
+// buildShapePreview renders the shape centered on the 4 lines of the sidebar.
+func buildShapePreview(gs *gameState, previewed shape.Shape) [4]string {
+	lines := [4]string{}
+	grid := previewed.GetGrid()
+
+	for i := range 4 {
+		if i >= len(grid) {
+			lines[i] = "                      "
+		} else {
+			lineBuilder := strings.Builder{}
+			spaceLength := (22 - len(grid[i])) / 2
+			lineBuilder.WriteString(strings.Repeat(" ", spaceLength))
+
+			for j := range grid[i] {
+				if grid[i][j] {
+					lineBuilder.WriteString(gs.colors[previewed.GetColor()].Render(" "))
+				} else {
+					lineBuilder.WriteString(" ")
+				}
+			}
+			lineBuilder.WriteString(strings.Repeat(" ", spaceLength))
+
+			lines[i] = lineBuilder.String()
+		}
+	}
+
+	return lines
+}
+
+func lineGoalSuffix(mode gameMode) string {
+	if goal := mode.lineGoal(); goal > 0 {
+		return "/" + strconv.Itoa(goal)
+	}
+
+	return ""
+}
+
+// sidebarTime returns the time left for timed modes and the time played otherwise.
+func sidebarTime(progress *gameProgress, now time.Time) time.Duration {
+	elapsed := progress.elapsedTime(now)
+	if limit := progress.mode.timeLimit(); limit > 0 {
+		return limit - elapsed
+	}
+
+	return elapsed
+}
+
+// buildResultsScreen shows the outcome of the game followed by the leaderboard
+// of the mode, where the entry of this game is highlighted.
+func buildResultsScreen(gs *gameState) string {
+	progress := gs.progress
+	titleStyle := lipgloss.NewStyle().Bold(true)
+
+	builder := strings.Builder{}
+	builder.WriteString(titleStyle.Render(strings.ToUpper(progress.mode.String()) + " - " + progress.result.String()))
+	builder.WriteString("\n\n")
+	builder.WriteString(fmt.Sprintf("  Score: %d\n", gs.engine.Score()))
+	builder.WriteString(fmt.Sprintf("  Lines: %d\n", gs.engine.Lines()))
+	builder.WriteString(fmt.Sprintf("  Time:  %s\n\n", formatDuration(progress.elapsed)))
+
+	// Replays and AI games are not recorded, only live games have a leaderboard.
+	if gs.recorder != nil {
+		builder.WriteString(buildLeaderboard(progress))
+
+		if gs.recorder.err != nil {
+			builder.WriteString("\n  Replay not saved: " + gs.recorder.err.Error() + "\n")
+		} else if gs.recorder.path != "" {
+			builder.WriteString("\n  Replay saved to " + gs.recorder.path + "\n")
+		}
+	}
+
+	builder.WriteString("\n  q/ctl+c to quit\n")
+
+	return builder.String()
+}
+
+func buildLeaderboard(progress *gameProgress) string {
+	titleStyle := lipgloss.NewStyle().Bold(true)
+	highlightStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#CF6209"))
+
+	builder := strings.Builder{}
+	builder.WriteString(titleStyle.Render("Leaderboard"))
+	builder.WriteString("\n")
+
+	if progress.leaderboardErr != nil {
+		builder.WriteString("  unavailable: " + progress.leaderboardErr.Error() + "\n")
+	}
+
+	if len(progress.leaderboard) == 0 {
+		builder.WriteString("  no entries yet\n")
+	}
+
+	for i, entry := range progress.leaderboard {
+		line := fmt.Sprintf("  %2d. %8d pts %4d lines %10s  %s", i+1, entry.Score, entry.Lines, formatDuration(entry.Time), entry.Date.Format(time.DateOnly))
+		if i == progress.rank {
+			line = highlightStyle.Render(line)
+		}
+
+		builder.WriteString(line + "\n")
+	}
+
+	return builder.String()
+}
@@ -1,307 +1,172 @@
 package tetris
 
 import (
-	"slices"
 	"time"
 
 	"github.com/Kaamkiya/gg/internal/app/tetris/color"
-	"github.com/Kaamkiya/gg/internal/app/tetris/shape"
+	"github.com/Kaamkiya/gg/internal/app/tetris/engine"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 const (
 	// height is the game area height counted in Tetris squares
-	height = 20
+	height = engine.Height
 	// width is the game area height counted in Tetris squares
-	width = 10
+	width = engine.Width
 
 	// initialGameProgressTickDelay is the game loop interval
 	initialGameProgressTickDelay time.Duration = 300 * time.Millisecond
 )
 
-// gameboard represents the Tetris game area. The Grid is a fixed-size array
-// where each box contains a Color. The color of each box is used both for displaying
-// and for calculating if a box is empty where if the box is of color Black, it is
-// considered empty.
-type gameboard struct {
-	Colors map[color.Color]lipgloss.Style
-	Grid   [height][width]color.Color
-}
-
-// gameState contains the application state.
-//   - nextShape is the shape that will be dropped after the current one.
-//   - currentShape is the shape that is being dropped currently.
-//   - gameboard is the playing area
-//   - shapeRandomizer is used to find which shape is going to be dropped next.
+// gameState contains the application state. The rules of the game are
+// implemented by the engine, the gameState schedules its steps and renders it.
+//   - engine is the game being played.
+//   - colors are the styles used to render each color of the board.
+//   - controls is the keybinding help shown in the sidebar.
 //   - isPaused is a flag which is true when the game is paused.
+//   - dropForced is true when a drop locked the piece and scheduled a new tick.
+//   - progress tracks the time the game mode end condition depends on.
+//   - lineAnimation is the ongoing line clearing animation, if any.
+//   - clock returns the current time. Replays drive it from the recorded events.
+//   - recorder records the game to a replay. It is nil when playing a replay back.
 type gameState struct {
-	nextShape         *shape.Shape
-	currentShape      *shape.Shape
-	gameBoard         *gameboard
-	shapeRandomizer   *shape.Randomizer
-	score             uint
-	currentDifficulty *difficulty
-	isPaused          bool
-	pieceDrop         pieceDrop
-}
-
-const (
-	dropIniated dropStatus = iota
-	dropInProgress
-	dropFinished
-)
-
-type dropStatus int
-
-type pieceDrop struct {
-	dropStatus dropStatus
-	dropForced bool
-}
-
-func newGameboard(colors map[color.Color]lipgloss.Style) *gameboard {
-	grid := [height][width]color.Color{}
-
-	return &gameboard{colors, grid}
-}
-
-// handleGameProgressTick updates the game state to simulate the current shape
-// dropping a line. The basic flow is:
-//  1. Create new shapes if needed
-//  2. Drop the current shape one line
-//  3. Check if any lines are completed
-//  4. Start the line clearing animation if needed, otherwise schedule the
-//     the next tick.
-func (gs *gameState) handleGameProgressTick() tea.Cmd {
-	middleX := (width / 2) - 1
-	if gs.nextShape == nil {
-		newShape := shape.CreateNew(middleX, 0, gs.shapeRandomizer)
-		gs.nextShape = &newShape
-	}
-
-	nextCmd := tea.Tick(gs.currentDifficulty.gameProgressTickDelay, func(time.Time) tea.Msg {
-		return gameProgressTick{}
-	})
-
-	if gs.currentShape == nil {
-		newShape := shape.CreateNew(middleX, 0, gs.shapeRandomizer)
-		gs.currentShape = gs.nextShape
-		gs.nextShape = &newShape
-		gs.addShapeToGrid(gs.currentShape)
-		return nextCmd
-	}
-
-	// Give the player a full tick to arrange the piece.
-	if gs.pieceDrop.dropStatus == dropIniated {
-		gs.pieceDrop.dropStatus = dropInProgress
-		return nextCmd
-	}
-
-	if !gs.applyTransformation(gs.currentShape.MoveDown) {
-		gs.adjustDifficulty()
-		_, posY := gs.currentShape.GetPosition()
-		completedLines := gs.checkForCompleteLines(posY, posY+gs.currentShape.GetHeight()-1)
-
-		gs.currentShape = nil
-		gs.pieceDrop.dropStatus = dropFinished
-
-		if len(completedLines) != 0 {
-			lineAnimationMsg := gs.constructLineAnimationMsg(completedLines)
-			return gs.handleLineAnimationTick(lineAnimationMsg)
-		} else if posY == 0 {
-			return tea.Quit
+	engine        *engine.Engine
+	colors        map[color.Color]lipgloss.Style
+	controls      []string
+	isPaused      bool
+	dropForced    bool
+	progress      *gameProgress
+	lineAnimation *lineAnimation
+	clock         func() time.Time
+	recorder      *replayRecorder
+}
+
+// handleGameProgressTick finishes the game if the mode end condition is met,
+// otherwise makes the engine apply gravity to the current shape.
+func (gs *gameState) handleGameProgressTick(now time.Time) tea.Cmd {
+	if result := gs.progress.checkEndCondition(gs.engine.Lines(), now); result != resultNone {
+		return gs.finishGame(result, now)
+	}
+
+	cells := gs.engine.Cells()
+
+	return gs.handleStepEvents(cells, gs.engine.Step(engine.Gravity), now)
+}
+
+// handleStepEvents continues the game loop after the engine applied gravity.
+// cells is the board as it was rendered before the step, which is what the
+// line clearing animation starts from. The flow is:
+//   - Lines cleared: finish the game if they reach the line goal, so that the
+//     clock stops on the step which cleared them, otherwise start the line
+//     clearing animation
+//   - Topped out: finish the game
+//   - Otherwise: schedule the next tick
+func (gs *gameState) handleStepEvents(cells engine.Board, events []engine.Event, now time.Time) tea.Cmd {
+	for _, event := range events {
+		switch event.Kind {
+		case engine.LinesCleared:
+			if result := gs.progress.checkEndCondition(gs.engine.Lines(), now); result != resultNone {
+				return gs.finishGame(result, now)
+			}
+			gs.lineAnimation = gs.constructLineAnimation(cells, event.Lines)
+			return gs.handleLineAnimationTick()
+		case engine.ToppedOut:
+			return gs.finishGame(resultToppedOut, now)
 		}
 	}
 
-	gs.addStillLivingScore()
-
-	return nextCmd
-}
-
-func (gs *gameState) handleLeft() {
-	if gs.currentShape == nil {
-		return
-	}
-
-	gs.applyTransformation(gs.currentShape.MoveLeft)
-}
-
-func (gs *gameState) handleRight() {
-	if gs.currentShape == nil {
-		return
-	}
-
-	gs.applyTransformation(gs.currentShape.MoveRight)
-}
-
-// handleDrop moves immediately the piece to the bottom but the drop is not finished yet.
-// Players will have time equal to another tick to arrange the piece before it is dropped fully.
-// This is achieved with the dropStatus variable.
-// If players press down again they force a drop which causes the piece to drop fully immediately.
-// In that case a new Tick is scheduled to progress the game.
-func (gs *gameState) handleDrop() tea.Cmd {
-	if gs.currentShape == nil {
-		return nil
-	}
-
-	pieceMoved := false
-	for gs.applyTransformation(gs.currentShape.MoveDown) {
-		pieceMoved = true
-		gs.addLivingDangerouslyScore()
-	}
-
-	if pieceMoved {
-		gs.pieceDrop.dropStatus = dropIniated
-		return nil
-	}
-
-	gs.pieceDrop.dropStatus = dropFinished
-	gs.pieceDrop.dropForced = true
-
-	return gs.handleGameProgressTick()
+	return gs.nextGameProgressTick()
 }
 
-func (gs *gameState) handleLeftRotate() {
-	if gs.currentShape == nil {
-		return
-	}
-
-	gs.applyTransformation(gs.currentShape.RotateLeft)
+func (gs *gameState) nextGameProgressTick() tea.Cmd {
+	return tea.Tick(gs.gameProgressTickDelay(), func(time.Time) tea.Msg {
+		return gameProgressTick{}
+	})
 }
 
-func (gs *gameState) handleRightRotate() {
-	if gs.currentShape == nil {
-		return
-	}
-
-	gs.applyTransformation(gs.currentShape.RotateRight)
+// gameProgressTickDelay decreases as the engine level increases.
+func (gs *gameState) gameProgressTickDelay() time.Duration {
+	return time.Duration(float32(initialGameProgressTickDelay) / gs.engine.Level())
 }
 
-func (gs *gameState) applyTransformation(tranformation func() shape.Shape) bool {
-	newShape := tranformation()
+// finishGame stops the game clock, records the result in the leaderboard of
+// the mode and saves the replay. Replays being played back have no recorder and
+// leave the leaderboards untouched. No more ticks are scheduled so the results
+// screen stays up until the player quits.
+func (gs *gameState) finishGame(result gameResult, now time.Time) tea.Cmd {
+	gs.progress.stopClock(now)
+	gs.progress.result = result
 
-	gs.deleteShapeFromGrid(gs.currentShape)
-
-	if gs.isShapeValid(newShape) {
-		gs.currentShape = &newShape
-		gs.addShapeToGrid(gs.currentShape)
-
-		return true
-	} else {
-		gs.addShapeToGrid(gs.currentShape)
+	if gs.recorder != nil {
+		gs.recordResult()
+		gs.recorder.save()
 	}
 
-	return false
+	return nil
 }
 
-// isShapeValid checks if a shape is valid by checking:
-//   - If the shape is inside the gameBoard
-//   - If the shape does not overlap with any occupied box.
-func (gs *gameState) isShapeValid(shape shape.Shape) bool {
-	shapeGrid := shape.GetGrid()
-	posX, posY := shape.GetPosition()
-
-	if posX < 0 {
-		return false
-	}
-
-	if posX+len(shapeGrid[0]) > width || posY+len(shapeGrid) > height {
-		return false
+// handleInput plays an input of the engine with the matching handler.
+func (gs *gameState) handleInput(input engine.Input, now time.Time) tea.Cmd {
+	switch input {
+	case engine.MoveLeft:
+		gs.handleLeft()
+	case engine.MoveRight:
+		gs.handleRight()
+	case engine.RotateLeft:
+		gs.handleLeftRotate()
+	case engine.RotateRight:
+		gs.handleRightRotate()
+	case engine.Drop:
+		return gs.handleDrop(now)
+	case engine.Hold:
+		return gs.handleHold(now)
 	}
 
-	for i := range shapeGrid {
-		for j := range shapeGrid[i] {
-			if shapeGrid[i][j] {
-				if gs.gameBoard.Grid[posY+i][posX+j] != color.None {
-					return false
-				}
-			}
-		}
-	}
-
-	return true
+	return nil
 }
 
-func (gs *gameState) addShapeToGrid(shape *shape.Shape) {
-	gs.modidfyColorGridFromShape(shape, shape.GetColor())
+func (gs *gameState) handleLeft() {
+	gs.engine.Step(engine.MoveLeft)
 }
 
-func (gs *gameState) deleteShapeFromGrid(shape *shape.Shape) {
-	gs.modidfyColorGridFromShape(shape, color.None)
+func (gs *gameState) handleRight() {
+	gs.engine.Step(engine.MoveRight)
 }
 
-func (gs *gameState) modidfyColorGridFromShape(shape *shape.Shape, color color.Color) {
-	shapeGrid := shape.GetGrid()
-	posX, posY := shape.GetPosition()
+// handleDrop moves immediately the piece to the bottom. If the piece was
+// already at the bottom the drop is forced and the piece is locked, in which
+// case the game loop continues from here and the next scheduled tick is skipped.
+func (gs *gameState) handleDrop(now time.Time) tea.Cmd {
+	cells := gs.engine.Cells()
+	events := gs.engine.Step(engine.Drop)
 
-	for i := range shapeGrid {
-		for j := range shapeGrid[i] {
-			if shapeGrid[i][j] {
-				gs.gameBoard.Grid[posY+i][posX+j] = color
-			}
+	for _, event := range events {
+		if event.Kind == engine.Locked {
+			gs.dropForced = true
+			return gs.handleStepEvents(cells, events, now)
 		}
 	}
-}
-
-func (gs *gameState) removeCompletedLines(completedLines []int) {
-	gs.addLineScore(len(completedLines))
-	slices.Sort(completedLines)
-	slices.Reverse(completedLines)
-
-	// lines are removed with a single pass from bottom to top.The completedLines array
-	// is sorted in descending order and the first completed line is replaced by the one
-	// above it. If another completed line is encountered during replacing, the distanceToCopyFrom
-	// is increased to start copying from two places above and so on. The distanceToCopyFrom variable
-	// specifies both the lines to skip when replacing and the index of the next completed line in the
-	// completedLines array.
-	distanceToCopyFrom := 1
-
-	for i := completedLines[0]; i >= 0; i-- {
-		if i-distanceToCopyFrom < 0 {
-			return
-		}
-
-		if gs.isLineEmpty(i) {
-			return
-		}
-
-		for distanceToCopyFrom < len(completedLines) && completedLines[distanceToCopyFrom] == i-distanceToCopyFrom {
-			distanceToCopyFrom++
-		}
 
-		for j := range width {
-			gs.gameBoard.Grid[i][j] = gs.gameBoard.Grid[i-distanceToCopyFrom][j]
-		}
-	}
+	return nil
 }
 
-func (gs *gameState) checkForCompleteLines(from, to int) []int {
-	completedLines := make([]int, 0, 4)
-	for i := to; i >= from; i-- {
-		if gs.isLineCompleted(i) {
-			completedLines = append(completedLines, i)
+// handleHold puts the piece aside. The game is over if the piece coming back
+// does not fit anymore.
+func (gs *gameState) handleHold(now time.Time) tea.Cmd {
+	for _, event := range gs.engine.Step(engine.Hold) {
+		if event.Kind == engine.ToppedOut {
+			return gs.finishGame(resultToppedOut, now)
 		}
 	}
 
-	return completedLines
+	return nil
 }
 
-func (gs *gameState) isLineCompleted(line int) bool {
-	for i := range width {
-		if gs.gameBoard.Grid[line][i] == color.None {
-			return false
-		}
-	}
-
-	return true
+func (gs *gameState) handleLeftRotate() {
+	gs.engine.Step(engine.RotateLeft)
 }
 
-func (gs *gameState) isLineEmpty(line int) bool {
-	for i := range width {
-		if gs.gameBoard.Grid[line][i] != color.None {
-			return false
-		}
-	}
-
-	return true
+func (gs *gameState) handleRightRotate() {
+	gs.engine.Step(engine.RotateRight)
 }
@@ -0,0 +1,131 @@
+package tetris
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// leaderboardSize is the number of entries kept for each mode
+const leaderboardSize = 10
+
+type leaderboardEntry struct {
+	Score uint          `json:"score"`
+	Lines int           `json:"lines"`
+	Time  time.Duration `json:"time"`
+	Date  time.Time     `json:"date"`
+}
+
+// leaderboards holds the best results of each mode, keyed by the mode name.
+type leaderboards map[string][]leaderboardEntry
+
+func leaderboardPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "gg", "tetris_leaderboards.json"), nil
+}
+
+// loadLeaderboards reads the leaderboards from path. A missing file is not an
+// error and results in empty leaderboards.
+func loadLeaderboards(path string) (leaderboards, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return leaderboards{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	boards := leaderboards{}
+	if err := json.Unmarshal(data, &boards); err != nil {
+		return nil, err
+	}
+
+	return boards, nil
+}
+
+func (l leaderboards) save(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+// add inserts the entry in the leaderboard of the mode and returns its rank
+// starting at 0, or -1 if the entry did not make it to the leaderboard.
+// Sprint entries are ranked by time, every other mode is ranked by score.
+func (l leaderboards) add(mode gameMode, entry leaderboardEntry) int {
+	entries := l[mode.String()]
+
+	rank, _ := slices.BinarySearchFunc(entries, entry, func(a, b leaderboardEntry) int {
+		if mode == modeSprint {
+			if a.Time <= b.Time {
+				return -1
+			}
+			return 1
+		}
+
+		if a.Score >= b.Score {
+			return -1
+		}
+		return 1
+	})
+
+	if rank >= leaderboardSize {
+		return -1
+	}
+
+	entries = slices.Insert(entries, rank, entry)
+	if len(entries) > leaderboardSize {
+		entries = entries[:leaderboardSize]
+	}
+
+	l[mode.String()] = entries
+
+	return rank
+}
+
+// recordResult adds the finished game to the leaderboard of its mode. Sprints
+// and marathons that topped out never reached their goal and are not recorded.
+func (gs *gameState) recordResult() {
+	progress := gs.progress
+
+	path, err := leaderboardPath()
+	if err != nil {
+		progress.leaderboardErr = err
+		return
+	}
+
+	boards, err := loadLeaderboards(path)
+	if err != nil {
+		progress.leaderboardErr = err
+		return
+	}
+
+	if progress.result != resultToppedOut || progress.mode.lineGoal() == 0 {
+		progress.rank = boards.add(progress.mode, leaderboardEntry{
+			Score: gs.engine.Score(),
+			Lines: gs.engine.Lines(),
+			Time:  progress.elapsed,
+			Date:  time.Now(),
+		})
+
+		if progress.rank >= 0 {
+			progress.leaderboardErr = boards.save(path)
+		}
+	}
+
+	progress.leaderboard = boards[progress.mode.String()]
+}
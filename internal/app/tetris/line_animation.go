@@ -1,27 +1,33 @@
 package tetris
 
 import (
-	"maps"
-	"slices"
 	"time"
 
 	"github.com/Kaamkiya/gg/internal/app/tetris/color"
+	"github.com/Kaamkiya/gg/internal/app/tetris/engine"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 // lineAnimationInterval is the animation refresh interval
 const lineAnimationInterval time.Duration = 100 * time.Millisecond
 
-// lineAnimationTick is a tea.Msg that contains the lines to change in a map
-// where the key is the line index and the value is the colors to apply.
-// Additionally, it holds how many animations (color changes) are left for the
-// animation to complete.
-type lineAnimationTick struct {
+// lineAnimationTick is a tea.Msg that advances the ongoing line animation.
+type lineAnimationTick struct{}
+
+// lineAnimation contains the lines to change in a map where the key is the
+// line index and the value is the colors to apply. Additionally, it holds how
+// many animations (color changes) are left for the animation to complete.
+// The engine has already removed the completed lines, so the animation is
+// played on grid, a copy of the board from before they were removed.
+// It is kept in the gameState rather than in the lineAnimationTick so that
+// replaying the sequence of messages reproduces the animation.
+type lineAnimation struct {
+	grid               engine.Board
 	linesToUpdate      map[int][width]color.Color
 	animationCountDown int
 }
 
-func (gs *gameState) constructLineAnimationMsg(completedLines []int) lineAnimationTick {
+func (gs *gameState) constructLineAnimation(grid engine.Board, completedLines []int) *lineAnimation {
 	completedLineMap := make(map[int][width]color.Color, len(completedLines))
 	animationCountdown := 2
 
@@ -40,10 +46,10 @@ func (gs *gameState) constructLineAnimationMsg(completedLines []int) lineAnimati
 
 	for _, v := range completedLines {
 		completedLineMap[v] = highlightedLine
-
 	}
 
-	return lineAnimationTick{
+	return &lineAnimation{
+		grid,
 		completedLineMap,
 		animationCountdown,
 	}
@@ -51,27 +57,28 @@ func (gs *gameState) constructLineAnimationMsg(completedLines []int) lineAnimati
 
 // handleLineAnimationTick performs the grid updates for the flashing animation when
 // lines are completed. If the animation is complete (animationCountDown set to 0) it
-// resumes the game. Otherwse it swaps the lines color and continues with the animation.
-func (gs *gameState) handleLineAnimationTick(animationTick lineAnimationTick) tea.Cmd {
-	if animationTick.animationCountDown == 0 {
-		gs.removeCompletedLines(slices.Collect(maps.Keys(animationTick.linesToUpdate)))
+// resumes the game with the board of the engine. Otherwse it swaps the lines color and
+// continues with the animation.
+func (gs *gameState) handleLineAnimationTick() tea.Cmd {
+	animation := gs.lineAnimation
+	if animation == nil {
+		return nil
+	}
+
+	if animation.animationCountDown == 0 {
+		gs.lineAnimation = nil
 		return func() tea.Msg {
 			return gameProgressTick{}
 		}
 	}
 
-	animationTick.animationCountDown--
-	newLinesToUpdateMap := make(map[int][width]color.Color, len(animationTick.linesToUpdate))
-	for k, v := range animationTick.linesToUpdate {
-		newLinesToUpdateMap[k] = gs.gameBoard.Grid[k]
-		gs.gameBoard.Grid[k] = v
+	animation.animationCountDown--
+	for k, v := range animation.linesToUpdate {
+		animation.linesToUpdate[k] = animation.grid[k]
+		animation.grid[k] = v
 	}
 
 	return tea.Tick(lineAnimationInterval, func(time.Time) tea.Msg {
-		return lineAnimationTick{
-			newLinesToUpdateMap,
-			animationTick.animationCountDown,
-		}
+		return lineAnimationTick{}
 	})
 }
-
@@ -0,0 +1,195 @@
+package tetris
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// sprintLineGoal is the number of lines to clear to finish a sprint
+	sprintLineGoal = 40
+	// marathonLineGoal is the number of lines to clear to finish a marathon
+	marathonLineGoal = 150
+	// ultraDuration is the time available to score points in ultra mode
+	ultraDuration = 2 * time.Minute
+)
+
+// gameMode selects the end condition of a game.
+//   - modeClassic runs until the player tops out.
+//   - modeSprint ends when sprintLineGoal lines are cleared, the time is what counts.
+//   - modeUltra ends after ultraDuration, the score is what counts.
+//   - modeMarathon ends when marathonLineGoal lines are cleared.
+//   - modeVersus ends when one of the two players tops out.
+type gameMode int
+
+const (
+	modeClassic gameMode = iota
+	modeSprint
+	modeUltra
+	modeMarathon
+	modeVersus
+)
+
+// gameModes are the single player modes
+var gameModes = []gameMode{modeClassic, modeSprint, modeUltra, modeMarathon}
+
+func (m gameMode) String() string {
+	switch m {
+	case modeSprint:
+		return "sprint"
+	case modeUltra:
+		return "ultra"
+	case modeMarathon:
+		return "marathon"
+	case modeVersus:
+		return "versus"
+	default:
+		return "classic"
+	}
+}
+
+func (m gameMode) description() string {
+	switch m {
+	case modeSprint:
+		return fmt.Sprintf("clear %d lines as fast as possible", sprintLineGoal)
+	case modeUltra:
+		return fmt.Sprintf("score as much as possible in %s", formatDuration(ultraDuration))
+	case modeMarathon:
+		return fmt.Sprintf("clear %d lines", marathonLineGoal)
+	case modeVersus:
+		return "send garbage to your opponent until they top out"
+	default:
+		return "play until you top out"
+	}
+}
+
+// lineGoal returns the number of lines that finishes the game, or 0 if the
+// mode is not won by clearing lines.
+func (m gameMode) lineGoal() int {
+	switch m {
+	case modeSprint:
+		return sprintLineGoal
+	case modeMarathon:
+		return marathonLineGoal
+	default:
+		return 0
+	}
+}
+
+// timeLimit returns how long the game lasts, or 0 if the mode is not timed.
+func (m gameMode) timeLimit() time.Duration {
+	if m == modeUltra {
+		return ultraDuration
+	}
+
+	return 0
+}
+
+const (
+	resultNone gameResult = iota
+	resultToppedOut
+	resultGoalReached
+	resultTimeUp
+)
+
+type gameResult int
+
+func (r gameResult) String() string {
+	switch r {
+	case resultToppedOut:
+		return "Topped out"
+	case resultGoalReached:
+		return "Goal reached"
+	case resultTimeUp:
+		return "Time's up"
+	default:
+		return ""
+	}
+}
+
+// gameProgress keeps track of the time the mode end conditions depend on.
+// The clock only runs while the game is not paused: elapsed holds the time
+// played before the last resume and resumedAt when that resume happened.
+// Once the game is finished, leaderboard holds the mode leaderboard for the
+// results screen and rank the position of this game in it (-1 if absent).
+type gameProgress struct {
+	mode           gameMode
+	elapsed        time.Duration
+	resumedAt      time.Time
+	running        bool
+	result         gameResult
+	leaderboard    []leaderboardEntry
+	rank           int
+	leaderboardErr error
+}
+
+func newGameProgress(mode gameMode) *gameProgress {
+	return &gameProgress{mode: mode, rank: -1}
+}
+
+func (p *gameProgress) startClock(now time.Time) {
+	if p.running {
+		return
+	}
+
+	p.resumedAt = now
+	p.running = true
+}
+
+func (p *gameProgress) stopClock(now time.Time) {
+	if !p.running {
+		return
+	}
+
+	p.elapsed += now.Sub(p.resumedAt)
+	p.running = false
+}
+
+func (p *gameProgress) elapsedTime(now time.Time) time.Duration {
+	if p.running {
+		return p.elapsed + now.Sub(p.resumedAt)
+	}
+
+	return p.elapsed
+}
+
+// checkEndCondition returns the result of the game if the mode end condition
+// has been met with the given number of cleared lines and resultNone otherwise.
+func (p *gameProgress) checkEndCondition(linesCleared int, now time.Time) gameResult {
+	if goal := p.mode.lineGoal(); goal > 0 && linesCleared >= goal {
+		return resultGoalReached
+	}
+
+	if limit := p.mode.timeLimit(); limit > 0 && p.elapsedTime(now) >= limit {
+		return resultTimeUp
+	}
+
+	return resultNone
+}
+
+func (p *gameProgress) isFinished() bool {
+	return p.result != resultNone
+}
+
+// formatDuration formats a duration as m:ss.cc
+func formatDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+
+	minutes := int(d / time.Minute)
+	seconds := int(d % time.Minute / time.Second)
+	centiseconds := int(d % time.Second / (10 * time.Millisecond))
+
+	return fmt.Sprintf("%d:%02d.%02d", minutes, seconds, centiseconds)
+}
+
+func parseGameMode(name string) (gameMode, error) {
+	for _, mode := range gameModes {
+		if mode.String() == name {
+			return mode, nil
+		}
+	}
+
+	return modeClassic, fmt.Errorf("unknown game mode %q", name)
+}
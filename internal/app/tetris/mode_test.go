@@ -0,0 +1,76 @@
+package tetris
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSprintEndsWhenLineGoalIsReached(t *testing.T) {
+	progress := newGameProgress(modeSprint)
+
+	if progress.checkEndCondition(sprintLineGoal-1, time.Now()) != resultNone {
+		t.Fatal("Sprint should not end before the line goal is reached")
+	}
+
+	if progress.checkEndCondition(sprintLineGoal, time.Now()) != resultGoalReached {
+		t.Fatal("Sprint should end when the line goal is reached")
+	}
+}
+
+func TestUltraEndsWhenTimeIsUp(t *testing.T) {
+	progress := newGameProgress(modeUltra)
+	start := time.Now()
+	progress.startClock(start)
+
+	if progress.checkEndCondition(0, start.Add(ultraDuration-time.Second)) != resultNone {
+		t.Fatal("Ultra should not end before the time is up")
+	}
+
+	if progress.checkEndCondition(0, start.Add(ultraDuration)) != resultTimeUp {
+		t.Fatal("Ultra should end when the time is up")
+	}
+}
+
+func TestClockDoesNotRunWhilePaused(t *testing.T) {
+	progress := newGameProgress(modeUltra)
+	start := time.Now()
+
+	progress.startClock(start)
+	progress.stopClock(start.Add(time.Minute))
+	progress.startClock(start.Add(time.Hour))
+
+	if elapsed := progress.elapsedTime(start.Add(time.Hour + time.Minute)); elapsed != 2*time.Minute {
+		t.Fatal("Expected 2 minutes of play but got " + elapsed.String())
+	}
+}
+
+func TestSprintLeaderboardIsRankedByTime(t *testing.T) {
+	boards := leaderboards{}
+
+	boards.add(modeSprint, leaderboardEntry{Score: 100, Time: 2 * time.Minute})
+	rank := boards.add(modeSprint, leaderboardEntry{Score: 10, Time: time.Minute})
+
+	if rank != 0 || boards["sprint"][0].Time != time.Minute {
+		t.Fatal("The fastest sprint should be first in the leaderboard")
+	}
+}
+
+func TestLeaderboardKeepsOnlyTheBestEntries(t *testing.T) {
+	boards := leaderboards{}
+
+	for i := range leaderboardSize {
+		boards.add(modeUltra, leaderboardEntry{Score: uint(100 + i)})
+	}
+
+	if rank := boards.add(modeUltra, leaderboardEntry{Score: 1}); rank != -1 {
+		t.Fatal("A score lower than every entry of a full leaderboard should not be ranked")
+	}
+
+	if rank := boards.add(modeUltra, leaderboardEntry{Score: 1000}); rank != 0 {
+		t.Fatal("The best score should be ranked first")
+	}
+
+	if len(boards["ultra"]) != leaderboardSize {
+		t.Fatal("The leaderboard should not grow past its size")
+	}
+}
@@ -0,0 +1,155 @@
+package tetris
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// playbackSpeeds are the speeds a replay can be played at
+var playbackSpeeds = []float64{0.5, 1, 2, 4, 8}
+
+// playbackTick is the tea.Msg that applies the next event of the replay.
+// Ticks from a previous generation were scheduled before the last pause or
+// speed change and are ignored.
+type playbackTick struct {
+	generation int
+}
+
+// replayPlayer plays a replay back by applying its events to a fresh gameState
+// at the time they were recorded. The game clock follows the event offsets
+// instead of the wall clock so timed modes end exactly like the recorded game.
+type replayPlayer struct {
+	game       *gameState
+	replay     replay
+	nextEvent  int
+	start      time.Time
+	now        time.Time
+	isPaused   bool
+	speedIndex int
+	generation int
+}
+
+func newReplayPlayer(r replay) *replayPlayer {
+	player := &replayPlayer{
+		replay:     r,
+		start:      time.Unix(0, 0),
+		speedIndex: 1,
+	}
+	player.now = player.start
+
+	game := newGameState(r.mode, r.seed, func() time.Time { return player.now })
+	player.game = &game
+
+	return player
+}
+
+func (p *replayPlayer) Init() tea.Cmd {
+	// The first tick of the game is part of the replay events.
+	p.game.Init()
+
+	return p.scheduleNextEvent()
+}
+
+func (p *replayPlayer) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "Q":
+			return p, tea.Quit
+		case " ", "p", "P":
+			p.isPaused = !p.isPaused
+			p.generation++
+			if !p.isPaused {
+				return p, p.scheduleNextEvent()
+			}
+		case "+", "=":
+			return p, p.changeSpeed(1)
+		case "-", "_":
+			return p, p.changeSpeed(-1)
+		case "n", "N", ".":
+			if p.isPaused {
+				p.applyNextEvent()
+			}
+		}
+	case playbackTick:
+		if msg.generation != p.generation || p.isPaused {
+			return p, nil
+		}
+
+		p.applyNextEvent()
+		return p, p.scheduleNextEvent()
+	}
+
+	return p, nil
+}
+
+func (p *replayPlayer) changeSpeed(change int) tea.Cmd {
+	p.speedIndex = min(max(p.speedIndex+change, 0), len(playbackSpeeds)-1)
+	p.generation++
+
+	if p.isPaused {
+		return nil
+	}
+
+	return p.scheduleNextEvent()
+}
+
+func (p *replayPlayer) applyNextEvent() {
+	if p.isFinished() {
+		return
+	}
+
+	event := p.replay.events[p.nextEvent]
+	p.now = p.start.Add(event.offset)
+	p.game.applyEvent(event)
+	p.nextEvent++
+}
+
+func (p *replayPlayer) scheduleNextEvent() tea.Cmd {
+	if p.isFinished() {
+		return nil
+	}
+
+	delay := p.start.Add(p.replay.events[p.nextEvent].offset).Sub(p.now)
+	delay = time.Duration(float64(delay) / playbackSpeeds[p.speedIndex])
+	generation := p.generation
+
+	return tea.Tick(delay, func(time.Time) tea.Msg {
+		return playbackTick{generation}
+	})
+}
+
+func (p *replayPlayer) isFinished() bool {
+	return p.nextEvent >= len(p.replay.events)
+}
+
+// View shows the replayed game with the playback status and controls below it.
+func (p *replayPlayer) View() string {
+	status := "playing"
+	if p.isFinished() {
+		status = "finished"
+	} else if p.isPaused {
+		status = "paused"
+	}
+
+	statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#2692E8"))
+
+	builder := strings.Builder{}
+	builder.WriteString(p.game.View())
+	builder.WriteString("\n")
+	builder.WriteString(statusStyle.Render(fmt.Sprintf(
+		"REPLAY %s  x%g  %s  event %d/%d",
+		p.replay.mode,
+		playbackSpeeds[p.speedIndex],
+		status,
+		p.nextEvent,
+		len(p.replay.events),
+	)))
+	builder.WriteString("\n  space/p to pause, n to step, +/- to change speed, q/ctl+c to quit\n")
+
+	return builder.String()
+}
@@ -0,0 +1,279 @@
+package tetris
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// replayHeader is the first line of every replay file and holds the format version
+const replayHeader = "gg-tetris-replay 1"
+
+// replayExtension is the file extension of saved replays
+const replayExtension = ".replay"
+
+type replayEventKind byte
+
+const (
+	eventProgressTick  replayEventKind = 'T'
+	eventAnimationTick replayEventKind = 'A'
+	eventKey           replayEventKind = 'K'
+)
+
+// replayEvent is a message handled by gameState.Update. The offset is the time
+// elapsed since the start of the game when the message was handled.
+type replayEvent struct {
+	offset time.Duration
+	kind   replayEventKind
+	key    string
+}
+
+// replay contains everything needed to play a game again: the shapes are
+// reproduced from the seed and the player actions from the events, which are
+// applied in order to a fresh gameState.
+//
+// A replay is stored as text. After the header, the mode and the seed, each
+// line holds one event as the milliseconds elapsed since the previous event,
+// the event kind and, for key events, the key:
+//
+//	gg-tetris-replay 1
+//	mode sprint
+//	seed 42
+//	0 T
+//	300 T
+//	52 K left
+type replay struct {
+	mode   gameMode
+	seed   int64
+	events []replayEvent
+}
+
+// replayRecorder collects the events of the game being played and saves them
+// once the game is over.
+type replayRecorder struct {
+	replay replay
+	start  time.Time
+	path   string
+	err    error
+}
+
+func newReplayRecorder(mode gameMode, seed int64) *replayRecorder {
+	return &replayRecorder{replay: replay{mode: mode, seed: seed}}
+}
+
+func (r *replayRecorder) record(now time.Time, msg tea.Msg) {
+	event := replayEvent{offset: now.Sub(r.start)}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		event.kind = eventKey
+		event.key = msg.String()
+	case gameProgressTick:
+		event.kind = eventProgressTick
+	case lineAnimationTick:
+		event.kind = eventAnimationTick
+	default:
+		return
+	}
+
+	r.replay.events = append(r.replay.events, event)
+}
+
+// save writes the replay to the replay directory. It only happens once, so
+// quitting from the results screen does not save the same game twice.
+func (r *replayRecorder) save() {
+	if r.path != "" || r.err != nil {
+		return
+	}
+
+	dir, err := replayDir()
+	if err != nil {
+		r.err = err
+		return
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		r.err = err
+		return
+	}
+
+	path := filepath.Join(dir, r.start.Format("20060102-150405")+"-"+r.replay.mode.String()+replayExtension)
+
+	file, err := os.Create(path)
+	if err != nil {
+		r.err = err
+		return
+	}
+
+	if err := r.replay.encode(file); err != nil {
+		file.Close()
+		r.err = err
+		return
+	}
+
+	// The replay may only reach the disk on close, which must not fail silently
+	if err := file.Close(); err != nil {
+		r.err = err
+		return
+	}
+
+	r.path = path
+}
+
+// applyEvent feeds an event to the game the same way gameState.Update did when
+// it was recorded. The returned commands are ignored as the following ticks are
+// part of the replay.
+func (gs *gameState) applyEvent(event replayEvent) {
+	now := gs.clock()
+
+	switch event.kind {
+	case eventKey:
+		gs.handleKey(event.key, now)
+	case eventProgressTick:
+		gs.handleGameProgressMsg(now)
+	case eventAnimationTick:
+		gs.handleLineAnimationTick()
+	}
+}
+
+func (r replay) encode(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+
+	fmt.Fprintln(writer, replayHeader)
+	fmt.Fprintln(writer, "mode", r.mode.String())
+	fmt.Fprintln(writer, "seed", r.seed)
+
+	previous := time.Duration(0)
+	for _, event := range r.events {
+		delta := (event.offset - previous).Milliseconds()
+		previous = event.offset
+
+		if event.kind == eventKey {
+			fmt.Fprintf(writer, "%d %c %s\n", delta, event.kind, event.key)
+		} else {
+			fmt.Fprintf(writer, "%d %c\n", delta, event.kind)
+		}
+	}
+
+	return writer.Flush()
+}
+
+func decodeReplay(r io.Reader) (replay, error) {
+	decoded := replay{}
+	scanner := bufio.NewScanner(r)
+
+	readLine := func(prefix string) (string, error) {
+		if !scanner.Scan() {
+			return "", errors.New("replay is truncated")
+		}
+
+		value, found := strings.CutPrefix(scanner.Text(), prefix)
+		if !found {
+			return "", fmt.Errorf("expected %q in replay, got %q", prefix, scanner.Text())
+		}
+
+		return value, nil
+	}
+
+	if _, err := readLine(replayHeader); err != nil {
+		return decoded, err
+	}
+
+	modeName, err := readLine("mode ")
+	if err != nil {
+		return decoded, err
+	}
+
+	mode, err := parseGameMode(modeName)
+	if err != nil {
+		return decoded, err
+	}
+	decoded.mode = mode
+
+	seed, err := readLine("seed ")
+	if err != nil {
+		return decoded, err
+	}
+
+	decoded.seed, err = strconv.ParseInt(seed, 10, 64)
+	if err != nil {
+		return decoded, fmt.Errorf("invalid replay seed: %w", err)
+	}
+
+	offset := time.Duration(0)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), " ", 3)
+		if len(fields) < 2 || len(fields[1]) != 1 {
+			return decoded, fmt.Errorf("invalid replay event %q", scanner.Text())
+		}
+
+		delta, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return decoded, fmt.Errorf("invalid replay event %q: %w", scanner.Text(), err)
+		}
+		offset += time.Duration(delta) * time.Millisecond
+
+		event := replayEvent{offset: offset, kind: replayEventKind(fields[1][0])}
+		switch event.kind {
+		case eventKey:
+			if len(fields) != 3 {
+				return decoded, fmt.Errorf("missing key in replay event %q", scanner.Text())
+			}
+			event.key = fields[2]
+		case eventProgressTick, eventAnimationTick:
+		default:
+			return decoded, fmt.Errorf("unknown replay event %q", scanner.Text())
+		}
+
+		decoded.events = append(decoded.events, event)
+	}
+
+	return decoded, scanner.Err()
+}
+
+func loadReplay(path string) (replay, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return replay{}, err
+	}
+	defer file.Close()
+
+	return decodeReplay(file)
+}
+
+func replayDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "gg", "tetris_replays"), nil
+}
+
+// listReplays returns the paths of the saved replays, newest first.
+func listReplays() ([]string, error) {
+	dir, err := replayDir()
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+replayExtension))
+	if err != nil {
+		return nil, err
+	}
+
+	// File names start with the date so sorting them sorts by date.
+	slices.Sort(paths)
+	slices.Reverse(paths)
+
+	return paths, nil
+}
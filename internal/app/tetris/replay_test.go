@@ -0,0 +1,102 @@
+package tetris
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// recordGame plays a short game with a fake clock and returns it with its replay.
+func recordGame(t *testing.T) (*gameState, replay) {
+	t.Helper()
+
+	// Keep the leaderboards and replays of finished games out of the user config.
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	t.Setenv("HOME", configDir)
+	t.Setenv("AppData", configDir)
+
+	now := time.Unix(1000, 0)
+	gs := newGameState(modeSprint, 42, func() time.Time { return now })
+	gs.recorder = newReplayRecorder(modeSprint, 42)
+	gs.Init()
+
+	keys := []tea.KeyMsg{
+		{Type: tea.KeyLeft},
+		{Type: tea.KeyRunes, Runes: []rune("z")},
+		{Type: tea.KeyRight},
+		{Type: tea.KeyRunes, Runes: []rune("x")},
+		{Type: tea.KeyDown},
+	}
+
+	for i := range 200 {
+		now = now.Add(300 * time.Millisecond)
+		gs.Update(gameProgressTick{})
+
+		if gs.lineAnimation != nil {
+			gs.Update(lineAnimationTick{})
+		}
+
+		now = now.Add(17 * time.Millisecond)
+		gs.Update(keys[i%len(keys)])
+	}
+
+	return &gs, gs.recorder.replay
+}
+
+func TestReplayIsEncodedAndDecoded(t *testing.T) {
+	_, recorded := recordGame(t)
+
+	buffer := bytes.Buffer{}
+	if err := recorded.encode(&buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := decodeReplay(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.mode != recorded.mode || decoded.seed != recorded.seed || len(decoded.events) != len(recorded.events) {
+		t.Fatal("Decoded replay header does not match the recorded one")
+	}
+
+	for i := range recorded.events {
+		if decoded.events[i] != recorded.events[i] {
+			t.Fatalf("Decoded event %d is %v but %v was recorded", i, decoded.events[i], recorded.events[i])
+		}
+	}
+}
+
+func TestReplayPlaybackReproducesTheGame(t *testing.T) {
+	recordedGame, recorded := recordGame(t)
+
+	player := newReplayPlayer(recorded)
+	player.Init()
+
+	for !player.isFinished() {
+		player.applyNextEvent()
+	}
+
+	if player.game.engine.Cells() != recordedGame.engine.Cells() {
+		t.Fatal("Replayed board differs from the recorded game")
+	}
+
+	if player.game.engine.Score() != recordedGame.engine.Score() {
+		t.Fatal("Replayed score differs from the recorded game")
+	}
+
+	if player.game.progress.elapsedTime(player.now) != recordedGame.progress.elapsedTime(recordedGame.clock()) {
+		t.Fatal("Replayed game clock differs from the recorded game")
+	}
+}
+
+func TestInvalidReplayIsRejected(t *testing.T) {
+	invalid := replayHeader + "\nmode sprint\nseed 42\n10 X\n"
+
+	if _, err := decodeReplay(bytes.NewBufferString(invalid)); err == nil {
+		t.Fatal("Unknown events should be rejected")
+	}
+}
@@ -9,14 +9,15 @@ import (
 // here: https://tetris.fandom.com/wiki/TGM_randomizer
 type Randomizer struct {
 	lastValues []int
+	rng        *rand.Rand
 }
 
 func (r *Randomizer) nextInt(maxValue int) int {
-	nextShape := rand.Intn(maxValue)
+	nextShape := r.rng.Intn(maxValue)
 
 	retries := 0
 	for retries < 6 && slices.Contains(r.lastValues, nextShape) {
-		nextShape = rand.Intn(maxValue)
+		nextShape = r.rng.Intn(maxValue)
 		retries++
 	}
 
@@ -27,6 +28,12 @@ func (r *Randomizer) nextInt(maxValue int) int {
 }
 
 func NewRandomizer() *Randomizer {
+	return NewSeededRandomizer(rand.Int63())
+}
+
+// NewSeededRandomizer creates a Randomizer which always produces the same
+// sequence of shapes for the same seed.
+func NewSeededRandomizer(seed int64) *Randomizer {
 	lastValues := make([]int, 4)
 
 	lastValues[0] = Z
@@ -36,5 +43,6 @@ func NewRandomizer() *Randomizer {
 
 	return &Randomizer{
 		lastValues,
+		rand.New(rand.NewSource(seed)),
 	}
 }
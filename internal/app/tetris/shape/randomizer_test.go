@@ -32,3 +32,14 @@ func TestNewRandomizerUpdatesStateCorrectlyOnNewInt(t *testing.T) {
 	}
 
 }
+
+func TestSeededRandomizersProduceTheSameShapes(t *testing.T) {
+	first := NewSeededRandomizer(42)
+	second := NewSeededRandomizer(42)
+
+	for range 100 {
+		if first.nextInt(7) != second.nextInt(7) {
+			t.Fatal("Randomizers with the same seed should produce the same sequence")
+		}
+	}
+}
@@ -3,12 +3,75 @@ package tetris
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/Kaamkiya/gg/internal/app/tetris/ai"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+)
+
+// Menu values of the choices which are not game modes
+const (
+	watchReplay = "replay"
+	watchAi     = "ai-demo"
+	benchmarkAi = "ai-benchmark"
+)
+
+const (
+	// benchmarkSeed is the seed of the first benchmark game, so that results are comparable
+	benchmarkSeed = 1
+	// benchmarkMaxPieces stops benchmark games which would otherwise never end
+	benchmarkMaxPieces = 1000
 )
 
 func Run() {
-	initialModel := initialModel()
+	var choice string
+
+	options := make([]huh.Option[string], 0, len(gameModes)+4)
+	for _, m := range gameModes {
+		options = append(options, huh.NewOption(m.String()+" - "+m.description(), m.String()))
+	}
+	options = append(options,
+		huh.NewOption(modeVersus.String()+" (2 player) - "+modeVersus.description(), modeVersus.String()),
+		huh.NewOption("watch a replay", watchReplay),
+		huh.NewOption("watch the AI play", watchAi),
+		huh.NewOption("benchmark the AI", benchmarkAi),
+	)
+
+	err := huh.NewSelect[string]().
+		Title("choose a mode:").
+		Options(options...).
+		Value(&choice).
+		Run()
+	if err != nil {
+		fmt.Printf("An error: %v", err)
+		os.Exit(1)
+	}
+
+	switch choice {
+	case watchReplay:
+		runReplay()
+		return
+	case watchAi:
+		runDemo()
+		return
+	case benchmarkAi:
+		runBenchmark()
+		return
+	case modeVersus.String():
+		runVersus()
+		return
+	}
+
+	mode, err := parseGameMode(choice)
+	if err != nil {
+		fmt.Printf("An error: %v", err)
+		os.Exit(1)
+	}
+
+	initialModel := initialModel(mode)
 	p := tea.NewProgram(&initialModel)
 
 	if _, err := p.Run(); err != nil {
@@ -18,3 +81,99 @@ func Run() {
 
 	fmt.Println("")
 }
+
+func runReplay() {
+	paths, err := listReplays()
+	if err != nil {
+		fmt.Printf("An error: %v", err)
+		os.Exit(1)
+	}
+
+	if len(paths) == 0 {
+		fmt.Println("No replays saved yet, finish a game to save one.")
+		return
+	}
+
+	var path string
+
+	options := make([]huh.Option[string], 0, len(paths))
+	for _, p := range paths {
+		options = append(options, huh.NewOption(filepath.Base(p), p))
+	}
+
+	err = huh.NewSelect[string]().
+		Title("choose a replay:").
+		Options(options...).
+		Value(&path).
+		Run()
+	if err != nil {
+		fmt.Printf("An error: %v", err)
+		os.Exit(1)
+	}
+
+	replay, err := loadReplay(path)
+	if err != nil {
+		fmt.Printf("An error: %v", err)
+		os.Exit(1)
+	}
+
+	p := tea.NewProgram(newReplayPlayer(replay))
+
+	if _, err := p.Run(); err != nil {
+		fmt.Printf("An error: %v", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("")
+}
+
+func runVersus() {
+	p := tea.NewProgram(newVersusGame())
+
+	if _, err := p.Run(); err != nil {
+		fmt.Printf("An error: %v", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("")
+}
+
+func runDemo() {
+	p := tea.NewProgram(newDemoPlayer())
+
+	if _, err := p.Run(); err != nil {
+		fmt.Printf("An error: %v", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("")
+}
+
+func runBenchmark() {
+	gamesInput := "100"
+
+	err := huh.NewInput().
+		Title("number of games:").
+		Value(&gamesInput).
+		Validate(func(s string) error {
+			games, err := strconv.Atoi(s)
+			if err != nil || games <= 0 {
+				return fmt.Errorf("enter a positive number")
+			}
+			return nil
+		}).
+		Run()
+	if err != nil {
+		fmt.Printf("An error: %v", err)
+		os.Exit(1)
+	}
+
+	games, _ := strconv.Atoi(gamesInput)
+
+	fmt.Printf("Playing %d games of at most %d pieces...\n", games, benchmarkMaxPieces)
+	result := ai.New(ai.DefaultWeights).Benchmark(games, benchmarkSeed, benchmarkMaxPieces)
+
+	fmt.Printf("Average lines: %.1f (min %d, max %d)\n", result.AverageLines, result.MinLines, result.MaxLines)
+	fmt.Printf("Average score: %.0f\n", result.AverageScore)
+	fmt.Printf("Topped out:    %d/%d\n", result.ToppedOut, result.Games)
+}
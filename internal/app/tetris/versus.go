@@ -0,0 +1,226 @@
+package tetris
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/app/tetris/engine"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// versusControls is the keybinding help shown in the sidebar of each player
+var versusControls = [2][]string{
+	{
+		"  a,d to move        ",
+		"  s to drop          ",
+		"  w,e to rotate      ",
+		"  f to hold          ",
+	},
+	{
+		"  j,l/←→ to move     ",
+		"  k/↓ to drop        ",
+		"  i,o/↑ to rotate    ",
+		"  u to hold          ",
+	},
+}
+
+// versusKeys maps the keys of each player to the input they play
+var versusKeys = [2]map[string]engine.Input{
+	{
+		"a": engine.MoveLeft,
+		"d": engine.MoveRight,
+		"s": engine.Drop,
+		"w": engine.RotateRight,
+		"e": engine.RotateLeft,
+		"f": engine.Hold,
+	},
+	{
+		"j":     engine.MoveLeft,
+		"left":  engine.MoveLeft,
+		"l":     engine.MoveRight,
+		"right": engine.MoveRight,
+		"k":     engine.Drop,
+		"down":  engine.Drop,
+		"i":     engine.RotateRight,
+		"up":    engine.RotateRight,
+		"o":     engine.RotateLeft,
+		"u":     engine.Hold,
+	},
+}
+
+// versusMsg routes a message of the game loop to the board of a player. Messages
+// from a previous round have an older round number and are ignored.
+type versusMsg struct {
+	player int
+	round  int
+	msg    tea.Msg
+}
+
+// versusGame runs two boards side by side. Clearing lines sends garbage lines
+// to the opponent and the first player to top out loses the round.
+type versusGame struct {
+	players  [2]*gameState
+	wins     [2]int
+	round    int
+	winner   int
+	isPaused bool
+	rng      *rand.Rand
+}
+
+func newVersusGame() *versusGame {
+	return &versusGame{rng: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// garbageLines returns the number of garbage lines sent for clearing lines at once.
+func garbageLines(linesCleared int) int {
+	switch linesCleared {
+	case 4:
+		return 4
+	case 3:
+		return 2
+	case 2:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Init starts a new round where both players get the same pieces.
+func (v *versusGame) Init() tea.Cmd {
+	seed := v.rng.Int63()
+	v.round++
+	v.winner = -1
+	v.isPaused = false
+
+	cmds := make([]tea.Cmd, 0, len(v.players))
+	for i := range v.players {
+		gs := newGameState(modeVersus, seed, wallClock)
+		gs.controls = versusControls[i]
+		v.players[i] = &gs
+
+		cmds = append(cmds, v.forPlayer(i, gs.Init()))
+	}
+
+	return tea.Batch(cmds...)
+}
+
+// forPlayer wraps the messages produced by cmd so that they reach the board of
+// the player.
+func (v *versusGame) forPlayer(player int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+
+	round := v.round
+
+	return func() tea.Msg {
+		return versusMsg{player, round, cmd()}
+	}
+}
+
+func (v *versusGame) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		return v, v.handleKey(msg.String())
+	case versusMsg:
+		if msg.round != v.round || v.winner >= 0 {
+			return v, nil
+		}
+
+		return v, v.play(msg.player, func(gs *gameState) tea.Cmd {
+			_, cmd := gs.Update(msg.msg)
+			return cmd
+		})
+	}
+
+	return v, nil
+}
+
+func (v *versusGame) handleKey(key string) tea.Cmd {
+	switch key {
+	case "ctrl+c", "q", "Q", "esc":
+		return tea.Quit
+	case "r", "R":
+		if v.winner >= 0 {
+			return v.Init()
+		}
+		return nil
+	case "p", "P":
+		if v.winner >= 0 {
+			return nil
+		}
+
+		v.isPaused = !v.isPaused
+		cmds := make([]tea.Cmd, 0, len(v.players))
+		for i, gs := range v.players {
+			cmds = append(cmds, v.forPlayer(i, gs.handleKey(key, gs.clock())))
+		}
+		return tea.Batch(cmds...)
+	}
+
+	if v.isPaused || v.winner >= 0 {
+		return nil
+	}
+
+	for player, keys := range versusKeys {
+		if input, ok := keys[key]; ok {
+			return v.play(player, func(gs *gameState) tea.Cmd {
+				return gs.handleInput(input, gs.clock())
+			})
+		}
+	}
+
+	return nil
+}
+
+// play applies the action to the board of the player, then sends garbage to the
+// opponent for the lines it cleared and ends the round if the player topped out.
+func (v *versusGame) play(player int, action func(gs *gameState) tea.Cmd) tea.Cmd {
+	gs := v.players[player]
+	opponent := v.players[1-player]
+
+	linesBefore := gs.engine.Lines()
+	cmd := v.forPlayer(player, action(gs))
+
+	if garbage := garbageLines(gs.engine.Lines() - linesBefore); garbage > 0 {
+		opponent.engine.AddGarbage(garbage, v.rng.Intn(width))
+	}
+
+	if gs.progress.isFinished() {
+		v.winner = 1 - player
+		v.wins[v.winner]++
+		opponent.progress.stopClock(opponent.clock())
+		return nil
+	}
+
+	return cmd
+}
+
+// View shows both boards side by side with the score of the match below.
+func (v *versusGame) View() string {
+	titleStyle := lipgloss.NewStyle().Bold(true)
+
+	boards := make([]string, 0, 2*len(v.players))
+	for i, gs := range v.players {
+		title := fmt.Sprintf("Player %d - %d wins - garbage %d", i+1, v.wins[i], gs.engine.PendingGarbage())
+		boards = append(boards, titleStyle.Render(title)+"\n"+buildPlayArea(gs), "  ")
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, boards...))
+	builder.WriteString("\n")
+
+	if v.winner >= 0 {
+		builder.WriteString(titleStyle.Render(fmt.Sprintf("Player %d wins the round!", v.winner+1)))
+		builder.WriteString("  r for a rematch, q/ctl+c to quit\n")
+	} else if v.isPaused {
+		builder.WriteString("Paused - p to resume, q/ctl+c to quit\n")
+	} else {
+		builder.WriteString("p to pause, q/ctl+c to quit\n")
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,41 @@
+package tetris
+
+import (
+	"testing"
+)
+
+func TestFirstPlayerToTopOutLoses(t *testing.T) {
+	versus := newVersusGame()
+	versus.Init()
+
+	for range 1000 {
+		if versus.winner >= 0 {
+			break
+		}
+
+		versus.Update(versusMsg{0, versus.round, gameProgressTick{}})
+		versus.handleKey("s")
+		versus.handleKey("s")
+	}
+
+	if versus.winner != 1 || versus.wins != [2]int{0, 1} {
+		t.Fatal("Player 2 should win when player 1 tops out")
+	}
+
+	versus.handleKey("r")
+	if versus.winner >= 0 || versus.players[0].progress.isFinished() {
+		t.Fatal("A rematch should start a new round")
+	}
+}
+
+func TestOldRoundMessagesAreIgnored(t *testing.T) {
+	versus := newVersusGame()
+	versus.Init()
+	versus.Init()
+
+	versus.Update(versusMsg{0, versus.round - 1, gameProgressTick{}})
+
+	if _, ok := versus.players[0].engine.Piece(); ok {
+		t.Fatal("A tick from a previous round should not progress the game")
+	}
+}
@@ -0,0 +1,79 @@
+package engine
+
+// Algorithm is an implementation of the AI interface.
+type Algorithm int
+
+const (
+	MCTSAlgorithm Algorithm = iota
+	NegamaxAlgorithm
+)
+
+var Algorithms = []Algorithm{MCTSAlgorithm, NegamaxAlgorithm}
+
+func (a Algorithm) String() string {
+	switch a {
+	case MCTSAlgorithm:
+		return "monte carlo tree search"
+	case NegamaxAlgorithm:
+		return "negamax (alpha-beta)"
+	}
+
+	return "unknown"
+}
+
+// presetWorkers is the number of mcts workers of the AIs created by NewAI. It
+// deliberately isn't runtime.GOMAXPROCS(0): an AI searching a fixed number of
+// iterations grows stronger with each worker, and the AIs must play as strong
+// on every machine.
+const presetWorkers = 4
+
+// NewAI creates an AI of the algorithm playing the game of the engine. depth is
+// the number of iterations of each of the presetWorkers mcts workers, which
+// search in parallel and keep their tree between turns, and the maximum search
+// depth of negamax.
+func NewAI(algorithm Algorithm, engine GameEngine, depth int) AI {
+	if algorithm == NegamaxAlgorithm {
+		return NewNegamax(engine, depth)
+	}
+
+	return NewMCTSWithConfig(engine, MCTSConfig{
+		Iterations: depth,
+		Workers:    presetWorkers,
+		ReuseTree:  true,
+	})
+}
+
+// PlayMatch plays a game on the board between two AIs, p1 starting, and returns
+// the winner or 0 for a draw. The AIs play as P1, so the board is seen from the
+// other side when p2 plays.
+func PlayMatch(engine GameEngine, board *Board, p1, p2 AI) Player {
+	player := P1
+
+	for {
+		ai := p1
+		rollout := board.Copy()
+		if player == P2 {
+			ai = p2
+			rollout.ChangePerspective()
+		}
+
+		move := ai.Solve(rollout)
+		if move == -1 {
+			return 0
+		}
+
+		if err := engine.PlayMove(board, player, move); err != nil {
+			panic(err)
+		}
+
+		if isOver, value := engine.CheckGameOver(board, move); isOver {
+			if value != 0 {
+				return player
+			}
+
+			return 0
+		}
+
+		player = engine.GetOpponent(player)
+	}
+}
@@ -0,0 +1,127 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// analysis is what the analyst found about the moves of the player on the
+// board, whose cells are kept to tell if the analysis is still up to date.
+type analysis struct {
+	cells []int
+	stats SearchStats
+}
+
+type analysisMsg analysis
+
+// analyze searches the position of the player when the analysis is shown.
+// Only one search runs at a time, the position is checked again when it ends.
+func (g *Game) analyze() tea.Cmd {
+	if !g.showAnalysis || g.analyzing || g.gameover || g.isAITurn() || g.isAnalyzed() {
+		return nil
+	}
+
+	g.analyzing = true
+	cells := slices.Clone(g.board.Cells)
+	analyst := g.analyst
+
+	// The analyst plays as P1, show it the board from the side of the player
+	board := g.board.Copy()
+	if g.turn == P2 {
+		board.ChangePerspective()
+	}
+
+	return func() tea.Msg {
+		_, stats := analyst.Search(context.Background(), board)
+		return analysisMsg{cells, stats}
+	}
+}
+
+func (g *Game) handleAnalysis(msg analysisMsg) tea.Cmd {
+	g.analyzing = false
+	analysis := analysis(msg)
+	g.analysis = &analysis
+
+	// The player moved or started a new round during the search
+	return g.analyze()
+}
+
+func (g Game) isAnalyzed() bool {
+	return g.analysis != nil && slices.Equal(g.analysis.cells, g.board.Cells)
+}
+
+// viewAnalysis shows for each empty cell how good playing it is for the player
+// and lists the best moves. MCTS rates the moves by how often they won in its
+// simulations and negamax by their score: a forced win or loss, or the
+// estimation of the position when the search could not see the end.
+func (g Game) viewAnalysis() string {
+	title := g.colors["status"].Render(fmt.Sprintf("analysis (%s)", g.difficulty.analystName()))
+
+	if g.gameover {
+		return title
+	}
+
+	if !g.isAnalyzed() {
+		return title + "\n" + g.colors["status"].Render("analyzing...")
+	}
+
+	moves := map[int]MoveStats{}
+	for _, move := range g.analysis.stats.Moves {
+		moves[move.Move] = move
+	}
+
+	grid := ""
+	for i := 0; i < g.board.Rows; i++ {
+		for j := 0; j < g.board.Cols; j++ {
+			index := i*g.board.Cols + j
+			content := printPlayer(g.board.Cells[index])
+			if move, ok := moves[index]; ok {
+				content = g.rateMove(move)
+			} else if content == "" {
+				content = "·"
+			}
+
+			grid += fmt.Sprintf("%5s", content)
+		}
+		grid += "\n"
+	}
+
+	best := []string{}
+	for _, move := range g.analysis.stats.Moves[:min(3, len(g.analysis.stats.Moves))] {
+		best = append(best, fmt.Sprintf("%s: %s (%d visits)", g.moveName(move.Move), g.rateMove(move), move.Visits))
+	}
+
+	summary := fmt.Sprintf("%d iterations, best: %s", g.analysis.stats.Iterations, strings.Join(best, ", "))
+
+	return title + "\n" + g.colors["text"].Render(grid) + g.colors["status"].Render(summary)
+}
+
+// rateMove returns the win rate or the score of the move.
+func (g Game) rateMove(move MoveStats) string {
+	if g.difficulty != Perfect {
+		return fmt.Sprintf("%.0f%%", move.WinRate*100)
+	}
+
+	switch {
+	case move.Score > WIN_SCORE/2:
+		return "win"
+	case move.Score < -WIN_SCORE/2:
+		return "loss"
+	}
+
+	return fmt.Sprintf("%+d", move.Score)
+}
+
+// moveName returns the cell of the move as the player addresses it.
+func (g Game) moveName(move int) string {
+	if len(g.board.Cells) <= 9 {
+		return fmt.Sprint(move + 1)
+	}
+
+	row, col, _ := g.board.GetRowCol(move)
+	return fmt.Sprintf("%d,%d", row+1, col+1)
+}
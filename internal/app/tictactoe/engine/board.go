@@ -10,20 +10,31 @@ const (
 
 type Player = int
 
+// Board holds the cells of the players. LastMove is the last move for the
+// games whose rules depend on it, their engine keeps it up to date. It is -1
+// otherwise.
 type Board struct {
-	Size  int
-	Cells []int
+	Rows     int
+	Cols     int
+	Cells    []int
+	LastMove int
 }
 
 func NewBoard(size int) *Board {
-	cells := make([]int, size*size)
+	return NewRectBoard(size, size)
+}
+
+func NewRectBoard(rows, cols int) *Board {
+	cells := make([]int, rows*cols)
 	for i := range cells {
 		cells[i] = EMPTY
 	}
 
 	return &Board{
-		Size:  size,
-		Cells: cells,
+		Rows:     rows,
+		Cols:     cols,
+		Cells:    cells,
+		LastMove: -1,
 	}
 }
 
@@ -58,7 +69,15 @@ func (b *Board) GetRowCol(index int) (int, int, error) {
 		return 0, 0, fmt.Errorf("invalid cell index: %d", index)
 	}
 
-	return index / b.Size, index % b.Size, nil
+	return index / b.Cols, index % b.Cols, nil
+}
+
+func (b *Board) GetIndex(row, col int) (int, error) {
+	if row < 0 || row >= b.Rows || col < 0 || col >= b.Cols {
+		return 0, fmt.Errorf("invalid cell position: %d,%d", row, col)
+	}
+
+	return row*b.Cols + col, nil
 }
 
 func (b *Board) ChangePerspective() {
@@ -68,15 +87,16 @@ func (b *Board) ChangePerspective() {
 }
 
 func (b *Board) Copy() *Board {
-	newBoard := NewBoard(b.Size)
+	newBoard := NewRectBoard(b.Rows, b.Cols)
 	copy(newBoard.Cells, b.Cells)
+	newBoard.LastMove = b.LastMove
 	return newBoard
 }
 
 func (b *Board) Print() {
-	for i := 0; i < b.Size; i++ {
-		for j := 0; j < b.Size; j++ {
-			cell, _ := b.GetCell(i*b.Size + j)
+	for i := 0; i < b.Rows; i++ {
+		for j := 0; j < b.Cols; j++ {
+			cell, _ := b.GetCell(i*b.Cols + j)
 			if cell == P1 {
 				fmt.Print("O")
 			} else if cell == P2 {
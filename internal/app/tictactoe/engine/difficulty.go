@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"time"
+)
+
+// Difficulty is a preset of the AI of the games against the computer.
+type Difficulty int
+
+const (
+	Random Difficulty = iota
+	Easy
+	Medium
+	Perfect
+)
+
+// EASY_NOISE is the probability that the easy AI plays a random move.
+const EASY_NOISE = 0.3
+
+// PERFECT_BUDGET is the time the perfect AI and its analyst may search a move.
+// The search is perfect when it reaches the end of the game before running out.
+const PERFECT_BUDGET = time.Second
+
+var Difficulties = []Difficulty{Random, Easy, Medium, Perfect}
+
+func (d Difficulty) String() string {
+	switch d {
+	case Random:
+		return "random"
+	case Easy:
+		return "easy"
+	case Medium:
+		return "medium"
+	case Perfect:
+		return "perfect"
+	}
+
+	return "unknown"
+}
+
+// Description tells how the AI of the difficulty plays.
+func (d Difficulty) Description() string {
+	switch d {
+	case Random:
+		return "plays any empty cell"
+	case Easy:
+		return "short monte carlo tree search, sometimes plays at random"
+	case Medium:
+		return "monte carlo tree search"
+	case Perfect:
+		return "negamax, never loses on small boards"
+	}
+
+	return ""
+}
+
+// NewAI creates the AI of the difficulty for a game of the variant. The medium
+// AI strength varies between half and all of the variant depth.
+func (d Difficulty) NewAI(engine GameEngine, variant Variant) AI {
+	switch d {
+	case Random:
+		return NewNoisyAI(nil, engine, 1)
+	case Easy:
+		return NewNoisyAI(NewAI(MCTSAlgorithm, engine, max(variant.Depth/4, 1)), engine, EASY_NOISE)
+	case Perfect:
+		return NewNegamaxWithBudget(engine, variant.SearchDepth, PERFECT_BUDGET)
+	}
+
+	half := max(variant.Depth/2, 1)
+	return NewAI(MCTSAlgorithm, engine, rand.IntN(half)+half)
+}
+
+// NewAnalyst creates the AI explaining the moves of a game of the variant: the
+// negamax of the perfect difficulty or a longer monte carlo tree search.
+func (d Difficulty) NewAnalyst(engine GameEngine, variant Variant) Searcher {
+	if d == Perfect {
+		return NewNegamaxWithBudget(engine, variant.SearchDepth, PERFECT_BUDGET)
+	}
+
+	return NewMCTSWithConfig(engine, MCTSConfig{
+		Iterations: variant.Depth * 10,
+		Budget:     time.Second,
+		Workers:    runtime.GOMAXPROCS(0),
+	})
+}
+
+func (d Difficulty) analystName() string {
+	if d == Perfect {
+		return "negamax"
+	}
+
+	return "monte carlo tree search"
+}
+
+// noisyAI plays a random move with a probability of noise, the move of its AI
+// otherwise.
+type noisyAI struct {
+	ai     AI
+	engine GameEngine
+	noise  float64
+}
+
+// NewNoisyAI creates an AI playing like ai but for the random moves it plays
+// with a probability of noise. ai may be nil if noise is 1.
+func NewNoisyAI(ai AI, engine GameEngine, noise float64) AI {
+	return &noisyAI{ai, engine, noise}
+}
+
+func (n *noisyAI) Solve(board *Board) int {
+	if n.ai != nil && rand.Float64() >= n.noise {
+		return n.ai.Solve(board)
+	}
+
+	move, _, err := popRandomMove(n.engine.GetLegalMoves(board))
+	if err != nil {
+		return -1
+	}
+
+	return move
+}
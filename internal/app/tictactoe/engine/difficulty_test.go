@@ -0,0 +1,53 @@
+package engine
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDifficulty_PerfectNeverLoses(t *testing.T) {
+	for _, difficulty := range []Difficulty{Random, Easy, Medium} {
+		engine := NewEngine(0)
+		perfect := Perfect.NewAI(engine, TicTacToe)
+		opponent := difficulty.NewAI(engine, TicTacToe)
+
+		for range 5 {
+			if winner := PlayMatch(engine, NewBoard(3), opponent, perfect); winner == P1 {
+				t.Fatalf("perfect lost against %s", difficulty)
+			}
+		}
+	}
+}
+
+func TestDifficulty_MediumWithAShallowVariant(t *testing.T) {
+	engine := NewEngine(0)
+	variant := TicTacToe
+	variant.Depth = 1
+
+	if move := Medium.NewAI(engine, variant).Solve(NewBoard(3)); move == -1 {
+		t.Fatal("expected a move from the medium AI of a variant of depth 1")
+	}
+}
+
+func TestGame_Analysis(t *testing.T) {
+	model := GetVariantModel(TicTacToe, Perfect, 0)
+
+	model, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	if cmd == nil {
+		t.Fatal("expected showing the analysis to start a search")
+	}
+
+	model, _ = model.Update(cmd())
+	game := model.(Game)
+
+	if !game.isAnalyzed() || len(game.analysis.stats.Moves) != 9 {
+		t.Fatal("expected the analysis of the 9 moves of the empty board")
+	}
+
+	for _, move := range game.analysis.stats.Moves {
+		if move.Score != 0 {
+			t.Errorf("expected every first move to draw, move %d scored %d", move.Move, move.Score)
+		}
+	}
+}
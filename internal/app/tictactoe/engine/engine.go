@@ -1,13 +1,31 @@
 package engine
 
+// Engine implements m,n,k-games: players take turns on a board of m rows and
+// n columns and the first to get k of their cells in a row, column or diagonal
+// wins. Tic-tac-toe is the 3,3,3-game.
 type Engine struct {
-	ai AI
+	ai        AI
+	winLength int
 }
 
+// NewEngine creates an engine where a player wins by filling a line as long as
+// the smallest dimension of the board, which is a full row on square boards.
 func NewEngine(depth int) *Engine {
-	engine := &Engine{}
-	mcts := NewMCTS(engine, depth)
-	engine.ai = mcts
+	return NewEngineWithWinLength(depth, 0)
+}
+
+// NewEngineWithWinLength creates an engine where a player wins by getting
+// winLength cells in a row. A winLength of 0 means the smallest dimension of
+// the board.
+func NewEngineWithWinLength(depth int, winLength int) *Engine {
+	return NewEngineWithAlgorithm(MCTSAlgorithm, depth, winLength)
+}
+
+// NewEngineWithAlgorithm creates an engine like NewEngineWithWinLength whose AI
+// uses the algorithm.
+func NewEngineWithAlgorithm(algorithm Algorithm, depth int, winLength int) *Engine {
+	engine := &Engine{winLength: winLength}
+	engine.ai = NewAI(algorithm, engine, depth)
 
 	return engine
 }
@@ -47,8 +65,31 @@ func (e *Engine) CheckGameOver(board *Board, lastMove int) (bool, int) {
 	return false, 0
 }
 
+// GetWinLength returns the number of cells in a row needed to win on the board.
+func (e *Engine) GetWinLength(board *Board) int {
+	if e.winLength > 0 {
+		return e.winLength
+	}
+
+	return min(board.Rows, board.Cols)
+}
+
+// Evaluate scores the lines of the win length which only one player can still
+// complete, for negamax searches stopping before the end of the game.
+func (e *Engine) Evaluate(board *Board) int {
+	return EvaluateLines(board, e.GetWinLength(board))
+}
+
+// CheckWin checks if the last move completed a line of the win length. Only
+// the lines going through the last move can have been completed by it.
 func (e *Engine) CheckWin(board *Board, lastMove int) bool {
-	player, err := board.GetCell(lastMove)
+	return HasLine(board, lastMove, e.GetWinLength(board))
+}
+
+// HasLine checks if the cell at index is part of a horizontal, vertical or
+// diagonal line of at least length cells of the same player.
+func HasLine(board *Board, index int, length int) bool {
+	player, err := board.GetCell(index)
 	if err != nil {
 		panic(err)
 	}
@@ -56,90 +97,40 @@ func (e *Engine) CheckWin(board *Board, lastMove int) bool {
 		return false
 	}
 
-	row, col, err := board.GetRowCol(lastMove)
+	row, col, err := board.GetRowCol(index)
 	if err != nil {
 		panic(err)
 	}
 
-	if e.checkRow(board, row, player) {
-		return true
-	}
-
-	if e.checkCol(board, col, player) {
-		return true
-	}
-
-	if e.checkDiagonal(board, player) {
-		return true
-	}
-
-	return false
-}
-
-func (e *Engine) checkRow(board *Board, row, player int) bool {
-	for i := 0; i < board.Size; i++ {
-		cell, err := board.GetCell(row*board.Size + i)
-		if err != nil {
-			panic(err)
-		}
-
-		if cell != player {
-			return false
-		}
-	}
-
-	return true
-}
+	// Horizontal, vertical, diagonal (\) and anti-diagonal (/) directions
+	directions := [4][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}}
+	for _, direction := range directions {
+		count := 1 +
+			countInDirection(board, row, col, direction[0], direction[1], player) +
+			countInDirection(board, row, col, -direction[0], -direction[1], player)
 
-func (e *Engine) checkCol(board *Board, col, player int) bool {
-	for i := 0; i < board.Size; i++ {
-		cell, err := board.GetCell(i*board.Size + col)
-		if err != nil {
-			panic(err)
-		}
-
-		if cell != player {
-			return false
+		if count >= length {
+			return true
 		}
 	}
 
-	return true
+	return false
 }
 
-func (e *Engine) checkDiagonal(board *Board, player int) bool {
-	sum := 0
-	// Left to right
-	for i := 0; i < board.Size; i++ {
-		cell, err := board.GetCell(i*board.Size + i)
-		if err != nil {
-			panic(err)
-		}
-
-		if cell == player {
-			sum += player
-		}
-	}
-
-	if sum == board.Size*player {
-		return true
-	}
+// countInDirection counts the consecutive cells of the player starting next to
+// row,col and moving by dRow,dCol.
+func countInDirection(board *Board, row, col, dRow, dCol, player int) int {
+	count := 0
 
-	sum = 0
-	// Right to left
-	for i := 0; i < board.Size; i++ {
-		cell, err := board.GetCell(i*board.Size + board.Size - i - 1)
-		if err != nil {
-			panic(err)
-		}
+	for {
+		row += dRow
+		col += dCol
 
-		if cell == player {
-			sum += player
+		index, err := board.GetIndex(row, col)
+		if err != nil || board.Cells[index] != player {
+			return count
 		}
-	}
 
-	if sum == board.Size*player {
-		return true
+		count++
 	}
-
-	return false
 }
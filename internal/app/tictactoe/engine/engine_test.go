@@ -155,3 +155,81 @@ func TestEngine_GetLegalMoves(t *testing.T) {
 		}
 	})
 }
+
+func TestEngine_CheckWinLength(t *testing.T) {
+	t.Run("Off-diagonal k in a row", func(t *testing.T) {
+		board := NewBoard(4)
+		engine := NewEngineWithWinLength(DEPTH, 3)
+		board.SetCell(1, P1)
+		board.SetCell(6, P1)
+		board.SetCell(11, P1)
+		if !engine.CheckWin(board, 6) {
+			t.Error("expected win")
+		}
+	})
+
+	t.Run("Anti-diagonal k in a row", func(t *testing.T) {
+		board := NewBoard(4)
+		engine := NewEngineWithWinLength(DEPTH, 3)
+		board.SetCell(7, P2)
+		board.SetCell(10, P2)
+		board.SetCell(13, P2)
+		if !engine.CheckWin(board, 13) {
+			t.Error("expected win")
+		}
+	})
+
+	t.Run("Line shorter than k", func(t *testing.T) {
+		board := NewBoard(15)
+		engine := NewEngineWithWinLength(DEPTH, 5)
+		for i := 0; i < 4; i++ {
+			board.SetCell(20+i, P1)
+		}
+		if engine.CheckWin(board, 21) {
+			t.Error("expected no win")
+		}
+
+		board.SetCell(24, P1)
+		if !engine.CheckWin(board, 21) {
+			t.Error("expected win")
+		}
+	})
+
+	t.Run("Line broken by the opponent", func(t *testing.T) {
+		board := NewBoard(15)
+		engine := NewEngineWithWinLength(DEPTH, 5)
+		for _, row := range []int{0, 1, 3, 4, 5} {
+			board.SetCell(row*15, P1)
+		}
+		board.SetCell(2*15, P2)
+		if engine.CheckWin(board, 0) {
+			t.Error("expected no win")
+		}
+	})
+
+	t.Run("Rectangular board", func(t *testing.T) {
+		board := NewRectBoard(3, 5)
+		engine := NewEngine(DEPTH)
+		board.SetCell(2, P1)
+		board.SetCell(7, P1)
+		board.SetCell(12, P1)
+		if engine.GetWinLength(board) != 3 || !engine.CheckWin(board, 12) {
+			t.Error("expected a full column to win")
+		}
+	})
+}
+
+func TestEngine_SolveLargerBoards(t *testing.T) {
+	board := NewBoard(4)
+	engine := NewEngineWithWinLength(FourByFour.Depth, FourByFour.WinLength)
+	board.Load([]int{
+		1, 1, 1, 0,
+		-1, -1, 0, 0,
+		-1, 0, 0, 0,
+		0, 0, 0, 0,
+	})
+
+	if move := engine.ai.Solve(board); move != 3 {
+		t.Errorf("expected move 3, got %d", move)
+	}
+}
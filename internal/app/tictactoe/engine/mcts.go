@@ -1,9 +1,13 @@
 package engine
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
 )
 
 const (
@@ -27,67 +31,220 @@ type GameEngine interface {
 	PlayMove(board *Board, player int, move int) error
 }
 
+// Searcher is an AI which can search until a context is done and report what
+// it found about each move.
+type Searcher interface {
+	AI
+	Search(ctx context.Context, board *Board) (int, SearchStats)
+}
+
+// SearchStats are returned by a search with the chosen move. Moves holds the
+// moves of the root, the most visited first.
+type SearchStats struct {
+	Iterations int
+	Moves      []MoveStats
+}
+
+// MoveStats tells how much a move was searched and how good it is for the
+// player to move. MCTS reports how often the move led to a win, draws counting
+// as half a win, and negamax reports its score.
+type MoveStats struct {
+	Move    int
+	Visits  int
+	WinRate float64
+	Score   int
+}
+
+// MCTSConfig sets the limits of a search and how it runs. The search stops at
+// the first limit reached, so at least one of them must be set unless the
+// context given to Search is done at some point.
+//   - Iterations is the number of iterations of each worker, 0 for no limit.
+//   - Budget is how long the search runs, 0 for no limit.
+//   - Workers is the number of trees searched in parallel, which are merged at
+//     the root to choose the move.
+//   - ReuseTree keeps the trees between searches: when the next board is two
+//     moves further in the game, the search continues from the matching node.
+type MCTSConfig struct {
+	Iterations int
+	Budget     time.Duration
+	Workers    int
+	ReuseTree  bool
+}
+
 type mcts struct {
 	engine GameEngine
-	depth  int
+	config MCTSConfig
+	roots  []*node
 }
 
 func NewMCTS(engine GameEngine, depth int) AI {
-	return &mcts{engine, depth}
+	return NewMCTSWithConfig(engine, MCTSConfig{Iterations: depth, Workers: 1})
+}
+
+// NewMCTSWithConfig creates an MCTS AI with the config. Searches of the same AI
+// must not run concurrently.
+func NewMCTSWithConfig(engine GameEngine, config MCTSConfig) Searcher {
+	config.Workers = max(config.Workers, 1)
+
+	return &mcts{engine: engine, config: config}
 }
 
 func (m *mcts) Solve(board *Board) int {
-	root := newNode(m.engine, board, -1, nil)
-
-	for i := 0; i < m.depth; i++ {
-		node := root
-		for node.isExpanded() {
-			child, err := node.selectChild()
-			if err != nil {
-				panic(err)
-			}
-			node = child
+	move, _ := m.Search(context.Background(), board)
+	return move
+}
+
+// Search runs the workers on their own tree until a limit is reached or the
+// context is done, then plays the move most visited by all of them.
+func (m *mcts) Search(ctx context.Context, board *Board) (int, SearchStats) {
+	if m.config.Budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.config.Budget)
+		defer cancel()
+	}
+
+	roots := make([]*node, m.config.Workers)
+	iterations := make([]int, m.config.Workers)
+
+	var wg sync.WaitGroup
+	for i := range roots {
+		roots[i] = m.getRoot(i, board)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			iterations[i] = m.run(ctx, roots[i])
+		}()
+	}
+	wg.Wait()
+
+	if m.config.ReuseTree {
+		m.roots = roots
+	}
+
+	stats := mergeStats(roots)
+	for _, n := range iterations {
+		stats.Iterations += n
+	}
+
+	if len(stats.Moves) == 0 || stats.Moves[0].Visits == 0 {
+		return -1, stats
+	}
+
+	// Forced wins look as good as winning at once, which is played first
+	if move := m.winningMove(board); move != -1 {
+		return move, stats
+	}
+
+	return stats.Moves[0].Move, stats
+}
+
+// winningMove returns a move of P1 which wins the game at once, or -1.
+func (m *mcts) winningMove(board *Board) int {
+	for _, move := range m.engine.GetLegalMoves(board) {
+		rollout := board.Copy()
+		m.engine.PlayMove(rollout, P1, move)
+
+		if isOver, value := m.engine.CheckGameOver(rollout, move); isOver && value != 0 {
+			return move
 		}
+	}
+
+	return -1
+}
 
-		isOver, value := m.engine.CheckGameOver(node.board, node.move)
-		value = m.engine.GetOpponent(value)
+// getRoot returns the node of the previous tree of the worker matching the
+// board, or a new node if there is none.
+func (m *mcts) getRoot(worker int, board *Board) *node {
+	if worker < len(m.roots) {
+		candidates := []*node{m.roots[worker]}
+		for _, child := range m.roots[worker].children {
+			candidates = append(candidates, child.children...)
+		}
 
-		if !isOver {
-			child, err := node.expand()
-			if err != nil {
-			} else {
-				value = child.simulate()
-				node = child
+		for _, candidate := range candidates {
+			if slices.Equal(candidate.board.Cells, board.Cells) && candidate.board.LastMove == board.LastMove {
+				candidate.parent = nil
+				return candidate
 			}
 		}
+	}
+
+	return newNode(m.engine, board.Copy(), -1, nil)
+}
 
-		node.backpropagate(value)
+// run searches the tree until the iterations of the worker are done or the
+// context is, and returns the number of iterations.
+func (m *mcts) run(ctx context.Context, root *node) int {
+	for i := 0; m.config.Iterations == 0 || i < m.config.Iterations; i++ {
+		if ctx.Err() != nil {
+			return i
+		}
+
+		m.iterate(root)
 	}
 
-	visits := make([]float64, board.Size*board.Size)
-	dist := make([]float64, board.Size*board.Size)
-	sum := 0.0
+	return m.config.Iterations
+}
 
-	for _, child := range root.children {
-		visits[child.move] = float64(child.visitCount)
-		sum += visits[child.move]
+// iterate selects a node to expand, simulates a game from it and backpropagates
+// the result.
+func (m *mcts) iterate(root *node) {
+	node := root
+	for node.isExpanded() {
+		child, err := node.selectChild()
+		if err != nil {
+			panic(err)
+		}
+		node = child
 	}
 
-	for i, visit := range visits {
-		dist[i] = visit / sum
+	isOver, value := m.engine.CheckGameOver(node.board, node.move)
+	value = m.engine.GetOpponent(value)
+
+	if !isOver {
+		child, err := node.expand()
+		if err != nil {
+		} else {
+			value = child.simulate()
+			node = child
+		}
 	}
 
-	bestMove := -1
-	bestValue := 0.0
+	node.backpropagate(value)
+}
+
+// mergeStats sums the visits and values of the moves of the roots.
+func mergeStats(roots []*node) SearchStats {
+	visits := map[int]int{}
+	values := map[int]int{}
+	for _, root := range roots {
+		for _, child := range root.children {
+			visits[child.move] += child.visitCount
+			values[child.move] += child.valueSum
+		}
+	}
 
-	for i, value := range dist {
-		if value > bestValue {
-			bestMove = i
-			bestValue = value
+	stats := SearchStats{}
+	for move, visit := range visits {
+		// Values are seen from the player to move after the move
+		winRate := 0.0
+		if visit > 0 {
+			winRate = (1 - float64(values[move])/float64(visit)) / 2
 		}
+
+		stats.Moves = append(stats.Moves, MoveStats{Move: move, Visits: visit, WinRate: winRate})
 	}
 
-	return bestMove
+	slices.SortFunc(stats.Moves, func(a, b MoveStats) int {
+		if a.Visits != b.Visits {
+			return b.Visits - a.Visits
+		}
+
+		return a.Move - b.Move
+	})
+
+	return stats
 }
 
 type node struct {
@@ -137,7 +294,9 @@ func (n *node) simulate() int {
 		n.engine.PlayMove(board, player, move)
 		isOver, winner = n.engine.CheckGameOver(board, move)
 		if isOver {
-			result = winner
+			// The winner is the player who just played, the result is seen
+			// from the player to move at the node, who plays as P1
+			result = winner * player
 			break
 		}
 
@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMCTS_SearchStats(t *testing.T) {
+	engine := NewEngine(DEPTH)
+	ai := NewMCTSWithConfig(engine, MCTSConfig{Iterations: 500, Workers: 4})
+
+	// #0: P1 wins by completing the first row
+	board := NewBoard(3)
+	board.Load(testCases[0].input)
+
+	move, stats := ai.Search(context.Background(), board)
+	if move != 2 {
+		t.Errorf("expected move 2, got %d", move)
+	}
+
+	if stats.Iterations != 2000 {
+		t.Errorf("expected 2000 iterations, got %d", stats.Iterations)
+	}
+
+	if stats.Moves[0].Move != move || stats.Moves[0].WinRate != 1 {
+		t.Errorf("expected the winning move first with a win rate of 1, got %+v", stats.Moves[0])
+	}
+}
+
+func TestMCTS_TimeBudget(t *testing.T) {
+	engine := NewEngine(DEPTH)
+	ai := NewMCTSWithConfig(engine, MCTSConfig{Budget: 50 * time.Millisecond, Workers: 2})
+
+	start := time.Now()
+	move, stats := ai.Search(context.Background(), NewBoard(4))
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the search to stop after its budget, took %v", elapsed)
+	}
+
+	if move == -1 || stats.Iterations == 0 {
+		t.Errorf("expected a move after %d iterations, got %d", stats.Iterations, move)
+	}
+}
+
+func TestMCTS_ContextCancellation(t *testing.T) {
+	engine := NewEngine(DEPTH)
+	ai := NewMCTSWithConfig(engine, MCTSConfig{Workers: 2})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if move, _ := ai.Search(ctx, NewBoard(4)); move == -1 {
+		t.Error("expected a move when the search is cancelled")
+	}
+}
+
+func TestMCTS_ReuseTree(t *testing.T) {
+	engine := NewEngine(DEPTH)
+	ai := NewMCTSWithConfig(engine, MCTSConfig{Iterations: 1000, Workers: 1, ReuseTree: true})
+
+	board := NewBoard(3)
+	move, _ := ai.Search(context.Background(), board)
+
+	// Play the move of the AI, the answer of the opponent and look at the
+	// board from the side of the AI again.
+	engine.PlayMove(board, P1, move)
+	board.ChangePerspective()
+	engine.PlayMove(board, P1, engine.GetLegalMoves(board)[0])
+	board.ChangePerspective()
+
+	_, stats := ai.Search(context.Background(), board)
+
+	visits := 0
+	for _, move := range stats.Moves {
+		visits += move.Visits
+	}
+
+	if visits <= stats.Iterations {
+		t.Errorf("expected the visits of the previous search to be kept, got %d visits for %d iterations", visits, stats.Iterations)
+	}
+}
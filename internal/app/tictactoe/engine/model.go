@@ -3,28 +3,64 @@ package engine
 import (
 	"fmt"
 	"log"
-	"math/rand/v2"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
+// Variant describes an m,n,k-game: the board has Rows and Cols and WinLength
+// cells in a row win. Depth is the number of MCTS iterations of the AI and
+// SearchDepth the maximum search depth of negamax.
+type Variant struct {
+	Name        string
+	Rows        int
+	Cols        int
+	WinLength   int
+	Depth       int
+	SearchDepth int
+}
+
+var (
+	TicTacToe  = Variant{"tictactoe", 3, 3, 3, 100, 9}
+	FourByFour = Variant{"4x4 four in a row", 4, 4, 4, 300, 16}
+	Gomoku     = Variant{"gomoku 15x15 five in a row", 15, 15, 5, 1000, 4}
+
+	Variants = []Variant{TicTacToe, FourByFour, Gomoku}
+)
+
+// Game is a match of rounds of the variant, against the AI of the difficulty or
+// between two players sharing the keyboard. The first player to win more than
+// half of the bestOf rounds wins the match, and a bestOf of 0 plays rounds until
+// the players quit. The moves of the round are recorded so that they can be
+// taken back and exported.
 type Game struct {
-	board    *Board
-	engine   *Engine
-	turn     Player
-	winner   Player
-	gameover bool
-	round    int
-	scoreP1  int
-	scoreP2  int
-	colors   map[string]lipgloss.Style
+	variant      Variant
+	difficulty   Difficulty
+	twoPlayer    bool
+	bestOf       int
+	board        *Board
+	engine       *Engine
+	analyst      Searcher
+	analysis     *analysis
+	showAnalysis bool
+	analyzing    bool
+	cursor       int
+	turn         Player
+	winner       Player
+	gameover     bool
+	round        int
+	scoreP1      int
+	scoreP2      int
+	record       Record
+	saved        string
+	colors       map[string]lipgloss.Style
 }
 
 const (
-	size   = 3
 	yellow = "#FF9E3B"
 	dark   = "#3C3A32"
 	gray   = "#717C7C"
@@ -34,24 +70,60 @@ const (
 	blue   = "#7E9CD8"
 )
 
+// RecordGame is the game of the records of the variants, see Record.
+const RecordGame = "tictactoe"
+
 func GetModel() tea.Model {
-	board := NewBoard(size)
-	engine := NewEngine(100)
+	return GetVariantModel(TicTacToe, Medium, 0)
+}
+
+// GetVariantModel creates a match of bestOf rounds of the variant against the
+// AI of the difficulty.
+func GetVariantModel(variant Variant, difficulty Difficulty, bestOf int) tea.Model {
+	return newGame(variant, difficulty, false, bestOf)
+}
 
+// GetTwoPlayerModel creates a match of bestOf rounds of the variant between two
+// players. The analysis is the one of the medium difficulty.
+func GetTwoPlayerModel(variant Variant, bestOf int) tea.Model {
+	return newGame(variant, Medium, true, bestOf)
+}
+
+// LoadModel creates a game from the position of the record, against the AI of
+// the difficulty or between two players. The variant is the one of the size of
+// the board of the record.
+func LoadModel(record Record, difficulty Difficulty, twoPlayer bool) (tea.Model, error) {
+	if record.Game != RecordGame {
+		return nil, fmt.Errorf("cannot load a game of %s", record.Game)
+	}
+
+	index := slices.IndexFunc(Variants, func(v Variant) bool {
+		return v.Rows == record.Rows && v.Cols == record.Cols
+	})
+	if index == -1 {
+		return nil, fmt.Errorf("no variant is played on a %dx%d board", record.Rows, record.Cols)
+	}
+
+	g := newGame(Variants[index], difficulty, twoPlayer, 0)
+	if err := g.load(record); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+func newGame(variant Variant, difficulty Difficulty, twoPlayer bool, bestOf int) Game {
 	defaultStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#f9f6f2"))
 	c := func(s string) lipgloss.Color {
 		return lipgloss.Color(s)
 	}
 
-	return Game{
-		board:    board,
-		engine:   engine,
-		turn:     P1,
-		winner:   0,
-		round:    1,
-		scoreP1:  0,
-		scoreP2:  0,
-		gameover: false,
+	g := Game{
+		variant:    variant,
+		difficulty: difficulty,
+		twoPlayer:  twoPlayer,
+		bestOf:     bestOf,
+		turn:       P1,
 		colors: map[string]lipgloss.Style{
 			"board":  defaultStyle.Background(c(dark)),
 			"text":   defaultStyle.Background(c(dark)).Foreground(c(light)),
@@ -59,44 +131,45 @@ func GetModel() tea.Model {
 			"p1":     defaultStyle.Background(c(dark)).Foreground(c(yellow)),
 			"p2":     defaultStyle.Background(c(dark)).Foreground(c(red)),
 			"hi":     defaultStyle.Foreground(c(green)),
+			"cursor": defaultStyle.Background(c(gray)),
 			"status": defaultStyle.Foreground(c(blue)),
 		},
 	}
+
+	g.nextRound()
+	g.analyst = difficulty.NewAnalyst(g.engine, variant)
+
+	return g
 }
 
+// Init lets the AI play first in loaded games where it is its turn.
 func (g Game) Init() tea.Cmd {
-	return nil
+	if g.gameover {
+		return nil
+	}
+
+	return g.nextTurn()
 }
 
-type gameOverMsg struct{ winner Player }
-type nextTurnMsg struct{}
+// aiDelay lets the player see their move before the AI plays, without blocking
+// the interface like sleeping in Update would.
+const aiDelay = 200 * time.Millisecond
+
 type aiTurnMsg struct{}
+type aiMoveMsg struct{ move int }
 
 func (g Game) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case aiTurnMsg:
-		time.Sleep(time.Millisecond * 200)
-		return g, aiMoveCmd(&g)
-
-	case nextTurnMsg:
-		g.turn = g.engine.GetOpponent(g.turn)
-		if g.turn == P2 {
-			return g, func() tea.Msg {
-				return aiTurnMsg{}
-			}
-		}
-		return g, nil
+		cmd := g.aiMove()
+		return g, cmd
 
-	case gameOverMsg:
-		g.winner = msg.winner
-		g.turn = g.engine.GetOpponent(g.turn)
-		g.gameover = true
-		if g.winner == P1 {
-			g.scoreP1 += 1
-		} else if g.winner == P2 {
-			g.scoreP2 += 1
-		}
-		return g, nil
+	case aiMoveMsg:
+		return g.play(msg.move)
+
+	case analysisMsg:
+		cmd := g.handleAnalysis(msg)
+		return g, cmd
 
 	case tea.KeyMsg:
 		switch msg.String() {
@@ -104,47 +177,47 @@ func (g Game) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return g, tea.Quit
 
 		case "n", "N":
-			g.nextMatch()
-			if g.turn == P2 {
-				return g, aiMoveCmd(&g)
+			if !g.gameover {
+				return g, nil
+			}
+
+			if g.isMatchOver() {
+				g.newMatch()
+			} else {
+				g.nextRound()
 			}
-			return g, nil
 
+			cmd := g.nextTurn()
+			return g, cmd
+
+		case "u", "U":
+			cmd := g.undo()
+			return g, cmd
+
+		case "e", "E":
+			g.export()
+
+		case "a", "A":
+			g.showAnalysis = !g.showAnalysis
+			cmd := g.analyze()
+			return g, cmd
+
+		case "up", "k":
+			g.moveCursor(-1, 0)
+		case "down", "j":
+			g.moveCursor(1, 0)
+		case "left", "h":
+			g.moveCursor(0, -1)
+		case "right", "l":
+			g.moveCursor(0, 1)
+		case "enter", " ":
+			return g.playCell(g.cursor)
 		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			// Number keys only address the cells of boards with at most 9 cells
 			// There shouldn't be an error, because this is only called for integers
 			index, _ := strconv.Atoi(msg.String())
-			index -= 1
-			cell, err := g.board.GetCell(index)
-			if err != nil {
-				log.Fatal(err)
-			}
-
-			if cell == EMPTY {
-				g.engine.PlayMove(g.board, P1, index)
-
-				isover, win := g.engine.CheckGameOver(g.board, index)
-
-				if isover {
-					if win > 0 {
-						g.winner = g.turn
-						// Update score
-						if g.winner == P1 {
-							g.scoreP1 += 1
-						} else if g.winner == P2 {
-							g.scoreP2 += 1
-						}
-					} else {
-						g.winner = 0
-					}
-
-					g.gameover = true
-					g.turn = g.engine.GetOpponent(g.turn)
-					return g, nil
-				}
-
-				return g, func() tea.Msg {
-					return nextTurnMsg{}
-				}
+			if index <= len(g.board.Cells) {
+				return g.playCell(index - 1)
 			}
 		}
 	}
@@ -152,35 +225,213 @@ func (g Game) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return g, nil
 }
 
-// Handle AI turn
-func aiMoveCmd(g *Game) tea.Cmd {
-	return func() tea.Msg {
-		rollout := g.board.Copy()
-		move := g.engine.ai.Solve(rollout)
+func (g *Game) moveCursor(dRow, dCol int) {
+	row, col, _ := g.board.GetRowCol(g.cursor)
+	if index, err := g.board.GetIndex(row+dRow, col+dCol); err == nil {
+		g.cursor = index
+	}
+}
 
-		g.engine.PlayMove(g.board, P2, move)
+// isAITurn tells if the AI plays the next move, the players have to wait.
+func (g Game) isAITurn() bool {
+	return !g.twoPlayer && g.turn == P2
+}
 
-		isover, win := g.engine.CheckGameOver(g.board, move)
-		if isover {
-			if win > 0 {
-				return gameOverMsg{winner: P2}
-			}
+// playCell plays the move of the player on the cell if it is empty.
+func (g Game) playCell(index int) (tea.Model, tea.Cmd) {
+	if g.gameover || g.isAITurn() {
+		return g, nil
+	}
+
+	cell, err := g.board.GetCell(index)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if cell != EMPTY {
+		return g, nil
+	}
+
+	g.cursor = index
+	return g.play(index)
+}
 
-			return gameOverMsg{winner: 0}
+// play plays the move of the player whose turn it is, ends the round if the
+// game is over and hands the turn to the other player otherwise.
+func (g Game) play(move int) (tea.Model, tea.Cmd) {
+	g.engine.PlayMove(g.board, g.turn, move)
+	g.record.Moves = append(g.record.Moves, move)
+
+	isover, win := g.engine.CheckGameOver(g.board, move)
+	if isover {
+		g.endRound(win)
+		return g, nil
+	}
+
+	g.turn = g.engine.GetOpponent(g.turn)
+
+	cmd := g.nextTurn()
+	return g, cmd
+}
+
+// endRound scores the round. The player who did not make the last move starts
+// the next round.
+func (g *Game) endRound(win int) {
+	g.winner = 0
+	if win > 0 {
+		g.winner = g.turn
+	}
+
+	if g.winner == P1 {
+		g.scoreP1 += 1
+	} else if g.winner == P2 {
+		g.scoreP2 += 1
+	}
+
+	g.gameover = true
+	g.turn = g.engine.GetOpponent(g.turn)
+}
+
+// undo takes back the last move. Against the AI, it also takes back the moves of
+// the AI since the last move of the player so that the player is to move again.
+// Taking back the last move of a round cancels its score.
+func (g *Game) undo() tea.Cmd {
+	// The AI is searching its move on the current position
+	if g.isAITurn() && !g.gameover {
+		return nil
+	}
+
+	player := P1
+	if g.twoPlayer {
+		player = g.engine.GetOpponent(g.record.Turn())
+	}
+
+	record := g.record.TakeBack(player)
+	if len(record.Moves) == len(g.record.Moves) {
+		return nil
+	}
+
+	if g.winner == P1 {
+		g.scoreP1 -= 1
+	} else if g.winner == P2 {
+		g.scoreP2 -= 1
+	}
+
+	// The moves of the record were legal, they can be played again
+	if err := g.load(record); err != nil {
+		log.Fatal(err)
+	}
+
+	return g.nextTurn()
+}
+
+// load replays the moves of the record on a new board for the round, and ends
+// the round if the last move ended the game.
+func (g *Game) load(record Record) error {
+	board, err := record.Replay(g.engine)
+	if err != nil {
+		return err
+	}
+
+	g.board = board
+	g.record = record
+	g.turn = record.Turn()
+	g.gameover = false
+	g.winner = 0
+	g.analysis = nil
+
+	if n := len(record.Moves); n > 0 {
+		if over, win := g.engine.CheckGameOver(board, record.Moves[n-1]); over {
+			g.turn = g.engine.GetOpponent(g.turn)
+			g.endRound(win)
 		}
+	}
+
+	return nil
+}
+
+// export saves the moves of the round to the saved games.
+func (g *Game) export() {
+	path, err := SaveRecord(g.record, CellNotation{})
+	if err != nil {
+		g.saved = "Game not saved: " + err.Error()
+		return
+	}
+
+	g.saved = "Game saved to " + path
+}
+
+// nextTurn lets the AI play if it is its turn, or analyzes the position of the
+// player.
+func (g *Game) nextTurn() tea.Cmd {
+	if g.isAITurn() {
+		return tea.Tick(aiDelay, func(time.Time) tea.Msg {
+			return aiTurnMsg{}
+		})
+	}
+
+	return g.analyze()
+}
+
+// aiMove searches the move of the AI on a copy of the board. The AI plays as
+// P1, so it is shown the board from the side of P2.
+func (g Game) aiMove() tea.Cmd {
+	rollout := g.board.Copy()
+	rollout.ChangePerspective()
+	ai := g.engine.ai
+
+	return func() tea.Msg {
+		return aiMoveMsg{ai.Solve(rollout)}
+	}
+}
+
+// isMatchOver tells if a player won more than half of the rounds or if all the
+// rounds were played.
+func (g Game) isMatchOver() bool {
+	if g.bestOf == 0 || !g.gameover {
+		return false
+	}
+
+	toWin := g.bestOf/2 + 1
 
-		return nextTurnMsg{}
+	return g.scoreP1 >= toWin || g.scoreP2 >= toWin || g.round >= g.bestOf
+}
+
+// matchWinner returns the player who won the most rounds, 0 if it is a tie.
+func (g Game) matchWinner() Player {
+	if g.scoreP1 > g.scoreP2 {
+		return P1
+	} else if g.scoreP2 > g.scoreP1 {
+		return P2
 	}
+
+	return 0
 }
 
-func (g *Game) nextMatch() {
-	g.board = NewBoard(size)
+func (g *Game) newMatch() {
+	g.scoreP1 = 0
+	g.scoreP2 = 0
+	g.round = 0
+	g.turn = P1
+	g.nextRound()
+}
+
+// nextRound clears the board. Each round gets a new AI, so that the strength
+// of the medium AI varies.
+func (g *Game) nextRound() {
+	g.board = NewRectBoard(g.variant.Rows, g.variant.Cols)
+	g.cursor = len(g.board.Cells) / 2
 	g.gameover = false
 	g.winner = 0
 	g.round += 1
+	g.record = NewRecord(RecordGame, g.board, g.turn)
+	g.analysis = nil
+	g.saved = ""
 
-	randLvl := rand.IntN(50) + 50
-	g.engine = NewEngine(randLvl)
+	g.engine = &Engine{winLength: g.variant.WinLength}
+	if !g.twoPlayer {
+		g.engine.ai = g.difficulty.NewAI(g.engine, g.variant)
+	}
 }
 
 func printCell(board *Board, index int) string {
@@ -221,9 +472,16 @@ func (g Game) View() string {
 		case P2:
 			style = g.colors["p2"]
 			content = "X"
-		default: // Empty cell, show index
+		default: // Empty cell, show index on boards addressed with number keys
 			style = g.colors["text"]
-			content = strconv.Itoa(index + 1)
+			content = "·"
+			if len(g.board.Cells) <= 9 {
+				content = strconv.Itoa(index + 1)
+			}
+		}
+
+		if index == g.cursor && !g.gameover {
+			style = style.Background(g.colors["cursor"].GetBackground())
 		}
 
 		return style.Render(content)
@@ -241,27 +499,87 @@ func (g Game) View() string {
 		}
 	}
 
+	// Large boards are drawn without lines between the cells to fit the terminal
+	separator := " | "
+	rowSeparator := strings.Repeat("---+", g.board.Cols-1) + "---"
+	if g.board.Cols > 5 {
+		separator = " "
+		rowSeparator = ""
+	}
+
 	board := ""
-	for i := 0; i < 3; i++ {
+	for i := 0; i < g.board.Rows; i++ {
 		board += g.colors["board"].Render(" ")
-		board += renderCell(i * 3)
-		board += g.colors["line"].Render(" | ")
-		board += renderCell(i*3 + 1)
-		board += g.colors["line"].Render(" | ")
-		board += renderCell(i*3 + 2)
+		for j := 0; j < g.board.Cols; j++ {
+			if j > 0 {
+				board += g.colors["line"].Render(separator)
+			}
+			board += renderCell(i*g.board.Cols + j)
+		}
 		board += g.colors["board"].Render(" ")
 
-		if i < 2 {
-			board += "\n" + g.colors["line"].Render("---+---+---") + "\n"
+		if i < g.board.Rows-1 {
+			board += "\n"
+			if rowSeparator != "" {
+				board += g.colors["line"].Render(rowSeparator) + "\n"
+			}
 		}
 	}
 
-	status := g.colors["status"].Render(fmt.Sprintf("\n#%d:(W%d-L%d)", g.round, g.scoreP1, g.scoreP2))
-	if g.gameover {
-		status += g.colors["status"].Render("> [Q]uit - [N]ext match")
-	} else {
+	status := g.colors["status"].Render("\n" + g.viewScore())
+	switch {
+	case g.isMatchOver():
+		status += "\n" + g.colors["hi"].Render(g.viewMatchWinner())
+		status += "\n" + g.colors["status"].Render("[Q]uit - [N]ew match - [U]ndo - [E]xport")
+	case g.gameover:
+		status += g.colors["status"].Render("> [Q]uit - [N]ext round - [U]ndo - [E]xport")
+	default:
 		status += g.colors["status"].Render(fmt.Sprintf("> %s's turn", printPlayer(g.turn)))
+		status += "\n" + g.colors["status"].Render("arrows/hjkl to move, enter to play, a for analysis")
+		status += "\n" + g.colors["status"].Render("u to undo, e to export the game")
+	}
+
+	if len(g.record.Moves) > 0 {
+		status += "\n\n" + g.colors["status"].Width(40).Render(g.record.FormatMoves(CellNotation{}))
+	}
+
+	if g.saved != "" {
+		status += "\n" + g.colors["hi"].Render(g.saved)
+	}
+
+	if g.showAnalysis {
+		status += "\n\n" + g.viewAnalysis()
 	}
 
 	return winner + board + status
 }
+
+// viewScore shows the round and the rounds won by each player: the wins and
+// losses of the player against the AI.
+func (g Game) viewScore() string {
+	round := fmt.Sprintf("#%d", g.round)
+	if g.bestOf > 0 {
+		round += fmt.Sprintf("/%d", g.bestOf)
+	}
+
+	if g.twoPlayer {
+		return fmt.Sprintf("%s:(O %d-%d X)", round, g.scoreP1, g.scoreP2)
+	}
+
+	return fmt.Sprintf("%s:(W%d-L%d)", round, g.scoreP1, g.scoreP2)
+}
+
+func (g Game) viewMatchWinner() string {
+	winner := g.matchWinner()
+
+	switch {
+	case winner == 0:
+		return "The match is a tie!"
+	case g.twoPlayer:
+		return printPlayer(winner) + " wins the match!"
+	case winner == P1:
+		return "You win the match!"
+	}
+
+	return "The AI wins the match!"
+}
@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func press(model tea.Model, key string) (tea.Model, tea.Cmd) {
+	return model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+}
+
+// playCells plays the cells, numbered from 1, with the number keys.
+func playCells(model tea.Model, cells ...int) tea.Model {
+	for _, cell := range cells {
+		model, _ = press(model, strconv.Itoa(cell))
+	}
+
+	return model
+}
+
+func TestGame_TwoPlayers(t *testing.T) {
+	model := GetTwoPlayerModel(TicTacToe, 0)
+
+	// O takes the first row while X plays below.
+	game := playCells(model, 1, 4, 2, 5, 3).(Game)
+
+	if !game.gameover || game.winner != P1 || game.scoreP1 != 1 {
+		t.Fatalf("expected O to win the round, got winner %d and score %d-%d", game.winner, game.scoreP1, game.scoreP2)
+	}
+
+	// X did not make the last move and starts the next round.
+	model, _ = press(game, "n")
+	game = model.(Game)
+
+	if game.gameover || game.round != 2 || game.turn != P2 {
+		t.Errorf("expected X to start round 2, got round %d and turn %d", game.round, game.turn)
+	}
+}
+
+func TestGame_BestOf(t *testing.T) {
+	var model tea.Model = GetTwoPlayerModel(TicTacToe, 3)
+
+	// O wins the first round, X starts and wins the second one.
+	model = playCells(model, 1, 4, 2, 5, 3)
+	model, _ = press(model, "n")
+	model = playCells(model, 1, 4, 2, 5, 3)
+
+	if game := model.(Game); game.isMatchOver() {
+		t.Fatal("expected the match to go on after 1-1")
+	}
+
+	model, _ = press(model, "n")
+	game := playCells(model, 1, 4, 2, 5, 3).(Game)
+
+	if !game.isMatchOver() || game.matchWinner() != P1 {
+		t.Fatalf("expected O to win the match 2-1, got %d-%d", game.scoreP1, game.scoreP2)
+	}
+
+	model, _ = press(game, "n")
+	game = model.(Game)
+
+	if game.round != 1 || game.scoreP1 != 0 || game.scoreP2 != 0 || game.turn != P1 {
+		t.Errorf("expected a new match, got round %d and score %d-%d", game.round, game.scoreP1, game.scoreP2)
+	}
+}
+
+func TestGame_AIPlaysAfterThePlayer(t *testing.T) {
+	model := GetVariantModel(TicTacToe, Perfect, 0)
+
+	model, cmd := press(model, "5")
+	if cmd == nil {
+		t.Fatal("expected the AI turn to be scheduled")
+	}
+
+	// Keys are ignored during the turn of the AI.
+	model = playCells(model, 1)
+	model, cmd = model.Update(aiTurnMsg{})
+	model, _ = model.Update(cmd())
+	game := model.(Game)
+
+	played := 0
+	for _, cell := range game.board.Cells {
+		if cell == P2 {
+			played++
+		}
+	}
+
+	if played != 1 || game.board.Cells[0] == P1 || game.turn != P1 {
+		t.Errorf("expected the AI to play once and hand the turn back, got %v", game.board.Cells)
+	}
+}
+
+func TestGame_UndoCancelsTheScore(t *testing.T) {
+	model := GetTwoPlayerModel(TicTacToe, 0)
+
+	model = playCells(model, 1, 4, 2, 5, 3)
+	model, _ = press(model, "u")
+	game := model.(Game)
+
+	if game.gameover || game.scoreP1 != 0 || game.turn != P1 || game.board.Cells[2] != EMPTY {
+		t.Fatalf("expected O to play again, got score %d and turn %d", game.scoreP1, game.turn)
+	}
+
+	// O wins the round again.
+	game = playCells(game, 3).(Game)
+	if game.board.Cells[2] != P1 || game.scoreP1 != 1 {
+		t.Errorf("expected O to win c1 again, got %v", game.board.Cells)
+	}
+}
+
+func TestGame_UndoAgainstTheAI(t *testing.T) {
+	var model tea.Model = GetVariantModel(TicTacToe, Perfect, 0)
+
+	model, _ = press(model, "5")
+	model, cmd := model.Update(aiTurnMsg{})
+	model, _ = model.Update(cmd())
+	model, _ = press(model, "u")
+	game := model.(Game)
+
+	if len(game.record.Moves) != 0 || game.turn != P1 || slices.ContainsFunc(game.board.Cells, func(cell int) bool { return cell != EMPTY }) {
+		t.Errorf("expected the moves of the player and the AI to be taken back, got %v", game.board.Cells)
+	}
+}
+
+func TestLoadModel(t *testing.T) {
+	record := NewRecord(RecordGame, NewBoard(4), P1)
+	record.Moves = []int{0, 5}
+
+	model, err := LoadModel(record, Easy, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	game := model.(Game)
+	if game.variant != FourByFour || game.turn != P1 || game.board.Cells[5] != P2 {
+		t.Errorf("expected O to play on the loaded 4x4 board, got %v", game.board.Cells)
+	}
+
+	record.Rows = 5
+	if _, err := LoadModel(record, Easy, true); err == nil {
+		t.Error("expected an error for a board of no variant")
+	}
+}
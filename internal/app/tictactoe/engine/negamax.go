@@ -0,0 +1,395 @@
+package engine
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// WIN_SCORE is the score of a won position. Faster wins score higher.
+const WIN_SCORE = 1_000_000
+
+// Evaluator is implemented by the game engines which can estimate who is
+// winning a position the search could not play until the end. The score is
+// from the point of view of P1 and must stay far below WIN_SCORE.
+type Evaluator interface {
+	Evaluate(board *Board) int
+}
+
+type ttFlag int
+
+const (
+	exact ttFlag = iota
+	lowerBound
+	upperBound
+)
+
+// ttEntry is what the transposition table knows about a position searched to
+// depth: its score, whether the score is exact or a bound, its best move and
+// whether the score depends on positions evaluated at the depth limit.
+type ttEntry struct {
+	depth   int
+	score   int
+	flag    ttFlag
+	move    int
+	horizon bool
+}
+
+// negamax searches the game tree with alpha-beta pruning, deepening the search
+// one ply at a time until it reaches depth or proves the result, or runs out of
+// time when it has a budget. Like mcts, every position is seen from the player
+// to move, which is P1.
+type negamax struct {
+	engine  GameEngine
+	depth   int
+	budget  time.Duration
+	keys    [][3]uint64
+	table   map[uint64]ttEntry
+	history map[int]int
+
+	ctx      context.Context
+	deadline time.Time
+	nodes    int
+	aborted  bool
+	horizon  bool
+}
+
+// NewNegamax creates a negamax searching up to depth, however long it takes.
+func NewNegamax(engine GameEngine, depth int) Searcher {
+	return NewNegamaxWithBudget(engine, depth, 0)
+}
+
+// NewNegamaxWithBudget creates a negamax searching up to depth which plays the
+// best move of the deepest search it finished once the budget is spent, 0 for
+// no limit.
+func NewNegamaxWithBudget(engine GameEngine, depth int, budget time.Duration) Searcher {
+	return &negamax{engine: engine, depth: depth, budget: budget}
+}
+
+func (n *negamax) Solve(board *Board) int {
+	move, _ := n.solve(context.Background(), board)
+	return move
+}
+
+// Search solves the board, then scores every move, deepening the search from
+// the depth of the last complete search of the best move. Scoring the moves has
+// its own time budget, so a search can take twice as long as Solve.
+func (n *negamax) Search(ctx context.Context, board *Board) (int, SearchStats) {
+	move, depth := n.solve(ctx, board)
+	if move == -1 {
+		return move, SearchStats{}
+	}
+
+	n.start(ctx)
+
+	stats := SearchStats{}
+	for ; depth <= n.depth; depth++ {
+		n.horizon = false
+
+		moves := []MoveStats{}
+		for _, m := range n.engine.GetLegalMoves(board) {
+			nodes := n.nodes
+			score := n.scoreMove(board, m, depth, 0, -math.MaxInt, math.MaxInt)
+			moves = append(moves, MoveStats{Move: m, Visits: n.nodes - nodes, Score: score})
+		}
+
+		if n.aborted {
+			break
+		}
+
+		stats.Moves = moves
+		if !n.horizon {
+			break
+		}
+	}
+	stats.Iterations = n.nodes
+
+	slices.SortStableFunc(stats.Moves, func(a, b MoveStats) int {
+		return b.Score - a.Score
+	})
+
+	return move, stats
+}
+
+// solve returns the best move and the depth of the last complete search.
+func (n *negamax) solve(ctx context.Context, board *Board) (int, int) {
+	moves := n.engine.GetLegalMoves(board)
+	if len(moves) == 0 {
+		return -1, 0
+	}
+
+	n.initKeys(len(board.Cells))
+	n.table = map[uint64]ttEntry{}
+	n.history = map[int]int{}
+	n.start(ctx)
+
+	hash := n.hash(board)
+	bestMove := moves[0]
+	bestDepth := 0
+
+	for depth := 1; depth <= n.depth; depth++ {
+		n.horizon = false
+		score := n.search(board, depth, 0, -math.MaxInt, math.MaxInt)
+
+		// Keep the move of the last complete search.
+		if n.aborted {
+			break
+		}
+
+		bestMove = n.table[hash].move
+		bestDepth = depth
+
+		// Stop when the result is proven: a win or loss was found or every
+		// line of play reached the end of the game.
+		if isWinScore(score) || !n.horizon {
+			break
+		}
+	}
+
+	return bestMove, max(bestDepth, 1)
+}
+
+// start gives the search a new time budget.
+func (n *negamax) start(ctx context.Context) {
+	n.ctx = ctx
+	n.deadline = time.Now().Add(n.budget)
+	n.nodes = 0
+	n.aborted = false
+}
+
+// search returns the score of the board for P1, who is about to play.
+func (n *negamax) search(board *Board, depth, ply, alpha, beta int) int {
+	n.nodes++
+	if n.nodes%1024 == 0 && (n.budget > 0 && time.Now().After(n.deadline) || n.ctx.Err() != nil) {
+		n.aborted = true
+	}
+	if n.aborted {
+		return 0
+	}
+
+	if depth == 0 {
+		n.horizon = true
+		return n.evaluate(board)
+	}
+
+	originalAlpha := alpha
+	hash := n.hash(board)
+	ttMove := -1
+
+	if entry, ok := n.table[hash]; ok {
+		ttMove = entry.move
+
+		if entry.depth >= depth {
+			score := fromTableScore(entry.score, ply)
+			// The score only proves the result if its search reached the end of every line
+			n.horizon = n.horizon || entry.horizon
+
+			switch entry.flag {
+			case exact:
+				return score
+			case lowerBound:
+				alpha = max(alpha, score)
+			case upperBound:
+				beta = min(beta, score)
+			}
+
+			if alpha >= beta {
+				return score
+			}
+		}
+	}
+
+	moves := n.orderMoves(n.engine.GetLegalMoves(board), ttMove)
+	if len(moves) == 0 {
+		return 0
+	}
+
+	// Track the depth limit of this position alone to store it in the table
+	outerHorizon := n.horizon
+	n.horizon = false
+
+	bestScore := -math.MaxInt
+	bestMove := moves[0]
+
+	for _, move := range moves {
+		score := n.scoreMove(board, move, depth, ply, alpha, beta)
+		if n.aborted {
+			return 0
+		}
+
+		if score > bestScore {
+			bestScore = score
+			bestMove = move
+		}
+
+		alpha = max(alpha, score)
+		if alpha >= beta {
+			n.history[move] += depth * depth
+			break
+		}
+	}
+
+	flag := exact
+	if bestScore <= originalAlpha {
+		flag = upperBound
+	} else if bestScore >= beta {
+		flag = lowerBound
+	}
+
+	n.table[hash] = ttEntry{depth, toTableScore(bestScore, ply), flag, bestMove, n.horizon}
+	n.horizon = n.horizon || outerHorizon
+
+	return bestScore
+}
+
+// scoreMove plays the move on a copy of the board and returns its score.
+func (n *negamax) scoreMove(board *Board, move, depth, ply, alpha, beta int) int {
+	child := board.Copy()
+	n.engine.PlayMove(child, P1, move)
+
+	isOver, value := n.engine.CheckGameOver(child, move)
+	if isOver {
+		if value != 0 {
+			return WIN_SCORE - ply - 1
+		}
+
+		return 0
+	}
+
+	child.ChangePerspective()
+
+	return -n.search(child, depth-1, ply+1, -beta, -alpha)
+}
+
+func (n *negamax) evaluate(board *Board) int {
+	if evaluator, ok := n.engine.(Evaluator); ok {
+		return evaluator.Evaluate(board)
+	}
+
+	return 0
+}
+
+// orderMoves searches the best move found by a previous search first, then the
+// moves which caused the most cutoffs, so that alpha-beta prunes more.
+func (n *negamax) orderMoves(moves []int, ttMove int) []int {
+	slices.SortStableFunc(moves, func(a, b int) int {
+		if a == ttMove {
+			return -1
+		}
+		if b == ttMove {
+			return 1
+		}
+
+		return n.history[b] - n.history[a]
+	})
+
+	return moves
+}
+
+// initKeys draws the Zobrist keys of the cells: one random number per cell and
+// player, and one for the cell of the last move. The hash of a board is the xor
+// of the keys of its pieces and of its last move.
+func (n *negamax) initKeys(cells int) {
+	if len(n.keys) == cells {
+		return
+	}
+
+	rng := rand.New(rand.NewPCG(uint64(cells), 0x5eed))
+
+	n.keys = make([][3]uint64, cells)
+	for i := range n.keys {
+		n.keys[i] = [3]uint64{rng.Uint64(), rng.Uint64(), rng.Uint64()}
+	}
+}
+
+func (n *negamax) hash(board *Board) uint64 {
+	hash := uint64(0)
+	if board.LastMove != -1 {
+		hash = n.keys[board.LastMove][2]
+	}
+
+	for i, cell := range board.Cells {
+		switch cell {
+		case P1:
+			hash ^= n.keys[i][0]
+		case P2:
+			hash ^= n.keys[i][1]
+		}
+	}
+
+	return hash
+}
+
+func isWinScore(score int) bool {
+	return score > WIN_SCORE/2 || score < -WIN_SCORE/2
+}
+
+// Win scores depend on the ply they were found at, the table stores them
+// relative to the position instead so that they are valid at any ply.
+func toTableScore(score, ply int) int {
+	if score > WIN_SCORE/2 {
+		return score + ply
+	}
+	if score < -WIN_SCORE/2 {
+		return score - ply
+	}
+
+	return score
+}
+
+func fromTableScore(score, ply int) int {
+	if score > WIN_SCORE/2 {
+		return score - ply
+	}
+	if score < -WIN_SCORE/2 {
+		return score + ply
+	}
+
+	return score
+}
+
+// EvaluateLines scores the board for P1 with every line of length cells which
+// holds pieces of a single player: the more pieces, the more the line is worth
+// to that player.
+func EvaluateLines(board *Board, length int) int {
+	score := 0
+
+	directions := [4][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}}
+	for row := 0; row < board.Rows; row++ {
+		for col := 0; col < board.Cols; col++ {
+			for _, direction := range directions {
+				score += evaluateLine(board, row, col, direction[0], direction[1], length)
+			}
+		}
+	}
+
+	return score
+}
+
+// evaluateLine scores the line of length cells starting at row,col.
+func evaluateLine(board *Board, row, col, dRow, dCol, length int) int {
+	endRow := row + dRow*(length-1)
+	endCol := col + dCol*(length-1)
+	if endRow < 0 || endRow >= board.Rows || endCol < 0 || endCol >= board.Cols {
+		return 0
+	}
+
+	p1, p2 := 0, 0
+	for i := 0; i < length; i++ {
+		switch board.Cells[(row+dRow*i)*board.Cols+col+dCol*i] {
+		case P1:
+			p1++
+		case P2:
+			p2++
+		}
+	}
+
+	if p1 > 0 && p2 > 0 {
+		return 0
+	}
+
+	// Each piece multiplies the worth of the line by 4.
+	return 1<<(2*p1) - 1<<(2*p2)
+}
@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNegamax_Solve(t *testing.T) {
+	BOARD_SIZE := 3
+	engine := NewEngineWithAlgorithm(NegamaxAlgorithm, 9, 0)
+
+	for i, tc := range testCases {
+		board := NewBoard(BOARD_SIZE)
+		board.Load(tc.input)
+
+		move := engine.ai.Solve(board)
+
+		if move != tc.expected {
+			t.Errorf("#%d: expected move %d, got %d", i, tc.expected, move)
+		}
+	}
+}
+
+func TestNegamax_BlocksAFork(t *testing.T) {
+	engine := NewEngineWithAlgorithm(NegamaxAlgorithm, 9, 0)
+	board := NewBoard(3)
+
+	// P2 took opposite corners, P1 must play an edge: a corner lets P2 fork.
+	board.Load([]int{-1, 0, 0, 0, 1, 0, 0, 0, -1})
+
+	switch move := engine.ai.Solve(board); move {
+	case 1, 3, 5, 7:
+	default:
+		t.Errorf("expected an edge, got %d", move)
+	}
+}
+
+func TestNegamax_PerfectPlayIsADraw(t *testing.T) {
+	engine := NewEngineWithAlgorithm(NegamaxAlgorithm, 9, 0)
+
+	if winner := PlayMatch(engine, NewBoard(3), engine.ai, engine.ai); winner != 0 {
+		t.Errorf("expected a draw, got a win of %d", winner)
+	}
+}
+
+// Benchmark the AIs against each other: perfect play never loses.
+func TestNegamax_NeverLosesAgainstMCTS(t *testing.T) {
+	engine := NewEngine(DEPTH)
+	mcts := NewAI(MCTSAlgorithm, engine, DEPTH)
+	negamax := NewAI(NegamaxAlgorithm, engine, 9)
+
+	for range 10 {
+		if winner := PlayMatch(engine, NewBoard(3), negamax, mcts); winner == P2 {
+			t.Fatal("negamax lost as the first player")
+		}
+
+		if winner := PlayMatch(engine, NewBoard(3), mcts, negamax); winner == P1 {
+			t.Fatal("negamax lost as the second player")
+		}
+	}
+}
+
+func TestEvaluateLines(t *testing.T) {
+	board := NewBoard(3)
+	if score := EvaluateLines(board, 3); score != 0 {
+		t.Errorf("expected an empty board to be even, got %d", score)
+	}
+
+	board.SetCell(4, P1)
+	if score := EvaluateLines(board, 3); score <= 0 {
+		t.Errorf("expected the center to favor P1, got %d", score)
+	}
+
+	board.ChangePerspective()
+	if score := EvaluateLines(board, 3); score >= 0 {
+		t.Errorf("expected the center to favor P2, got %d", score)
+	}
+}
+
+func TestNegamax_SearchStats(t *testing.T) {
+	engine := NewEngine(0)
+	ai := NewNegamax(engine, 9)
+
+	// #3: P1 wins at once in 8 and can force a win with a fork in 2, 3 or 6
+	board := NewBoard(3)
+	board.Load(testCases[3].input)
+
+	move, stats := ai.Search(context.Background(), board)
+	if move != 8 {
+		t.Errorf("expected move 8, got %d", move)
+	}
+
+	if len(stats.Moves) != 5 || stats.Moves[0].Move != 8 {
+		t.Fatalf("expected the 5 moves with the fastest win first, got %+v", stats.Moves)
+	}
+
+	if stats.Moves[0].Score <= stats.Moves[1].Score {
+		t.Errorf("expected winning at once to score higher than a fork, got %+v", stats.Moves)
+	}
+
+	for _, m := range stats.Moves {
+		won := m.Score > WIN_SCORE/2
+		if won != (m.Move != 7) {
+			t.Errorf("move %d has an unexpected score %d", m.Move, m.Score)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package engine
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/charmbracelet/huh"
+)
+
+// recordHeader is the first line of every game record and holds the format version
+const recordHeader = "gg-board-game 1"
+
+// recordExtension is the file extension of saved games
+const recordExtension = ".game"
+
+// Notation converts the moves of a game to text and back.
+type Notation interface {
+	FormatMove(board *Board, move int) string
+	ParseMove(board *Board, text string) (int, error)
+}
+
+// CellNotation names a cell by its column letter and its row number, counted
+// from the top left cell: a1 is the top left cell and c3 the bottom right cell
+// of tic-tac-toe.
+type CellNotation struct{}
+
+func (CellNotation) FormatMove(board *Board, move int) string {
+	row, col, err := board.GetRowCol(move)
+	if err != nil {
+		return "?"
+	}
+
+	return fmt.Sprintf("%c%d", 'a'+col, row+1)
+}
+
+func (CellNotation) ParseMove(board *Board, text string) (int, error) {
+	if len(text) < 2 || !unicode.IsLower(rune(text[0])) {
+		return 0, fmt.Errorf("invalid cell %q", text)
+	}
+
+	row, err := strconv.Atoi(text[1:])
+	if err != nil {
+		return 0, fmt.Errorf("invalid cell %q", text)
+	}
+
+	return board.GetIndex(row-1, int(text[0]-'a'))
+}
+
+// Record holds the moves of a game from the empty board, First playing the
+// first one. Game names the game the moves are played in.
+//
+// A record is stored as text. After the header, the game, the board size and
+// the first player, the moves are numbered by pairs like in chess notation:
+//
+//	gg-board-game 1
+//	game tictactoe
+//	size 3x3
+//	first P1
+//	1. b2 a1 2. c3 a3
+type Record struct {
+	Game  string
+	Rows  int
+	Cols  int
+	First Player
+	Moves []int
+}
+
+func NewRecord(game string, board *Board, first Player) Record {
+	return Record{
+		Game:  game,
+		Rows:  board.Rows,
+		Cols:  board.Cols,
+		First: first,
+	}
+}
+
+// Turn returns the player who plays the next move.
+func (r Record) Turn() Player {
+	return r.turnAt(len(r.Moves))
+}
+
+// turnAt returns the player who plays the move of the index.
+func (r Record) turnAt(index int) Player {
+	if index%2 == 0 {
+		return r.First
+	}
+
+	return -r.First
+}
+
+// TakeBack removes the last move of the player and the moves played after it,
+// so that it is the turn of the player again. The record is unchanged if the
+// player has no move to take back.
+func (r Record) TakeBack(player Player) Record {
+	for n := len(r.Moves) - 1; n >= 0; n-- {
+		if r.turnAt(n) == player {
+			// Limit the capacity so that playing on does not overwrite the
+			// moves of the copies of the record.
+			r.Moves = r.Moves[:n:n]
+			return r
+		}
+	}
+
+	return r
+}
+
+// Replay plays the moves on an empty board with the rules of the engine. It
+// fails if a move is illegal or played after the end of the game.
+func (r Record) Replay(engine GameEngine) (*Board, error) {
+	board := NewRectBoard(r.Rows, r.Cols)
+
+	for i, move := range r.Moves {
+		if i > 0 {
+			if over, _ := engine.CheckGameOver(board, r.Moves[i-1]); over {
+				return nil, fmt.Errorf("move %d is played after the end of the game", i+1)
+			}
+		}
+
+		if !slices.Contains(engine.GetLegalMoves(board), move) {
+			return nil, fmt.Errorf("move %d is illegal", i+1)
+		}
+
+		if err := engine.PlayMove(board, r.turnAt(i), move); err != nil {
+			return nil, fmt.Errorf("move %d: %w", i+1, err)
+		}
+	}
+
+	return board, nil
+}
+
+// FormatMoves writes the moves in the notation, numbered by pairs.
+func (r Record) FormatMoves(notation Notation) string {
+	board := NewRectBoard(r.Rows, r.Cols)
+	moves := make([]string, 0, len(r.Moves)*3/2)
+
+	for i, move := range r.Moves {
+		if i%2 == 0 {
+			moves = append(moves, fmt.Sprintf("%d.", i/2+1))
+		}
+		moves = append(moves, notation.FormatMove(board, move))
+	}
+
+	return strings.Join(moves, " ")
+}
+
+func (r Record) Encode(w io.Writer, notation Notation) error {
+	writer := bufio.NewWriter(w)
+
+	first := "P1"
+	if r.First == P2 {
+		first = "P2"
+	}
+
+	fmt.Fprintln(writer, recordHeader)
+	fmt.Fprintln(writer, "game", r.Game)
+	fmt.Fprintf(writer, "size %dx%d\n", r.Rows, r.Cols)
+	fmt.Fprintln(writer, "first", first)
+	fmt.Fprintln(writer, r.FormatMoves(notation))
+
+	return writer.Flush()
+}
+
+func DecodeRecord(r io.Reader, notation Notation) (Record, error) {
+	decoded := Record{}
+	scanner := bufio.NewScanner(r)
+
+	readLine := func(prefix string) (string, error) {
+		if !scanner.Scan() {
+			return "", errors.New("game record is truncated")
+		}
+
+		value, found := strings.CutPrefix(scanner.Text(), prefix)
+		if !found {
+			return "", fmt.Errorf("expected %q in game record, got %q", prefix, scanner.Text())
+		}
+
+		return value, nil
+	}
+
+	if _, err := readLine(recordHeader); err != nil {
+		return decoded, err
+	}
+
+	game, err := readLine("game ")
+	if err != nil {
+		return decoded, err
+	}
+	decoded.Game = game
+
+	size, err := readLine("size ")
+	if err != nil {
+		return decoded, err
+	}
+
+	if _, err := fmt.Sscanf(size, "%dx%d", &decoded.Rows, &decoded.Cols); err != nil || decoded.Rows <= 0 || decoded.Cols <= 0 {
+		return decoded, fmt.Errorf("invalid board size %q", size)
+	}
+
+	first, err := readLine("first ")
+	if err != nil {
+		return decoded, err
+	}
+
+	switch first {
+	case "P1":
+		decoded.First = P1
+	case "P2":
+		decoded.First = P2
+	default:
+		return decoded, fmt.Errorf("invalid first player %q", first)
+	}
+
+	board := NewRectBoard(decoded.Rows, decoded.Cols)
+	for scanner.Scan() {
+		for _, field := range strings.Fields(scanner.Text()) {
+			// Skip the move numbers
+			if strings.HasSuffix(field, ".") {
+				continue
+			}
+
+			move, err := notation.ParseMove(board, field)
+			if err != nil {
+				return decoded, fmt.Errorf("invalid move %q: %w", field, err)
+			}
+
+			decoded.Moves = append(decoded.Moves, move)
+		}
+	}
+
+	return decoded, scanner.Err()
+}
+
+// SaveRecord writes the record to the directory of the saved games of its game
+// and returns the path of the file.
+func SaveRecord(record Record, notation Notation) (string, error) {
+	dir, err := recordDir(record.Game)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, time.Now().Format("20060102-150405")+recordExtension)
+
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+
+	if err := record.Encode(file, notation); err != nil {
+		file.Close()
+		return "", err
+	}
+
+	// The record may only reach the disk on close, which must not fail silently
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+func LoadRecord(path string, notation Notation) (Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Record{}, err
+	}
+	defer file.Close()
+
+	return DecodeRecord(file, notation)
+}
+
+func recordDir(game string) (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "gg", "games", game), nil
+}
+
+// ListRecords returns the paths of the saved games of the game, newest first.
+func ListRecords(game string) ([]string, error) {
+	dir, err := recordDir(game)
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+recordExtension))
+	if err != nil {
+		return nil, err
+	}
+
+	// File names are the date so sorting them sorts by date.
+	slices.Sort(paths)
+	slices.Reverse(paths)
+
+	return paths, nil
+}
+
+// SelectRecord asks whether to start a new game or to load one of the saved
+// games of the game. It returns nil for a new game.
+func SelectRecord(game string, notation Notation) (*Record, error) {
+	paths, err := ListRecords(game)
+	if err != nil || len(paths) == 0 {
+		return nil, err
+	}
+
+	path := ""
+
+	options := []huh.Option[string]{huh.NewOption("new game", "")}
+	for _, p := range paths {
+		options = append(options, huh.NewOption("load "+filepath.Base(p), p))
+	}
+
+	err = huh.NewSelect[string]().
+		Title("start a new game or load a saved one:").
+		Options(options...).
+		Value(&path).
+		Run()
+	if err != nil || path == "" {
+		return nil, err
+	}
+
+	record, err := LoadRecord(path, notation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCellNotation(t *testing.T) {
+	board := NewBoard(3)
+	notation := CellNotation{}
+
+	for move := range board.Cells {
+		text := notation.FormatMove(board, move)
+
+		parsed, err := notation.ParseMove(board, text)
+		if err != nil || parsed != move {
+			t.Errorf("%d: printed as %s and parsed as %d (%v)", move, text, parsed, err)
+		}
+	}
+
+	if text := notation.FormatMove(board, 5); text != "c2" {
+		t.Errorf("expected the last cell of the middle row to be c2, got %s", text)
+	}
+
+	for _, text := range []string{"", "a", "d1", "a4", "a0", "A1", "1a"} {
+		if _, err := notation.ParseMove(board, text); err == nil {
+			t.Errorf("%q: expected an error", text)
+		}
+	}
+}
+
+func TestRecordIsEncodedAndDecoded(t *testing.T) {
+	record := NewRecord(RecordGame, NewBoard(3), P2)
+	record.Moves = []int{4, 0, 8, 2, 6}
+
+	buffer := bytes.Buffer{}
+	if err := record.Encode(&buffer, CellNotation{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasSuffix(buffer.String(), "\n1. b2 a1 2. c3 c1 3. a3\n") {
+		t.Errorf("unexpected moves in %q", buffer.String())
+	}
+
+	decoded, err := DecodeRecord(&buffer, CellNotation{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Game != record.Game || decoded.Rows != 3 || decoded.Cols != 3 || decoded.First != P2 || !slices.Equal(decoded.Moves, record.Moves) {
+		t.Errorf("expected %+v, got %+v", record, decoded)
+	}
+}
+
+func TestDecodeRecordRejectsInvalidRecords(t *testing.T) {
+	records := []string{
+		"",
+		"gg-board-game 2\ngame tictactoe\nsize 3x3\nfirst P1\n",
+		"gg-board-game 1\ngame tictactoe\nsize 3\nfirst P1\n",
+		"gg-board-game 1\ngame tictactoe\nsize 3x3\nfirst O\n",
+		"gg-board-game 1\ngame tictactoe\nsize 3x3\nfirst P1\n1. b2 d4\n",
+	}
+
+	for _, record := range records {
+		if _, err := DecodeRecord(strings.NewReader(record), CellNotation{}); err == nil {
+			t.Errorf("%q: expected an error", record)
+		}
+	}
+}
+
+func TestRecordTakeBack(t *testing.T) {
+	record := NewRecord(RecordGame, NewBoard(3), P1)
+	record.Moves = []int{4, 0, 8}
+
+	if moves := record.TakeBack(P1).Moves; !slices.Equal(moves, []int{4, 0}) {
+		t.Errorf("expected the last move of P1 to be taken back, got %v", moves)
+	}
+
+	if moves := record.TakeBack(P2).Moves; !slices.Equal(moves, []int{4}) {
+		t.Errorf("expected the moves since the last move of P2 to be taken back, got %v", moves)
+	}
+
+	// Playing on after a takeback leaves the record untouched.
+	takeBack := record.TakeBack(P2)
+	takeBack.Moves = append(takeBack.Moves, 2)
+	if !slices.Equal(record.Moves, []int{4, 0, 8}) {
+		t.Errorf("expected the record to be unchanged, got %v", record.Moves)
+	}
+
+	record.First = P2
+	record.Moves = []int{4}
+	if moves := record.TakeBack(P1).Moves; !slices.Equal(moves, []int{4}) {
+		t.Errorf("expected no move to take back, got %v", moves)
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	engine := &Engine{}
+	record := NewRecord(RecordGame, NewBoard(3), P1)
+	record.Moves = []int{0, 4, 1, 5}
+
+	board, err := record.Replay(engine)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []int{1, 1, 0, 0, -1, -1, 0, 0, 0}
+	if !slices.Equal(board.Cells, expected) {
+		t.Errorf("expected %v, got %v", expected, board.Cells)
+	}
+
+	for _, moves := range [][]int{{0, 0}, {0, 3, 1, 4, 2, 5}, {9}} {
+		record.Moves = moves
+		if _, err := record.Replay(engine); err == nil {
+			t.Errorf("%v: expected an error", moves)
+		}
+	}
+}
+
+func TestSaveRecord(t *testing.T) {
+	// Keep the saved games out of the user config.
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	t.Setenv("HOME", configDir)
+	t.Setenv("AppData", configDir)
+
+	record := NewRecord(RecordGame, NewBoard(3), P1)
+	record.Moves = []int{4, 0}
+
+	path, err := SaveRecord(record, CellNotation{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := ListRecords(RecordGame)
+	if err != nil || !slices.Equal(paths, []string{path}) {
+		t.Fatalf("expected the saved game to be listed, got %v (%v)", paths, err)
+	}
+
+	loaded, err := LoadRecord(path, CellNotation{})
+	if err != nil || !slices.Equal(loaded.Moves, record.Moves) {
+		t.Errorf("expected the moves %v, got %v (%v)", record.Moves, loaded.Moves, err)
+	}
+}
@@ -2,139 +2,119 @@ package tictactoe
 
 import (
 	"fmt"
-	"strconv"
 
 	"github.com/Kaamkiya/gg/internal/app/tictactoe/engine"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/huh"
 )
 
-var winner = ' '
+// bestOfOptions are the lengths of the matches, 0 plays rounds until the
+// players quit.
+var bestOfOptions = []int{0, 1, 3, 5, 7}
 
-type model struct {
-	turn   rune
-	board  [9]rune
-	xcolor lipgloss.Style
-	ocolor lipgloss.Style
-}
-
-func initialModel() tea.Model {
-	return model{
-		turn: 'x',
-		board: [9]rune{
-			'1', '2', '3',
-			'4', '5', '6',
-			'7', '8', '9',
-		},
-		xcolor: lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000")),
-		ocolor: lipgloss.NewStyle().Foreground(lipgloss.Color("#0000ff")),
-	}
+func Run() {
+	run(true)
 }
 
-func (m model) Init() tea.Cmd {
-	return nil
+func RunVsAi() {
+	run(false)
 }
 
-func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
-			return m, tea.Quit
-		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
-			// There shouldn't be an error, because this is only called for integers
-			position, _ := strconv.Atoi(msg.String())
-
-			if m.board[position-1] != 'x' && m.board[position-1] != 'o' {
-				m.board[position-1] = m.turn
-
-				if m.turn == 'x' {
-					m.turn = 'o'
-				} else {
-					m.turn = 'x'
-				}
-			}
-
-			if m.CheckForWin() != ' ' {
-				winner = m.CheckForWin()
-				return m, tea.Quit
-			}
-		}
+// run plays a saved game or a new match, between two players or against the
+// AI of the chosen difficulty.
+func run(twoPlayer bool) {
+	record, err := engine.SelectRecord(engine.RecordGame, engine.CellNotation{})
+	if err != nil {
+		panic(err)
 	}
 
-	return m, nil
-}
-
-func (m model) View() string {
-	s := fmt.Sprintf("%c | %c | %c\n", m.board[0], m.board[1], m.board[2])
-	s += "---------\n"
-	s += fmt.Sprintf("%c | %c | %c\n", m.board[3], m.board[4], m.board[5])
-	s += "---------\n"
-	s += fmt.Sprintf("%c | %c | %c\n", m.board[6], m.board[7], m.board[8])
-
-	s += fmt.Sprintf("\n\n%c's turn", m.turn)
-
-	return s
-}
+	var variant engine.Variant
+	bestOf := 0
+	if record == nil {
+		variant, bestOf = selectMatch()
+	}
 
-func (m model) CheckForWin() rune {
-	// Check over each row to see if someone won.
-	for i := 0; i < 9; i += 3 {
-		o := 0
-		x := 0
-		for _, c := range m.board[i : i+3] {
-			if c == 'x' {
-				x++
-			} else if c == 'o' {
-				o++
-			}
-		}
+	difficulty := engine.Medium
+	if !twoPlayer {
+		difficulty = selectDifficulty()
+	}
 
-		if o == 3 {
-			return 'o'
-		} else if x == 3 {
-			return 'x'
+	var model tea.Model
+	switch {
+	case record != nil:
+		model, err = engine.LoadModel(*record, difficulty, twoPlayer)
+		if err != nil {
+			panic(err)
 		}
+	case twoPlayer:
+		model = engine.GetTwoPlayerModel(variant, bestOf)
+	default:
+		model = engine.GetVariantModel(variant, difficulty, bestOf)
 	}
 
-	// Check the columns
-	for i := 0; i < 3; i++ {
-		if m.board[i] == 'x' && m.board[i+3] == 'x' && m.board[i+6] == 'x' {
-			return 'x'
-		}
-		if m.board[i] == 'o' && m.board[i+3] == 'o' && m.board[i+6] == 'o' {
-			return 'o'
-		}
+	p := tea.NewProgram(model)
+
+	if _, err := p.Run(); err != nil {
+		panic(err)
 	}
+}
+
+func selectDifficulty() engine.Difficulty {
+	difficulty := engine.Medium
 
-	// And finally, check the diagonals.
-	if m.board[0] == 'x' && m.board[4] == 'x' && m.board[8] == 'x' ||
-		m.board[2] == 'x' && m.board[4] == 'x' && m.board[6] == 'x' {
-		return 'x'
+	difficulties := make([]huh.Option[engine.Difficulty], 0, len(engine.Difficulties))
+	for _, d := range engine.Difficulties {
+		difficulties = append(difficulties, huh.NewOption(fmt.Sprintf("%s - %s", d, d.Description()), d))
 	}
 
-	if m.board[0] == 'o' && m.board[4] == 'o' && m.board[8] == 'o' ||
-		m.board[2] == 'o' && m.board[4] == 'o' && m.board[6] == 'o' {
-		return 'o'
+	err := huh.NewSelect[engine.Difficulty]().
+		Title("choose a difficulty:").
+		Options(difficulties...).
+		Value(&difficulty).
+		Run()
+	if err != nil {
+		panic(err)
 	}
 
-	return ' '
+	return difficulty
 }
 
-func Run() {
-	p := tea.NewProgram(initialModel())
+// selectMatch asks for the variant and the number of rounds of the match.
+func selectMatch() (engine.Variant, int) {
+	variant := engine.TicTacToe
+	bestOf := 0
 
-	if _, err := p.Run(); err != nil {
-		panic(err)
+	options := make([]huh.Option[engine.Variant], 0, len(engine.Variants))
+	for _, v := range engine.Variants {
+		options = append(options, huh.NewOption(v.Name, v))
 	}
 
-	fmt.Printf("%c wins\n", winner)
-}
+	err := huh.NewSelect[engine.Variant]().
+		Title("choose a variant:").
+		Options(options...).
+		Value(&variant).
+		Run()
+	if err != nil {
+		panic(err)
+	}
 
-func RunVsAi() {
-	p := tea.NewProgram(engine.GetModel())
+	rounds := make([]huh.Option[int], 0, len(bestOfOptions))
+	for _, n := range bestOfOptions {
+		name := fmt.Sprintf("best of %d", n)
+		if n == 0 {
+			name = "no limit"
+		}
+		rounds = append(rounds, huh.NewOption(name, n))
+	}
 
-	if _, err := p.Run(); err != nil {
+	err = huh.NewSelect[int]().
+		Title("choose the length of the match:").
+		Options(rounds...).
+		Value(&bestOf).
+		Run()
+	if err != nil {
 		panic(err)
 	}
+
+	return variant, bestOf
 }
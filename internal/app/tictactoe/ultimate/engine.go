@@ -0,0 +1,177 @@
+// Package ultimate implements ultimate tic-tac-toe: a tic-tac-toe board whose
+// cells are tic-tac-toe boards themselves.
+package ultimate
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Kaamkiya/gg/internal/app/tictactoe/engine"
+)
+
+const (
+	// size is the number of rows and columns of the whole board.
+	size = 9
+	// subSize is the number of rows and columns of a sub-board.
+	subSize = 3
+)
+
+// lines are the rows, columns and diagonals of a 3x3 board.
+var lines = [8][3]int{
+	{0, 1, 2}, {3, 4, 5}, {6, 7, 8},
+	{0, 3, 6}, {1, 4, 7}, {2, 5, 8},
+	{0, 4, 8}, {2, 4, 6},
+}
+
+// Engine implements engine.GameEngine for ultimate tic-tac-toe. The board has 9
+// rows and columns, moves are the indices of its cells and the sub-boards are
+// numbered like the cells of a tic-tac-toe board.
+//   - A sub-board is won by getting 3 cells in a row in it, then no one can
+//     play in it anymore.
+//   - The cell of a move in its sub-board sends the opponent to the sub-board
+//     at the same place. If it is won or full, the opponent can play in any
+//     open sub-board.
+//   - The game is won by winning 3 sub-boards in a row.
+type Engine struct{}
+
+func NewEngine() *Engine {
+	return &Engine{}
+}
+
+func NewBoard() *engine.Board {
+	return engine.NewBoard(size)
+}
+
+// GetLegalMoves returns the empty cells of the sub-boards the player can play in.
+func (e *Engine) GetLegalMoves(board *engine.Board) []int {
+	var moves []int
+	for _, sub := range e.ActiveSubBoards(board) {
+		for _, index := range subBoardCells(sub) {
+			if board.Cells[index] == engine.EMPTY {
+				moves = append(moves, index)
+			}
+		}
+	}
+	return moves
+}
+
+// ActiveSubBoards returns the sub-boards the next move can be played in.
+func (e *Engine) ActiveSubBoards(board *engine.Board) []int {
+	if board.LastMove != -1 {
+		target := cellInSubBoard(board.LastMove)
+		if isOpen(board, target) {
+			return []int{target}
+		}
+	}
+
+	var subs []int
+	for sub := range size {
+		if isOpen(board, sub) {
+			subs = append(subs, sub)
+		}
+	}
+	return subs
+}
+
+func (e *Engine) PlayMove(board *engine.Board, player int, move int) error {
+	if !slices.Contains(e.GetLegalMoves(board), move) {
+		return fmt.Errorf("illegal move: %d", move)
+	}
+
+	board.LastMove = move
+	return board.SetCell(move, player)
+}
+
+func (e *Engine) GetOpponent(player int) int {
+	return -player
+}
+
+func (e *Engine) CheckGameOver(board *engine.Board, lastMove int) (bool, int) {
+	if lastMove == -1 {
+		return false, 0
+	}
+
+	// Only the sub-board of the last move, and the lines of the meta-board
+	// going through it, can have been won by it.
+	if subBoardWinner(board, subBoardOf(lastMove)) != engine.EMPTY && e.CheckWin(board) {
+		absValue := engine.P1 * engine.P2 * -1
+		return true, absValue
+	}
+
+	if len(e.GetLegalMoves(board)) == 0 {
+		return true, 0
+	}
+
+	return false, 0
+}
+
+// CheckWin checks if a player won 3 sub-boards in a row.
+func (e *Engine) CheckWin(board *engine.Board) bool {
+	winners := [size]int{}
+	for sub := range size {
+		winners[sub] = subBoardWinner(board, sub)
+	}
+
+	return lineWinner(winners) != engine.EMPTY
+}
+
+// subBoardWinner returns the player who won the sub-board, or engine.EMPTY.
+func subBoardWinner(board *engine.Board, sub int) int {
+	cells := [size]int{}
+	for i, index := range subBoardCells(sub) {
+		cells[i] = board.Cells[index]
+	}
+
+	return lineWinner(cells)
+}
+
+// lineWinner returns the player with 3 cells in a row on a 3x3 board.
+func lineWinner(cells [size]int) int {
+	for _, line := range lines {
+		player := cells[line[0]]
+		if player != engine.EMPTY && cells[line[1]] == player && cells[line[2]] == player {
+			return player
+		}
+	}
+
+	return engine.EMPTY
+}
+
+// isOpen tells if the sub-board is neither won nor full.
+func isOpen(board *engine.Board, sub int) bool {
+	if subBoardWinner(board, sub) != engine.EMPTY {
+		return false
+	}
+
+	for _, index := range subBoardCells(sub) {
+		if board.Cells[index] == engine.EMPTY {
+			return true
+		}
+	}
+
+	return false
+}
+
+// subBoardCells returns the indices of the cells of the sub-board, row by row.
+func subBoardCells(sub int) [size]int {
+	cells := [size]int{}
+	top, left := sub/subSize*subSize, sub%subSize*subSize
+
+	for i := range cells {
+		cells[i] = (top+i/subSize)*size + left + i%subSize
+	}
+
+	return cells
+}
+
+// subBoardOf returns the sub-board of the cell at index.
+func subBoardOf(index int) int {
+	row, col := index/size, index%size
+	return row/subSize*subSize + col/subSize
+}
+
+// cellInSubBoard returns the place of the cell at index in its sub-board.
+func cellInSubBoard(index int) int {
+	row, col := index/size, index%size
+	return row%subSize*subSize + col%subSize
+}
@@ -0,0 +1,138 @@
+package ultimate
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Kaamkiya/gg/internal/app/tictactoe/engine"
+)
+
+// cell returns the index of the cell at the place in the sub-board.
+func cell(sub, place int) int {
+	return subBoardCells(sub)[place]
+}
+
+func TestEngine_FirstMoveIsAnywhere(t *testing.T) {
+	if moves := NewEngine().GetLegalMoves(NewBoard()); len(moves) != size*size {
+		t.Errorf("expected %d moves, got %d", size*size, len(moves))
+	}
+}
+
+func TestEngine_MoveSendsToSubBoard(t *testing.T) {
+	e := NewEngine()
+	board := NewBoard()
+
+	// The top right cell of the center sub-board sends to the top right one.
+	if err := e.PlayMove(board, engine.P1, cell(4, 2)); err != nil {
+		t.Fatal(err)
+	}
+
+	moves := e.GetLegalMoves(board)
+	if len(moves) != 9 {
+		t.Fatalf("expected the 9 cells of a sub-board, got %v", moves)
+	}
+
+	for _, move := range moves {
+		if subBoardOf(move) != 2 {
+			t.Errorf("expected moves in sub-board 2, got %d", move)
+		}
+	}
+
+	if err := e.PlayMove(board, engine.P2, cell(0, 0)); err == nil {
+		t.Error("expected a move outside of the sub-board to be illegal")
+	}
+}
+
+func TestEngine_WonSubBoardIsClosed(t *testing.T) {
+	e := NewEngine()
+	board := NewBoard()
+
+	for _, place := range []int{0, 1, 2} {
+		board.SetCell(cell(0, place), engine.P1)
+	}
+
+	// A move sending to the won sub-board lets the opponent play anywhere else.
+	board.SetCell(cell(4, 0), engine.P1)
+	board.LastMove = cell(4, 0)
+
+	moves := e.GetLegalMoves(board)
+	if len(moves) != 9*8-1 {
+		t.Errorf("expected every empty cell out of the won sub-board, got %d moves", len(moves))
+	}
+
+	for _, move := range moves {
+		if subBoardOf(move) == 0 {
+			t.Errorf("expected no move in the won sub-board, got %d", move)
+		}
+	}
+}
+
+func TestEngine_CheckGameOver(t *testing.T) {
+	e := NewEngine()
+	board := NewBoard()
+
+	// P1 wins the sub-boards of the diagonal.
+	for _, sub := range []int{0, 4} {
+		for _, place := range []int{3, 4, 5} {
+			board.SetCell(cell(sub, place), engine.P1)
+		}
+	}
+
+	board.SetCell(cell(8, 3), engine.P1)
+	board.SetCell(cell(8, 4), engine.P1)
+	if over, _ := e.CheckGameOver(board, cell(8, 4)); over {
+		t.Fatal("expected the game to go on")
+	}
+
+	board.SetCell(cell(8, 5), engine.P1)
+	if over, win := e.CheckGameOver(board, cell(8, 5)); !over || win == 0 {
+		t.Fatal("expected P1 to win with the diagonal of sub-boards")
+	}
+}
+
+func TestEngine_AIWinsTheGame(t *testing.T) {
+	e := NewEngine()
+	board := NewBoard()
+
+	for _, sub := range []int{0, 4} {
+		for _, place := range []int{3, 4, 5} {
+			board.SetCell(cell(sub, place), engine.P1)
+		}
+	}
+	board.SetCell(cell(8, 3), engine.P1)
+	board.SetCell(cell(8, 4), engine.P1)
+
+	// The last move sends P1 to the sub-board where it wins the game.
+	board.SetCell(cell(1, 8), engine.P2)
+	board.LastMove = cell(1, 8)
+
+	if move := engine.NewMCTS(e, 500).Solve(board); move != cell(8, 5) {
+		t.Errorf("expected the AI to win in %d, got %d", cell(8, 5), move)
+	}
+}
+
+func TestEngine_AIPlaysLegalGames(t *testing.T) {
+	e := NewEngine()
+	board := NewBoard()
+	ai := engine.NewMCTS(e, 50)
+
+	player := engine.P1
+	for {
+		rollout := board.Copy()
+		if player == engine.P2 {
+			rollout.ChangePerspective()
+		}
+
+		move := ai.Solve(rollout)
+		if !slices.Contains(e.GetLegalMoves(board), move) {
+			t.Fatalf("the AI played the illegal move %d", move)
+		}
+
+		e.PlayMove(board, player, move)
+		if over, _ := e.CheckGameOver(board, move); over {
+			break
+		}
+
+		player = e.GetOpponent(player)
+	}
+}
@@ -0,0 +1,280 @@
+package ultimate
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Kaamkiya/gg/internal/app/tictactoe/engine"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	yellow = "#FF9E3B"
+	dark   = "#3C3A32"
+	gray   = "#717C7C"
+	light  = "#DCD7BA"
+	red    = "#E63D3D"
+	green  = "#98BB6C"
+	blue   = "#7E9CD8"
+
+	// AI_DEPTH is the number of MCTS iterations of each worker of the AI.
+	AI_DEPTH = 1000
+	// aiDelay lets the player see their move before the AI plays.
+	aiDelay = 200 * time.Millisecond
+)
+
+// model plays O as engine.P1 and X as engine.P2. Against the AI, the player is
+// O and the AI is X.
+type model struct {
+	board    *engine.Board
+	rules    *Engine
+	ai       engine.AI
+	cursor   int
+	turn     int
+	winner   int
+	gameover bool
+	colors   map[string]lipgloss.Style
+}
+
+type aiTurnMsg struct{}
+type aiMoveMsg struct{ move int }
+
+func initialModel(ai bool) model {
+	defaultStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#f9f6f2"))
+	c := func(s string) lipgloss.Color {
+		return lipgloss.Color(s)
+	}
+
+	m := model{
+		rules: NewEngine(),
+		colors: map[string]lipgloss.Style{
+			"board":  defaultStyle.Background(c(dark)),
+			"text":   defaultStyle.Background(c(dark)).Foreground(c(light)),
+			"line":   defaultStyle.Background(c(dark)).Foreground(c(gray)),
+			"p1":     defaultStyle.Background(c(dark)).Foreground(c(yellow)),
+			"p2":     defaultStyle.Background(c(dark)).Foreground(c(red)),
+			"active": defaultStyle.Background(c(blue)),
+			"cursor": defaultStyle.Background(c(gray)),
+			"hi":     defaultStyle.Foreground(c(green)),
+			"status": defaultStyle.Foreground(c(blue)),
+		},
+	}
+
+	m.newMatch(ai)
+
+	return m
+}
+
+// newMatch clears the board. Each match gets a new AI, so that it does not
+// reuse the search of the previous match.
+func (m *model) newMatch(ai bool) {
+	m.board = NewBoard()
+	m.cursor = len(m.board.Cells) / 2
+	m.turn = engine.P1
+	m.winner = engine.EMPTY
+	m.gameover = false
+	m.ai = nil
+
+	if ai {
+		m.ai = engine.NewAI(engine.MCTSAlgorithm, m.rules, AI_DEPTH)
+	}
+}
+
+func (m model) Init() tea.Cmd {
+	return nil
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case aiTurnMsg:
+		return m, m.aiMove()
+
+	case aiMoveMsg:
+		return m.play(msg.move)
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q":
+			return m, tea.Quit
+		case "n", "N":
+			if m.gameover {
+				m.newMatch(m.ai != nil)
+			}
+		case "up", "k":
+			m.moveCursor(-1, 0)
+		case "down", "j":
+			m.moveCursor(1, 0)
+		case "left", "h":
+			m.moveCursor(0, -1)
+		case "right", "l":
+			m.moveCursor(0, 1)
+		case "enter", " ":
+			if m.ai != nil && m.turn == engine.P2 {
+				return m, nil
+			}
+
+			return m.play(m.cursor)
+		}
+	}
+
+	return m, nil
+}
+
+func (m *model) moveCursor(dRow, dCol int) {
+	row, col, _ := m.board.GetRowCol(m.cursor)
+	if index, err := m.board.GetIndex(row+dRow, col+dCol); err == nil {
+		m.cursor = index
+	}
+}
+
+// play plays the move of the current player if it is legal, then lets the AI
+// play if it is its turn.
+func (m model) play(move int) (tea.Model, tea.Cmd) {
+	if m.gameover {
+		return m, nil
+	}
+
+	if err := m.rules.PlayMove(m.board, m.turn, move); err != nil {
+		return m, nil
+	}
+
+	if over, win := m.rules.CheckGameOver(m.board, move); over {
+		m.gameover = true
+		if win != 0 {
+			m.winner = m.turn
+		}
+
+		return m, nil
+	}
+
+	m.turn = m.rules.GetOpponent(m.turn)
+
+	if m.ai != nil && m.turn == engine.P2 {
+		return m, tea.Tick(aiDelay, func(time.Time) tea.Msg {
+			return aiTurnMsg{}
+		})
+	}
+
+	return m, nil
+}
+
+// aiMove searches the move of the AI on a copy of the board. The AI always
+// plays as engine.P1, so the pieces are swapped before the search.
+func (m model) aiMove() tea.Cmd {
+	board := m.board.Copy()
+	board.ChangePerspective()
+	ai := m.ai
+
+	return func() tea.Msg {
+		return aiMoveMsg{ai.Solve(board)}
+	}
+}
+
+func (m model) View() string {
+	active := []int{}
+	if !m.gameover {
+		active = m.rules.ActiveSubBoards(m.board)
+	}
+
+	separator := m.colors["line"].Render(" │")
+	rowSeparator := m.colors["line"].Render(strings.Repeat("───────┼", subSize-1) + "───────")
+
+	board := ""
+	for row := range size {
+		if row > 0 && row%subSize == 0 {
+			board += rowSeparator + "\n"
+		}
+
+		for col := range size {
+			if col > 0 && col%subSize == 0 {
+				board += separator
+			}
+
+			index := row*size + col
+			board += m.renderCell(index, slices.Contains(active, subBoardOf(index)))
+		}
+		board += m.colors["board"].Render(" ") + "\n"
+	}
+
+	return board + m.viewStatus()
+}
+
+// renderCell renders the cell with the color of the winner of its sub-board if
+// it was won, and highlights the cells of the active sub-boards and the cursor.
+func (m model) renderCell(index int, active bool) string {
+	style := m.colors["text"]
+	content := "·"
+
+	switch m.board.Cells[index] {
+	case engine.P1:
+		style = m.colors["p1"]
+		content = "O"
+	case engine.P2:
+		style = m.colors["p2"]
+		content = "X"
+	}
+
+	switch subBoardWinner(m.board, subBoardOf(index)) {
+	case engine.P1:
+		style = style.Foreground(m.colors["p1"].GetForeground()).Faint(true)
+	case engine.P2:
+		style = style.Foreground(m.colors["p2"].GetForeground()).Faint(true)
+	}
+
+	if active {
+		style = style.Background(m.colors["active"].GetBackground())
+	}
+
+	if index == m.cursor && !m.gameover {
+		style = style.Background(m.colors["cursor"].GetBackground())
+	}
+
+	return m.colors["board"].Render(" ") + style.Render(content)
+}
+
+func (m model) viewStatus() string {
+	status := "\n"
+
+	switch {
+	case m.gameover && m.winner == engine.EMPTY:
+		status += m.colors["hi"].Render("Draw!")
+	case m.gameover:
+		status += m.colors["hi"].Render("Winner: " + m.playerName(m.winner))
+	case m.ai != nil && m.turn == engine.P2:
+		status += m.colors["status"].Render("X is thinking...")
+	default:
+		status += m.colors["status"].Render(m.playerName(m.turn) + "'s turn, play in the blue sub-boards")
+	}
+
+	if m.gameover {
+		return status + "\n" + m.colors["status"].Render("[Q]uit - [N]ext match")
+	}
+
+	return status + "\n" + m.colors["status"].Render("arrows/hjkl to move, enter to play, q to quit")
+}
+
+func (m model) playerName(player int) string {
+	if player == engine.P1 {
+		return "O"
+	}
+
+	return "X"
+}
+
+func Run() {
+	p := tea.NewProgram(initialModel(false))
+
+	if _, err := p.Run(); err != nil {
+		panic(err)
+	}
+}
+
+func RunVsAi() {
+	p := tea.NewProgram(initialModel(true))
+
+	if _, err := p.Run(); err != nil {
+		panic(err)
+	}
+}